@@ -50,4 +50,8 @@ type Achievement struct {
 	Points          int                `bson:"points" json:"points"`
 	CreatedAt       time.Time          `bson:"createdAt" json:"createdAt"`
 	UpdatedAt       time.Time          `bson:"updatedAt" json:"updatedAt"`
-}
\ No newline at end of file
+
+	// Salinan dari Postgres (ditulis dispatcher outbox), bukan input user
+	Status        AchievementStatus `bson:"status,omitempty" json:"-"`
+	PointsAwarded int               `bson:"pointsAwarded,omitempty" json:"-"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,8 +15,13 @@ const (
 	StatusVerified  AchievementStatus = "verified"
 	StatusRejected  AchievementStatus = "rejected"
 	StatusDeleted   AchievementStatus = "deleted"
+	StatusRevoked   AchievementStatus = "revoked"
 )
 
+// ErrInvalidTransition dicek workflow sebelum transaksi, lalu dicek ulang repository
+// di bawah lock baris agar request paralel tidak menulis transisi ilegal
+var ErrInvalidTransition = errors.New("action is not allowed in the current status")
+
 type AchievementReference struct {
 	ID uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
 
@@ -26,7 +32,10 @@ type AchievementReference struct {
 	Student Student `gorm:"foreignKey:StudentID;references:ID"`
 
 	MongoAchievementID string            `gorm:"type:varchar(50);not null"`
-	Status             AchievementStatus `gorm:"type:varchar(20);default:'draft'"`
+	Status             AchievementStatus `gorm:"type:varchar(20);default:'draft';index"`
+
+	// Salinan AchievementType dari MongoDB agar bisa difilter di Postgres
+	AchievementType string `gorm:"type:varchar(50);index"`
 
 	SubmittedAt *time.Time
 	VerifiedAt  *time.Time
@@ -36,6 +45,12 @@ type AchievementReference struct {
 
 	RejectionNote string `gorm:"type:text"`
 
+	// Poin yang diberikan saat verifikasi (dikurangi kembali saat revoke)
+	PointsAwarded int `gorm:"default:0"`
+
+	RevokedAt      *time.Time
+	RevocationNote string `gorm:"type:text"`
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SystemActorRole dipakai untuk perubahan status oleh proses internal (mis. rekonsiliasi)
+const SystemActorRole = "System"
+
+// StatusActor adalah user yang memicu perubahan status prestasi
+type StatusActor struct {
+	UserID      uuid.UUID
+	Role        string
+	Permissions []string
+	Scopes      []UserScope // unit organisasi actor; kosong = global
+}
+
+// Can mengecek apakah actor punya permission tertentu
+func (a StatusActor) Can(permission string) bool {
+	for _, p := range a.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// AchievementStatusEvent adalah log append-only untuk setiap perubahan status
+type AchievementStatusEvent struct {
+	ID uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	AchievementRefID uuid.UUID `gorm:"type:uuid;not null;index"`
+
+	ActorID   *uuid.UUID `gorm:"type:uuid"`
+	ActorRole string     `gorm:"type:varchar(50)"`
+
+	FromStatus AchievementStatus `gorm:"type:varchar(20)"`
+	ToStatus   AchievementStatus `gorm:"type:varchar(20);not null"`
+	Note       string            `gorm:"type:text"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AchievementType adalah tipe prestasi tambahan yang didaftarkan Admin.
+// Schema adalah JSON Schema untuk Achievement.Details.CustomFields.
+type AchievementType struct {
+	ID          uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name        string          `gorm:"type:varchar(50);unique;not null"`
+	Label       string          `gorm:"type:varchar(100);not null"`
+	Description string          `gorm:"type:text"`
+	Schema      json.RawMessage `gorm:"type:jsonb;not null"`
+	IsActive    bool            `gorm:"not null"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Alasan perubahan dosen wali
+const (
+	AssignManual   = "manual"       // ditetapkan admin per mahasiswa
+	AssignReassign = "reassign"     // dosen wali lama berhenti, bimbingan dipindah
+	AssignBalance  = "auto_balance" // pembagian otomatis berdasarkan beban bimbingan
+	AssignLegacy   = "legacy"       // data lama sebelum ada riwayat
+	AssignImport   = "import"       // dosen wali awal dari import user (kolom Advisor NIP)
+)
+
+// AdvisorAssignment adalah riwayat dosen wali mahasiswa.
+// EffectiveTo nil berarti penugasan yang sedang berlaku.
+type AdvisorAssignment struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	StudentID uuid.UUID `gorm:"type:uuid;not null;index" json:"student_id"`
+
+	LecturerID uuid.UUID `gorm:"type:uuid;not null;index" json:"lecturer_id"`
+	Lecturer   *Lecturer `gorm:"foreignKey:LecturerID" json:"lecturer,omitempty"`
+
+	PreviousLecturerID *uuid.UUID `gorm:"type:uuid" json:"previous_lecturer_id,omitempty"`
+	Reason             string     `gorm:"type:varchar(30);not null" json:"reason"`
+	Note               string     `gorm:"type:text" json:"note,omitempty"`
+	AssignedBy         *uuid.UUID `gorm:"type:uuid" json:"assigned_by,omitempty"`
+
+	EffectiveFrom time.Time  `gorm:"not null" json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to,omitempty"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
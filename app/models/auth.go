@@ -12,13 +12,17 @@ type User struct {
 	Email        string    `gorm:"type:varchar(100);unique;not null"`
 	PasswordHash string    `gorm:"type:varchar(255);not null"`
 	FullName     string    `gorm:"type:varchar(100);not null"`
+	Phone        string    `gorm:"type:varchar(20)"` // format +628xx, diisi user sendiri
 
 	RoleID       uuid.UUID `gorm:"type:uuid;not null"`
 	Role         Role      `gorm:"foreignKey:RoleID"`
 
-	// [BARU] Menyimpan ID Token yang valid saat ini (Whitelist)
-	CurrentAccessTokenID  *uuid.UUID `gorm:"type:uuid"`
-	CurrentRefreshTokenID *uuid.UUID `gorm:"type:uuid"`
+	// Pembatasan akses per unit organisasi (kosong = global)
+	Scopes []UserScope `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+
+	// Akun buatan admin (termasuk import) wajib ganti password saat login pertama
+	MustChangePassword bool       `gorm:"not null;default:false"`
+	PasswordChangedAt  *time.Time
 
 	IsActive  bool      `gorm:"default:true"`
 	CreatedAt time.Time `gorm:"autoCreateTime"`
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Department struct {
+	ID   uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Code string    `gorm:"type:varchar(20)"`
+	Name string    `gorm:"type:varchar(100);not null"` // unik (case-insensitive) per fakultas
+
+	// Nullable hanya untuk data hasil migrasi yang fakultasnya belum diketahui
+	FacultyID *uuid.UUID `gorm:"type:uuid;index"`
+	Faculty   *Faculty   `gorm:"foreignKey:FacultyID"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
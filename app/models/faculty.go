@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Faculty struct {
+	ID   uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Code string    `gorm:"type:varchar(20)"`
+	Name string    `gorm:"type:varchar(100);not null"` // unik (case-insensitive) lewat index lower(name)
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ImportJobStatus string
+
+const (
+	ImportPending   ImportJobStatus = "pending"
+	ImportRunning   ImportJobStatus = "running"
+	ImportCompleted ImportJobStatus = "completed" // semua baris sudah diproses (bisa ada baris gagal)
+	ImportFailed    ImportJobStatus = "failed"    // berhenti di tengah jalan (mis. server restart)
+)
+
+// ImportRowError adalah kesalahan pada satu baris file import (Row = nomor baris di file, header = 1)
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportJob mencatat progress import user massal yang berjalan di background
+type ImportJob struct {
+	ID        uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CreatedBy uuid.UUID       `gorm:"type:uuid;not null;index" json:"created_by"`
+	FileName  string          `gorm:"type:varchar(255)" json:"file_name"`
+	Status    ImportJobStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+
+	TotalRows     int              `gorm:"not null;default:0" json:"total_rows"`
+	ProcessedRows int              `gorm:"not null;default:0" json:"processed_rows"`
+	SucceededRows int              `gorm:"not null;default:0" json:"succeeded_rows"`
+	FailedRows    int              `gorm:"not null;default:0" json:"failed_rows"`
+	Errors        []ImportRowError `gorm:"type:jsonb;serializer:json" json:"errors"`
+	LastError     string           `gorm:"type:text" json:"last_error,omitempty"`
+
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
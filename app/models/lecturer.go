@@ -14,7 +14,8 @@ type Lecturer struct {
 	// PERBAIKAN TOTAL: Ganti nama field jadi NIP (bukan LecturerID)
 	NIP          string    `gorm:"column:nip;type:varchar(100);unique;not null"`
 
-	Department   string    `gorm:"type:varchar(100)"`
+	DepartmentID *uuid.UUID  `gorm:"type:uuid;index"`
+	Department   *Department `gorm:"foreignKey:DepartmentID"`
 
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Hasil percobaan login yang dicatat di audit login_attempts
+const (
+	LoginPending     = "pending" // password sedang dicek; ikut dihitung gagal sampai hasilnya dicatat
+	LoginSucceeded   = "success"
+	LoginInvalid     = "invalid_credentials"
+	LoginInactive    = "inactive"
+	LoginThrottled   = "throttled" // ditolak karena backoff, password tidak dicek
+	LoginLocked      = "locked"    // ditolak karena akun sedang dikunci
+	LoginAdminUnlock = "admin_unlock"
+)
+
+// LoginAttempt adalah audit setiap percobaan login. Counter gagal per username & per IP
+// dihitung dari tabel ini; baris success / admin_unlock me-reset counter username.
+type LoginAttempt struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Username  string     `gorm:"type:varchar(50);not null;index:idx_login_attempt_username,priority:1" json:"username"` // lowercase
+	UserID    *uuid.UUID `gorm:"type:uuid" json:"user_id,omitempty"`
+	IPAddress string     `gorm:"type:varchar(45);not null;index:idx_login_attempt_ip,priority:1" json:"ip_address"`
+	UserAgent string     `gorm:"type:text" json:"user_agent,omitempty"`
+	Result    string     `gorm:"type:varchar(20);not null" json:"result"`
+	CreatedAt time.Time  `gorm:"autoCreateTime;index:idx_login_attempt_username,priority:2;index:idx_login_attempt_ip,priority:2" json:"created_at"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutboxStatus string
+
+const (
+	OutboxPending   OutboxStatus = "pending"
+	OutboxDone      OutboxStatus = "done"
+	OutboxFailed    OutboxStatus = "failed"    // melewati batas retry, perlu dicek manual (bisa di-retry admin)
+	OutboxCancelled OutboxStatus = "cancelled" // dibatalkan sebelum diproses (mis. award lama setelah revoke)
+)
+
+// Jenis event outbox yang diproses dispatcher
+// award_points hanya tersisa dari versi lama; poin kini ditulis langsung di transaksi verifikasi
+const (
+	OutboxAwardPoints = "award_points"
+	OutboxNotify      = "notify"
+	OutboxSyncMongo   = "sync_mongo_status"
+)
+
+// OutboxEvent ditulis dalam transaksi yang sama dengan perubahan status,
+// lalu diproses (dengan retry) oleh dispatcher di background
+type OutboxEvent struct {
+	ID uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+
+	Kind        string          `gorm:"type:varchar(50);not null" json:"kind"`
+	AggregateID uuid.UUID       `gorm:"type:uuid;not null;index" json:"aggregate_id"`
+	Payload     json.RawMessage `gorm:"type:jsonb;not null" json:"payload"`
+
+	Status        OutboxStatus `gorm:"type:varchar(20);not null;default:'pending';index:idx_outbox_pending,priority:1" json:"status"`
+	NextAttemptAt time.Time    `gorm:"not null;index:idx_outbox_pending,priority:2" json:"next_attempt_at"`
+	Attempts      int          `gorm:"not null;default:0" json:"attempts"`
+	LastError     string       `gorm:"type:text" json:"last_error,omitempty"`
+
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty"`
+}
+
+// Payload per jenis event
+type AwardPointsPayload struct {
+	StudentID        uuid.UUID `json:"student_id"`
+	AchievementRefID uuid.UUID `json:"achievement_ref_id"`
+	Amount           int       `json:"amount"`
+	ActorID          uuid.UUID `json:"actor_id"`
+	ActorRole        string    `json:"actor_role"`
+}
+
+type NotifyPayload struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Subject string    `json:"subject"`
+	Message string    `json:"message"`
+}
+
+type SyncMongoPayload struct {
+	MongoID       string            `json:"mongo_id"`
+	Status        AchievementStatus `json:"status"`
+	PointsAwarded int               `json:"points_awarded"`
+}
+
+// NewOutboxEvent menyiapkan event pending yang siap diproses segera
+func NewOutboxEvent(kind string, aggregateID uuid.UUID, payload interface{}) (OutboxEvent, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+	return OutboxEvent{
+		Kind:          kind,
+		AggregateID:   aggregateID,
+		Payload:       raw,
+		Status:        OutboxPending,
+		NextAttemptAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PasswordResetToken hanya menyimpan hash token; token mentah dikirim lewat email.
+// Token sekali pakai: UsedAt terisi saat dipakai atau saat diganti permintaan baru.
+type PasswordResetToken struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	User      User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	TokenHash string    `gorm:"type:varchar(64);not null;uniqueIndex"` // sha256 hex
+
+	RequestIP string    `gorm:"type:varchar(45)"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
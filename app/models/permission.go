@@ -10,4 +10,77 @@ type Permission struct {
 	Resource    string    `gorm:"type:varchar(50);not null"`
 	Action      string    `gorm:"type:varchar(50);not null"`
 	Description string    `gorm:"type:text"`
-}
\ No newline at end of file
+}
+
+// Nama permission yang dicek oleh middleware.RequirePermission & workflow prestasi
+const (
+	PermUserManage            = "user:manage"
+	PermRoleManage            = "role:manage"
+	PermAchievementCreate     = "achievement:create"
+	PermAchievementRead       = "achievement:read"
+	PermAchievementUpdate     = "achievement:update"
+	PermAchievementDelete     = "achievement:delete"
+	PermAchievementSubmit     = "achievement:submit"
+	PermAchievementVerify     = "achievement:verify" // sekaligus memberi akses data mahasiswa bimbingan
+	PermAchievementRevoke     = "achievement:revoke"
+	PermAchievementManage     = "achievement:manage" // hapus/revoke prestasi siapa pun
+	PermAchievementTypeManage = "achievement_type:manage"
+	PermPointRuleManage       = "point_rule:manage"
+	PermOrganizationManage    = "organization:manage" // master data fakultas/jurusan/prodi
+	PermProfileManage         = "profile:manage"      // profile Mahasiswa/Dosen Wali (NIM, NIP, prodi, jurusan)
+	PermPointReconcile        = "point:reconcile"
+	PermOutboxManage          = "outbox:manage"    // lihat & retry event outbox yang gagal
+	PermStudentReadAll        = "student:read_all" // akses data & prestasi semua mahasiswa
+	PermAdvisorAssign         = "advisor:assign"
+	PermReportRead            = "report:read"
+)
+
+// DefaultRolePermissions adalah pemetaan awal role -> permission yang di-seed saat startup
+var DefaultRolePermissions = map[string][]string{
+	"Admin": {
+		PermUserManage, PermRoleManage, PermAchievementRead, PermAchievementRevoke, PermAchievementManage,
+		PermAchievementTypeManage, PermPointRuleManage, PermPointReconcile, PermOrganizationManage,
+		PermProfileManage, PermStudentReadAll, PermAdvisorAssign, PermReportRead, PermOutboxManage,
+	},
+	"Mahasiswa": {
+		PermAchievementCreate, PermAchievementRead, PermAchievementUpdate,
+		PermAchievementDelete, PermAchievementSubmit,
+	},
+	"Dosen Wali": {
+		PermAchievementRead, PermAchievementVerify, PermAchievementRevoke, PermReportRead,
+	},
+	// Reviewer program studi: hanya membaca prestasi & laporan semua mahasiswa
+	"Kaprodi": {
+		PermAchievementRead, PermStudentReadAll, PermReportRead,
+	},
+	// Approver tingkat fakultas: boleh verifikasi/revoke prestasi mahasiswa mana pun
+	"Wakil Dekan": {
+		PermAchievementRead, PermAchievementVerify, PermAchievementRevoke,
+		PermStudentReadAll, PermReportRead,
+	},
+}
+
+// SystemRoles dipakai langsung oleh kode (mis. pembuatan profile), tidak boleh di-rename/hapus
+var SystemRoles = []string{"Admin", "Mahasiswa", "Dosen Wali"}
+
+// IsSystemRole mengecek apakah role termasuk role bawaan sistem
+func IsSystemRole(name string) bool {
+	for _, r := range SystemRoles {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBuiltinPermission mengecek apakah permission dipakai oleh route/kode (tidak boleh dihapus)
+func IsBuiltinPermission(name string) bool {
+	for _, perms := range DefaultRolePermissions {
+		for _, p := range perms {
+			if p == name {
+				return true
+			}
+		}
+	}
+	return false
+}
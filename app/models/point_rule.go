@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PointRule adalah aturan penilaian poin yang dikelola Admin.
+// Kriteria yang kosong (atau Rank 0) berarti berlaku untuk semua nilai.
+type PointRule struct {
+	ID   uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Name string    `gorm:"type:varchar(100);not null"`
+
+	AchievementType  string `gorm:"type:varchar(50)"`
+	CompetitionLevel string `gorm:"type:varchar(50)"`
+	Rank             int    `gorm:"default:0"`
+	MedalType        string `gorm:"type:varchar(50)"`
+	PublicationType  string `gorm:"type:varchar(50)"`
+	Position         string `gorm:"type:varchar(100)"` // Jabatan di organisasi
+
+	Points   int  `gorm:"not null"`
+	Priority int  `gorm:"default:0"` // lebih tinggi menang atas rule yang lebih spesifik
+	IsActive bool `gorm:"not null"`
+
+	// Masa berlaku pedoman (mis. per tahun akademik)
+	ValidFrom  *time.Time
+	ValidUntil *time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
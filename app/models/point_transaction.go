@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PointReason string
+
+const (
+	PointReasonAward          PointReason = "achievement_award"
+	PointReasonRevoke         PointReason = "achievement_revoke"
+	PointReasonOpeningBalance PointReason = "opening_balance"
+)
+
+// PointTransaction adalah ledger poin mahasiswa. Student.TotalPoints = SUM(amount).
+type PointTransaction struct {
+	ID uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+
+	StudentID uuid.UUID `gorm:"type:uuid;not null;index"`
+
+	// Satu achievement hanya boleh punya satu award & satu revoke (mencegah double count)
+	AchievementRefID *uuid.UUID  `gorm:"type:uuid;uniqueIndex:idx_point_tx_achievement_reason"`
+	Reason           PointReason `gorm:"type:varchar(30);not null;uniqueIndex:idx_point_tx_achievement_reason"`
+
+	Amount int    `gorm:"not null"`
+	Note   string `gorm:"type:text"`
+
+	ActorID *uuid.UUID `gorm:"type:uuid"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
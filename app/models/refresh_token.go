@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken adalah satu refresh token yang pernah diterbitkan. Semua token dalam satu
+// session membentuk satu family; token yang sudah dirotasi (RotatedAt terisi) lalu dipakai
+// lagi dianggap dicuri sehingga seluruh session dicabut.
+type RefreshToken struct {
+	ID        uuid.UUID   `gorm:"type:uuid;primary_key"` // = jti pada JWT
+	SessionID uuid.UUID   `gorm:"type:uuid;not null;index"`
+	Session   UserSession `gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE;"`
+	TokenHash string      `gorm:"type:varchar(64);not null"` // sha256 hex
+
+	ExpiresAt    time.Time  `gorm:"not null"`
+	RotatedAt    *time.Time // waktu ditukar dengan token baru
+	ReplacedByID *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime"`
+}
@@ -14,4 +14,14 @@ type Role struct {
 	
 	// Many-to-Many dengan Permission (otomatis buat tabel role_permissions)
 	Permissions []Permission `gorm:"many2many:role_permissions;"`
-}
\ No newline at end of file
+}
+
+// HasPermission mengecek apakah role memiliki permission (Permissions harus di-preload)
+func (r Role) HasPermission(name string) bool {
+	for _, p := range r.Permissions {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
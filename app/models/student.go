@@ -15,7 +15,9 @@ type Student struct {
 	// Ini agar GORM tidak menganggapnya sebagai Foreign Key
 	NIM          string    `gorm:"column:nim;type:varchar(100);unique;not null"`
 
-	ProgramStudy string    `gorm:"type:varchar(100)"`
+	// Program studi (master data); jurusan & fakultas mengikuti program studi
+	StudyProgramID *uuid.UUID    `gorm:"type:uuid;index"`
+	StudyProgram   *StudyProgram `gorm:"foreignKey:StudyProgramID"`
 	AcademicYear string    `gorm:"type:varchar(10)"`
 
 	// [BARU] Kolom Poin Gamifikasi
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StudyProgram struct {
+	ID     uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Code   string    `gorm:"type:varchar(20)"`
+	Name   string    `gorm:"type:varchar(100);not null"` // unik (case-insensitive) per jurusan
+	Degree string    `gorm:"type:varchar(10)"`           // mis. D3, S1, S2
+
+	// Nullable hanya untuk data hasil migrasi yang jurusannya belum diketahui
+	DepartmentID *uuid.UUID  `gorm:"type:uuid;index"`
+	Department   *Department `gorm:"foreignKey:DepartmentID"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
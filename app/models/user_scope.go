@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Jenis unit organisasi untuk membatasi akses user
+const (
+	UnitFaculty      = "faculty"
+	UnitDepartment   = "department"
+	UnitProgramStudy = "program_study"
+)
+
+// UserScope membatasi permission "semua mahasiswa" (student:read_all, report:read)
+// hanya pada unit organisasi tertentu. User tanpa scope = akses global.
+type UserScope struct {
+	ID     uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_user_scope_unit_id"`
+	Unit   string    `gorm:"type:varchar(30);not null;uniqueIndex:idx_user_scope_unit_id"`
+	// ID faculty / department / study_program sesuai Unit
+	UnitID uuid.UUID `gorm:"type:uuid;uniqueIndex:idx_user_scope_unit_id"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// IsValidUnit mengecek jenis unit yang didukung
+func IsValidUnit(unit string) bool {
+	return unit == UnitFaculty || unit == UnitDepartment || unit == UnitProgramStudy
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserSession adalah satu login aktif (per perangkat) sekaligus family refresh token.
+// Access token hanya valid jika jti-nya sama dengan AccessTokenID session yang belum dicabut.
+type UserSession struct {
+	ID     uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;index" json:"-"`
+	User   User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"-"`
+
+	Device    string `gorm:"type:varchar(100)" json:"device"`
+	IPAddress string `gorm:"type:varchar(45)" json:"ip_address"`
+	UserAgent string `gorm:"type:text" json:"user_agent"`
+
+	AccessTokenID uuid.UUID `gorm:"type:uuid;not null" json:"-"`
+
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	ExpiresAt  time.Time  `gorm:"not null;index" json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+
+	Current bool `gorm:"-" json:"current"` // diisi saat list: session yang sedang dipakai
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"time"
+
+	"gouas/app/models"
+
+	"github.com/google/uuid"
+)
+
+// AchievementFilter adalah parameter list achievement (filter, sort, pagination)
+type AchievementFilter struct {
+	Statuses        []models.AchievementStatus
+	AchievementType string
+	CreatedFrom     *time.Time
+	CreatedTo       *time.Time
+	StudentIDs      []uuid.UUID
+	Units           []models.UserScope // batas unit organisasi dari UserScope
+	StudyProgramID  *uuid.UUID
+	AcademicYear    string
+	AdvisorID       *uuid.UUID
+
+	SortBy   string
+	SortDesc bool
+	Page     int
+	Limit    int
+}
+
+// Kolom yang boleh dipakai untuk sorting (whitelist agar aman dari SQL injection)
+var AchievementSortColumns = map[string]string{
+	"created_at":     "achievement_references.created_at",
+	"updated_at":     "achievement_references.updated_at",
+	"submitted_at":   "achievement_references.submitted_at",
+	"verified_at":    "achievement_references.verified_at",
+	"status":         "achievement_references.status",
+	"type":           "achievement_references.achievement_type",
+	"points_awarded": "achievement_references.points_awarded",
+}
@@ -2,30 +2,54 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"gouas/app/models"
+	"log"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrMongoDocumentMissing: reference ada di Postgres tetapi dokumennya tidak ada di Mongo
+var ErrMongoDocumentMissing = errors.New("achievement document not found in MongoDB")
+
 type AchievementRepository interface {
-	Create(achievement models.Achievement, studentID uuid.UUID) (*models.AchievementReference, error)
-	FindReferenceByID(id uuid.UUID) (*models.AchievementReference, error)
-	UpdateStatus(id uuid.UUID, status models.AchievementStatus) error
-	Verify(id uuid.UUID, verifierID uuid.UUID) error
-	Reject(id uuid.UUID, note string) error
-	AddAttachment(mongoID string, attachment models.Attachment) error
-	SoftDelete(id uuid.UUID) error
-	FindAllReferences() ([]models.AchievementReference, error)
-	FindReferencesByStudentID(studentID uuid.UUID) ([]models.AchievementReference, error)
-	// [BARU] Mencari berdasarkan list Student ID (untuk Dosen Wali)
-	FindReferencesByStudentIDs(studentIDs []uuid.UUID) ([]models.AchievementReference, error)
-	GetMongoDetail(mongoID string) (*models.Achievement, error)
-	UpdateMongo(mongoID string, data models.Achievement) error
+	Create(ctx context.Context, achievement models.Achievement, studentID uuid.UUID) (*models.AchievementReference, error)
+	FindReferenceByID(ctx context.Context, id uuid.UUID) (*models.AchievementReference, error)
+	// Method transisi menulis event outbox di transaksi yang sama dengan perubahan status.
+	// from = status asal yang sah (Transition.From); status lain ditolak dengan models.ErrInvalidTransition
+	UpdateStatus(ctx context.Context, id uuid.UUID, from []models.AchievementStatus, status models.AchievementStatus, actor models.StatusActor, outbox []models.OutboxEvent) error
+	Verify(ctx context.Context, id uuid.UUID, from []models.AchievementStatus, actor models.StatusActor, points int, outbox []models.OutboxEvent) error
+	Reject(ctx context.Context, id uuid.UUID, from []models.AchievementStatus, note string, actor models.StatusActor, outbox []models.OutboxEvent) error
+	// [BARU] Revoke status verified sekaligus mengurangi poin yang pernah diberikan (atomic)
+	Revoke(ctx context.Context, id uuid.UUID, from []models.AchievementStatus, reason string, actor models.StatusActor, outbox []models.OutboxEvent) error
+	AddAttachment(ctx context.Context, mongoID string, attachment models.Attachment) error
+	SoftDelete(ctx context.Context, id uuid.UUID, from []models.AchievementStatus, actor models.StatusActor, outbox []models.OutboxEvent) error
+	// [BARU] Riwayat perubahan status (urut dari yang paling lama)
+	FindStatusEvents(ctx context.Context, id uuid.UUID) ([]models.AchievementStatusEvent, error)
+	FindReferencesByStudentID(ctx context.Context, studentID uuid.UUID) ([]models.AchievementReference, error)
+	// [BARU] List dengan filter, sorting & pagination. Mengembalikan total data sebelum paging.
+	FindReferences(ctx context.Context, filter AchievementFilter) ([]models.AchievementReference, int64, error)
+	GetMongoDetail(ctx context.Context, mongoID string) (*models.Achievement, error)
+	UpdateMongo(ctx context.Context, mongoID string, data models.Achievement) error
+	// SyncMongoStatus menyalin status & poin final ke dokumen Mongo (dipanggil dispatcher outbox)
+	SyncMongoStatus(ctx context.Context, mongoID string, status models.AchievementStatus, pointsAwarded int) error
+	// [BARU] Full-text search di Mongo. studentIDs nil = tanpa batasan mahasiswa.
+	SearchMongo(ctx context.Context, query string, studentIDs []uuid.UUID, limit int) ([]SearchHit, error)
+	FindReferencesByMongoIDs(ctx context.Context, mongoIDs []string) ([]models.AchievementReference, error)
+}
+
+// SearchHit adalah dokumen Mongo hasil pencarian beserta skor relevansinya
+type SearchHit struct {
+	Achievement models.Achievement `bson:",inline"`
+	Score       float64            `bson:"score"`
 }
 
 type achievementRepository struct {
@@ -40,10 +64,7 @@ func NewAchievementRepository(pg *gorm.DB, mongoDB *mongo.Database) AchievementR
 	}
 }
 
-func (r *achievementRepository) Create(achievement models.Achievement, studentID uuid.UUID) (*models.AchievementReference, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
+func (r *achievementRepository) Create(ctx context.Context, achievement models.Achievement, studentID uuid.UUID) (*models.AchievementReference, error) {
 	achievement.ID = primitive.NewObjectID()
 	achievement.CreatedAt = time.Now()
 	achievement.UpdatedAt = time.Now()
@@ -61,45 +82,94 @@ func (r *achievementRepository) Create(achievement models.Achievement, studentID
 		StudentID:          studentID,
 		MongoAchievementID: achievement.ID.Hex(),
 		Status:             models.StatusDraft,
+		AchievementType:    achievement.AchievementType,
 	}
 
-	err = r.pg.Create(&ref).Error
+	err = r.pg.WithContext(ctx).Create(&ref).Error
 	if err != nil {
-		r.mongo.DeleteOne(ctx, bson.M{"_id": achievement.ID})
+		// Kompensasi best-effort; kalau gagal, dokumen yatim dibersihkan oleh job rekonsiliasi
+		if _, delErr := r.mongo.DeleteOne(ctx, bson.M{"_id": achievement.ID}); delErr != nil {
+			log.Printf("[ACHIEVEMENT] failed to remove mongo document %s after postgres error: %v", achievement.ID.Hex(), delErr)
+		}
 		return nil, err
 	}
 
 	return &ref, nil
 }
 
-func (r *achievementRepository) FindReferenceByID(id uuid.UUID) (*models.AchievementReference, error) {
+func (r *achievementRepository) FindReferenceByID(ctx context.Context, id uuid.UUID) (*models.AchievementReference, error) {
 	var ref models.AchievementReference
-	err := r.pg.Preload("Student").First(&ref, "id = ?", id).Error
+	err := r.pg.WithContext(ctx).Preload("Student").First(&ref, "id = ?", id).Error
 	return &ref, err
 }
 
-func (r *achievementRepository) FindAllReferences() ([]models.AchievementReference, error) {
+func (r *achievementRepository) FindReferencesByStudentID(ctx context.Context, studentID uuid.UUID) ([]models.AchievementReference, error) {
 	var refs []models.AchievementReference
-	err := r.pg.Preload("Student.User").Order("created_at desc").Find(&refs).Error
+	err := r.pg.WithContext(ctx).Where("student_id = ?", studentID).Order("created_at desc").Find(&refs).Error
 	return refs, err
 }
 
-func (r *achievementRepository) FindReferencesByStudentID(studentID uuid.UUID) ([]models.AchievementReference, error) {
-	var refs []models.AchievementReference
-	err := r.pg.Where("student_id = ?", studentID).Order("created_at desc").Find(&refs).Error
-	return refs, err
-}
+func (r *achievementRepository) FindReferences(ctx context.Context, f AchievementFilter) ([]models.AchievementReference, int64, error) {
+	q := r.pg.WithContext(ctx).Model(&models.AchievementReference{}).
+		Joins("JOIN students ON students.id = achievement_references.student_id")
 
-func (r *achievementRepository) FindReferencesByStudentIDs(studentIDs []uuid.UUID) ([]models.AchievementReference, error) {
-	var refs []models.AchievementReference
-	if len(studentIDs) == 0 {
-		return refs, nil
+	if len(f.Statuses) > 0 {
+		q = q.Where("achievement_references.status IN ?", f.Statuses)
+	} else {
+		// Default: yang sudah dihapus tidak ikut ditampilkan
+		q = q.Where("achievement_references.status <> ?", models.StatusDeleted)
 	}
-	err := r.pg.Preload("Student.User").Where("student_id IN ?", studentIDs).Order("created_at desc").Find(&refs).Error
-	return refs, err
+	if f.AchievementType != "" {
+		q = q.Where("achievement_references.achievement_type = ?", f.AchievementType)
+	}
+	if f.CreatedFrom != nil {
+		q = q.Where("achievement_references.created_at >= ?", *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		q = q.Where("achievement_references.created_at <= ?", *f.CreatedTo)
+	}
+	if f.StudentIDs != nil {
+		if len(f.StudentIDs) == 0 {
+			// Scope kosong (mis. mahasiswa tanpa profil) = tidak ada data
+			q = q.Where("1 = 0")
+		} else {
+			q = q.Where("achievement_references.student_id IN ?", f.StudentIDs)
+		}
+	}
+	q = applyUnitScope(q, f.Units)
+	if f.StudyProgramID != nil {
+		q = q.Where("students.study_program_id = ?", *f.StudyProgramID)
+	}
+	if f.AcademicYear != "" {
+		q = q.Where("students.academic_year = ?", f.AcademicYear)
+	}
+	if f.AdvisorID != nil {
+		q = q.Where("students.advisor_id = ?", *f.AdvisorID)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	column, ok := AchievementSortColumns[f.SortBy]
+	if !ok {
+		column = AchievementSortColumns["created_at"]
+	}
+	order := column + " asc"
+	if f.SortDesc {
+		order = column + " desc"
+	}
+
+	var refs []models.AchievementReference
+	err := q.Preload("Student.User").
+		Order(order).Order("achievement_references.id").
+		Offset((f.Page - 1) * f.Limit).Limit(f.Limit).
+		Find(&refs).Error
+	return refs, total, err
 }
 
-func (r *achievementRepository) UpdateStatus(id uuid.UUID, status models.AchievementStatus) error {
+func (r *achievementRepository) UpdateStatus(ctx context.Context, id uuid.UUID, from []models.AchievementStatus, status models.AchievementStatus, actor models.StatusActor, outbox []models.OutboxEvent) error {
 	updates := map[string]interface{}{
 		"status":     status,
 		"updated_at": time.Now(),
@@ -108,30 +178,120 @@ func (r *achievementRepository) UpdateStatus(id uuid.UUID, status models.Achieve
 		now := time.Now()
 		updates["submitted_at"] = &now
 	}
-	return r.pg.Model(&models.AchievementReference{}).Where("id = ?", id).Updates(updates).Error
+	return r.changeStatus(ctx, id, from, updates, actor, "", outbox, nil)
 }
 
-func (r *achievementRepository) Verify(id uuid.UUID, verifierID uuid.UUID) error {
+func (r *achievementRepository) Verify(ctx context.Context, id uuid.UUID, from []models.AchievementStatus, actor models.StatusActor, points int, outbox []models.OutboxEvent) error {
 	now := time.Now()
-	return r.pg.Model(&models.AchievementReference{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"status":      models.StatusVerified,
-		"verified_by": verifierID,
-		"verified_at": now,
-		"updated_at":  now,
-	}).Error
+	return r.changeStatus(ctx, id, from, map[string]interface{}{
+		"status":         models.StatusVerified,
+		"verified_by":    actor.UserID,
+		"verified_at":    now,
+		"points_awarded": points,
+		"updated_at":     now,
+	}, actor, "", outbox, func(tx *gorm.DB, ref *models.AchievementReference) error {
+		// Poin dicatat di transaksi yang sama, sehingga revoke selalu melihat award yang sudah ada
+		return recordPointTransaction(tx, &models.PointTransaction{
+			StudentID:        ref.StudentID,
+			AchievementRefID: &ref.ID,
+			Reason:           models.PointReasonAward,
+			Amount:           points,
+			ActorID:          actorID(actor),
+		})
+	})
 }
 
-func (r *achievementRepository) Reject(id uuid.UUID, note string) error {
-	return r.pg.Model(&models.AchievementReference{}).Where("id = ?", id).Updates(map[string]interface{}{
+func (r *achievementRepository) Reject(ctx context.Context, id uuid.UUID, from []models.AchievementStatus, note string, actor models.StatusActor, outbox []models.OutboxEvent) error {
+	return r.changeStatus(ctx, id, from, map[string]interface{}{
 		"status":         models.StatusRejected,
 		"rejection_note": note,
 		"updated_at":     time.Now(),
-	}).Error
+	}, actor, note, outbox, nil)
 }
 
-func (r *achievementRepository) AddAttachment(mongoIDHex string, attachment models.Attachment) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+func (r *achievementRepository) Revoke(ctx context.Context, id uuid.UUID, from []models.AchievementStatus, reason string, actor models.StatusActor, outbox []models.OutboxEvent) error {
+	now := time.Now()
+	return r.changeStatus(ctx, id, from, map[string]interface{}{
+		"status":          models.StatusRevoked,
+		"revoked_at":      now,
+		"revocation_note": reason,
+		"updated_at":      now,
+	}, actor, reason, outbox, func(tx *gorm.DB, ref *models.AchievementReference) error {
+		return reverseAward(tx, ref, reason, actor)
+	})
+}
+
+// reverseAward membatalkan award lama (dari outbox versi sebelumnya) yang belum diproses, lalu
+// mengurangi poin tepat sebesar award yang benar-benar ada di ledger
+func reverseAward(tx *gorm.DB, ref *models.AchievementReference, note string, actor models.StatusActor) error {
+	if err := cancelOutboxEvents(tx, ref.ID, models.OutboxAwardPoints); err != nil {
+		return err
+	}
+	var awarded int
+	if err := tx.Model(&models.PointTransaction{}).
+		Where("achievement_ref_id = ? AND reason = ?", ref.ID, models.PointReasonAward).
+		Select("COALESCE(SUM(amount), 0)").Scan(&awarded).Error; err != nil {
+		return err
+	}
+	if awarded == 0 {
+		return nil
+	}
+	return recordPointTransaction(tx, &models.PointTransaction{
+		StudentID:        ref.StudentID,
+		AchievementRefID: &ref.ID,
+		Reason:           models.PointReasonRevoke,
+		Amount:           -awarded,
+		Note:             note,
+		ActorID:          actorID(actor),
+	})
+}
+
+// changeStatus mengupdate reference, mencatat event status dan menulis outbox dalam satu transaksi.
+// Status dicek ulang terhadap from setelah reference dikunci: workflow.Resolve berjalan sebelum
+// transaksi, jadi dua request paralel (mis. verify & reject) bisa sama-sama lolos di sana.
+// extra (opsional) dijalankan di transaksi yang sama setelah pengecekan itu.
+func (r *achievementRepository) changeStatus(ctx context.Context, id uuid.UUID, from []models.AchievementStatus, updates map[string]interface{}, actor models.StatusActor, note string, outbox []models.OutboxEvent, extra func(tx *gorm.DB, ref *models.AchievementReference) error) error {
+	return r.pg.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ref models.AchievementReference
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ref, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if !slices.Contains(from, ref.Status) {
+			return models.ErrInvalidTransition
+		}
+
+		if extra != nil {
+			if err := extra(tx, &ref); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&models.AchievementReference{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		event := models.AchievementStatusEvent{
+			AchievementRefID: id,
+			ActorRole:        actor.Role,
+			FromStatus:       ref.Status,
+			ToStatus:         updates["status"].(models.AchievementStatus),
+			Note:             note,
+			ActorID:          actorID(actor),
+		}
+		if err := tx.Create(&event).Error; err != nil {
+			return err
+		}
+		return insertOutboxEvents(tx, outbox)
+	})
+}
+
+func (r *achievementRepository) FindStatusEvents(ctx context.Context, id uuid.UUID) ([]models.AchievementStatusEvent, error) {
+	var events []models.AchievementStatusEvent
+	err := r.pg.WithContext(ctx).Where("achievement_ref_id = ?", id).Order("created_at asc").Find(&events).Error
+	return events, err
+}
+
+func (r *achievementRepository) AddAttachment(ctx context.Context, mongoIDHex string, attachment models.Attachment) error {
 	objID, _ := primitive.ObjectIDFromHex(mongoIDHex)
 	update := bson.M{
 		"$push": bson.M{"attachments": attachment},
@@ -141,23 +301,41 @@ func (r *achievementRepository) AddAttachment(mongoIDHex string, attachment mode
 	return err
 }
 
-func (r *achievementRepository) SoftDelete(id uuid.UUID) error {
-	return r.pg.Model(&models.AchievementReference{}).Where("id = ?", id).Update("status", models.StatusDeleted).Error
+// SoftDelete menandai prestasi deleted. Prestasi verified (hanya Admin) ditarik poinnya seperti revoke.
+func (r *achievementRepository) SoftDelete(ctx context.Context, id uuid.UUID, from []models.AchievementStatus, actor models.StatusActor, outbox []models.OutboxEvent) error {
+	return r.changeStatus(ctx, id, from, map[string]interface{}{
+		"status":     models.StatusDeleted,
+		"updated_at": time.Now(),
+	}, actor, "", outbox, func(tx *gorm.DB, ref *models.AchievementReference) error {
+		if ref.Status != models.StatusVerified {
+			return nil
+		}
+		return reverseAward(tx, ref, "achievement deleted", actor)
+	})
 }
 
-func (r *achievementRepository) GetMongoDetail(mongoID string) (*models.Achievement, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+func (r *achievementRepository) GetMongoDetail(ctx context.Context, mongoID string) (*models.Achievement, error) {
 	objID, _ := primitive.ObjectIDFromHex(mongoID)
 	var achievement models.Achievement
 	err := r.mongo.FindOne(ctx, bson.M{"_id": objID}).Decode(&achievement)
-	return &achievement, err
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, ErrMongoDocumentMissing
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &achievement, nil
 }
 
-func (r *achievementRepository) UpdateMongo(mongoID string, data models.Achievement) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+func (r *achievementRepository) UpdateMongo(ctx context.Context, mongoID string, data models.Achievement) error {
 	objID, _ := primitive.ObjectIDFromHex(mongoID)
+
+	// Jaga salinan tipe di Postgres tetap sama dengan dokumen Mongo
+	if err := r.pg.WithContext(ctx).Model(&models.AchievementReference{}).Where("mongo_achievement_id = ?", mongoID).
+		Update("achievement_type", data.AchievementType).Error; err != nil {
+		return err
+	}
+
 	update := bson.M{
 		"$set": bson.M{
 			"title":           data.Title,
@@ -165,9 +343,56 @@ func (r *achievementRepository) UpdateMongo(mongoID string, data models.Achievem
 			"achievementType": data.AchievementType,
 			"details":         data.Details,
 			"tags":            data.Tags,
+			"points":          data.Points,
 			"updatedAt":       time.Now(),
 		},
 	}
 	_, err := r.mongo.UpdateOne(ctx, bson.M{"_id": objID}, update)
 	return err
-}
\ No newline at end of file
+}
+
+func (r *achievementRepository) SyncMongoStatus(ctx context.Context, mongoID string, status models.AchievementStatus, pointsAwarded int) error {
+	objID, err := primitive.ObjectIDFromHex(mongoID)
+	if err != nil {
+		return err
+	}
+	_, err = r.mongo.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{
+		"$set": bson.M{"status": status, "pointsAwarded": pointsAwarded, "updatedAt": time.Now()},
+	})
+	return err
+}
+
+func (r *achievementRepository) SearchMongo(ctx context.Context, query string, studentIDs []uuid.UUID, limit int) ([]SearchHit, error) {
+	filter := bson.M{"$text": bson.M{"$search": query}}
+	if studentIDs != nil {
+		ids := make([]string, 0, len(studentIDs))
+		for _, id := range studentIDs {
+			ids = append(ids, id.String())
+		}
+		filter["studentId"] = bson.M{"$in": ids}
+	}
+
+	opts := options.Find().
+		SetProjection(bson.M{"score": bson.M{"$meta": "textScore"}}).
+		SetSort(bson.D{{Key: "score", Value: bson.M{"$meta": "textScore"}}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.mongo.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var hits []SearchHit
+	err = cursor.All(ctx, &hits)
+	return hits, err
+}
+
+func (r *achievementRepository) FindReferencesByMongoIDs(ctx context.Context, mongoIDs []string) ([]models.AchievementReference, error) {
+	var refs []models.AchievementReference
+	if len(mongoIDs) == 0 {
+		return refs, nil
+	}
+	err := r.pg.WithContext(ctx).Preload("Student.User").
+		Where("mongo_achievement_id IN ? AND status <> ?", mongoIDs, models.StatusDeleted).
+		Find(&refs).Error
+	return refs, err
+}
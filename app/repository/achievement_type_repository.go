@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"gouas/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AchievementTypeRepository interface {
+	FindAll(ctx context.Context) ([]models.AchievementType, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*models.AchievementType, error)
+	FindByName(ctx context.Context, name string) (*models.AchievementType, error)
+	Create(ctx context.Context, t models.AchievementType) (*models.AchievementType, error)
+	Update(ctx context.Context, t models.AchievementType) error
+}
+
+type achievementTypeRepository struct {
+	db *gorm.DB
+}
+
+func NewAchievementTypeRepository(db *gorm.DB) AchievementTypeRepository {
+	return &achievementTypeRepository{db}
+}
+
+func (r *achievementTypeRepository) FindAll(ctx context.Context) ([]models.AchievementType, error) {
+	var types []models.AchievementType
+	err := r.db.WithContext(ctx).Order("name asc").Find(&types).Error
+	return types, err
+}
+
+func (r *achievementTypeRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.AchievementType, error) {
+	var t models.AchievementType
+	err := r.db.WithContext(ctx).First(&t, "id = ?", id).Error
+	return &t, err
+}
+
+func (r *achievementTypeRepository) FindByName(ctx context.Context, name string) (*models.AchievementType, error) {
+	var t models.AchievementType
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&t).Error
+	return &t, err
+}
+
+func (r *achievementTypeRepository) Create(ctx context.Context, t models.AchievementType) (*models.AchievementType, error) {
+	err := r.db.WithContext(ctx).Create(&t).Error
+	return &t, err
+}
+
+func (r *achievementTypeRepository) Update(ctx context.Context, t models.AchievementType) error {
+	return r.db.WithContext(ctx).Model(&t).Select("label", "description", "schema", "is_active", "updated_at").Updates(&t).Error
+}
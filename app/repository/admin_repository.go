@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"gouas/app/models"
 
 	"github.com/google/uuid"
@@ -8,17 +9,21 @@ import (
 )
 
 type AdminRepository interface {
-	CreateUser(user models.User) (models.User, error)
-	FindRoleByName(name string) (models.Role, error)
-	UpdateUserRole(userID uuid.UUID, roleID uuid.UUID) error
-	FindAllUsers() ([]models.User, error)
-	FindUserByID(id uuid.UUID) (*models.User, error)
-	UpdateUser(user models.User) error
-	DeleteUser(id uuid.UUID) error
+	CreateUser(ctx context.Context, user models.User) (models.User, error)
+	FindRoleByName(ctx context.Context, name string) (models.Role, error)
+	UpdateUserRole(ctx context.Context, userID uuid.UUID, roleID uuid.UUID) error
+	FindAllUsers(ctx context.Context) ([]models.User, error)
+	FindUserByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	UpdateUser(ctx context.Context, user models.User) error
+	DeleteUser(ctx context.Context, id uuid.UUID) error
 	
+	// Scope unit organisasi user (fakultas/jurusan/prodi)
+	FindUserScopes(ctx context.Context, userID uuid.UUID) ([]models.UserScope, error)
+	ReplaceUserScopes(ctx context.Context, userID uuid.UUID, scopes []models.UserScope) error
+
 	// --- NEW: Helper untuk auto-create profile ---
-	CreateStudentProfile(student models.Student) error
-	CreateLecturerProfile(lecturer models.Lecturer) error
+	CreateStudentProfile(ctx context.Context, student models.Student) error
+	CreateLecturerProfile(ctx context.Context, lecturer models.Lecturer) error
 }
 
 type adminRepository struct {
@@ -32,48 +37,67 @@ func NewAdminRepository(db *gorm.DB) AdminRepository {
 // ... Fungsi-fungsi User standar (CreateUser, FindRoleByName, dll) biarkan sama ...
 // Saya tulis ulang yang standar agar anda tidak bingung copy-pastenya
 
-func (r *adminRepository) CreateUser(user models.User) (models.User, error) {
-	err := r.db.Create(&user).Error
+func (r *adminRepository) CreateUser(ctx context.Context, user models.User) (models.User, error) {
+	err := r.db.WithContext(ctx).Create(&user).Error
 	return user, err
 }
 
-func (r *adminRepository) FindRoleByName(name string) (models.Role, error) {
+func (r *adminRepository) FindRoleByName(ctx context.Context, name string) (models.Role, error) {
 	var role models.Role
-	err := r.db.Where("name = ?", name).First(&role).Error
+	err := r.db.WithContext(ctx).Preload("Permissions").Where("name = ?", name).First(&role).Error
 	return role, err
 }
 
-func (r *adminRepository) UpdateUserRole(userID uuid.UUID, roleID uuid.UUID) error {
-	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("role_id", roleID).Error
+func (r *adminRepository) UpdateUserRole(ctx context.Context, userID uuid.UUID, roleID uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Update("role_id", roleID).Error
 }
 
-func (r *adminRepository) FindAllUsers() ([]models.User, error) {
+func (r *adminRepository) FindAllUsers(ctx context.Context) ([]models.User, error) {
 	var users []models.User
-	err := r.db.Preload("Role").Find(&users).Error
+	err := r.db.WithContext(ctx).Preload("Role").Find(&users).Error
 	return users, err
 }
 
-func (r *adminRepository) FindUserByID(id uuid.UUID) (*models.User, error) {
+func (r *adminRepository) FindUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	var user models.User
-	err := r.db.Preload("Role").First(&user, "id = ?", id).Error
+	err := r.db.WithContext(ctx).Preload("Role.Permissions").Preload("Scopes").First(&user, "id = ?", id).Error
 	return &user, err
 }
 
-func (r *adminRepository) UpdateUser(user models.User) error {
-	return r.db.Save(&user).Error
+func (r *adminRepository) UpdateUser(ctx context.Context, user models.User) error {
+	return r.db.WithContext(ctx).Save(&user).Error
 }
 
-func (r *adminRepository) DeleteUser(id uuid.UUID) error {
+func (r *adminRepository) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	// Hapus User (Profile akan terhapus otomatis karena constraint ON DELETE CASCADE di database)
-	return r.db.Delete(&models.User{}, "id = ?", id).Error
+	return r.db.WithContext(ctx).Delete(&models.User{}, "id = ?", id).Error
+}
+
+func (r *adminRepository) FindUserScopes(ctx context.Context, userID uuid.UUID) ([]models.UserScope, error) {
+	var scopes []models.UserScope
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("unit, unit_id").Find(&scopes).Error
+	return scopes, err
+}
+
+// ReplaceUserScopes mengganti seluruh scope user dalam satu transaksi
+func (r *adminRepository) ReplaceUserScopes(ctx context.Context, userID uuid.UUID, scopes []models.UserScope) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserScope{}).Error; err != nil {
+			return err
+		}
+		if len(scopes) == 0 {
+			return nil
+		}
+		return tx.Create(&scopes).Error
+	})
 }
 
 // --- NEW IMPLEMENTATION ---
 
-func (r *adminRepository) CreateStudentProfile(student models.Student) error {
-	return r.db.Create(&student).Error
+func (r *adminRepository) CreateStudentProfile(ctx context.Context, student models.Student) error {
+	return r.db.WithContext(ctx).Create(&student).Error
 }
 
-func (r *adminRepository) CreateLecturerProfile(lecturer models.Lecturer) error {
-	return r.db.Create(&lecturer).Error
+func (r *adminRepository) CreateLecturerProfile(ctx context.Context, lecturer models.Lecturer) error {
+	return r.db.WithContext(ctx).Create(&lecturer).Error
 }
\ No newline at end of file
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"gouas/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AdvisorRepository mengelola penugasan dosen wali beserta riwayatnya
+type AdvisorRepository interface {
+	FindHistory(ctx context.Context, studentID uuid.UUID) ([]models.AdvisorAssignment, error)
+	// ApplyAssignments menutup penugasan lama, mencatat penugasan baru, mengubah students.advisor_id
+	// dan menulis event outbox dalam satu transaksi. Baris mahasiswa dikunci dan penugasan yang
+	// advisor_id-nya sudah tidak sama dengan PreviousLecturerID (diubah request lain) dilewati.
+	// outbox dipanggil dengan penugasan yang benar-benar diterapkan.
+	ApplyAssignments(ctx context.Context, assignments []models.AdvisorAssignment, outbox func(applied []models.AdvisorAssignment) ([]models.OutboxEvent, error)) ([]models.AdvisorAssignment, error)
+
+	// CountAdvisees menghitung jumlah mahasiswa bimbingan per dosen
+	CountAdvisees(ctx context.Context, lecturerIDs []uuid.UUID) (map[uuid.UUID]int64, error)
+	// FindActiveLecturers mengambil dosen di jurusan yang akun user-nya aktif
+	FindActiveLecturers(ctx context.Context, departmentID uuid.UUID) ([]models.Lecturer, error)
+	// FindUnassignedStudents mengambil mahasiswa tanpa dosen wali di program studi milik jurusan
+	FindUnassignedStudents(ctx context.Context, departmentID uuid.UUID) ([]models.Student, error)
+	// CountPendingSubmissions menghitung prestasi berstatus submitted per mahasiswa
+	CountPendingSubmissions(ctx context.Context, studentIDs []uuid.UUID) (map[uuid.UUID]int64, error)
+}
+
+type advisorRepository struct {
+	db *gorm.DB
+}
+
+func NewAdvisorRepository(db *gorm.DB) AdvisorRepository {
+	return &advisorRepository{db}
+}
+
+func (r *advisorRepository) FindHistory(ctx context.Context, studentID uuid.UUID) ([]models.AdvisorAssignment, error) {
+	var history []models.AdvisorAssignment
+	err := r.db.WithContext(ctx).Preload("Lecturer.User").
+		Where("student_id = ?", studentID).Order("effective_from desc").Find(&history).Error
+	return history, err
+}
+
+func (r *advisorRepository) ApplyAssignments(ctx context.Context, assignments []models.AdvisorAssignment, outbox func(applied []models.AdvisorAssignment) ([]models.OutboxEvent, error)) ([]models.AdvisorAssignment, error) {
+	var applied []models.AdvisorAssignment
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids := make([]uuid.UUID, 0, len(assignments))
+		for _, a := range assignments {
+			ids = append(ids, a.StudentID)
+		}
+		// Urut id agar dua transaksi yang mengunci mahasiswa sama tidak deadlock
+		var students []models.Student
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "advisor_id").
+			Where("id IN ?", ids).Order("id").Find(&students).Error; err != nil {
+			return err
+		}
+		current := make(map[uuid.UUID]*uuid.UUID, len(students))
+		for _, st := range students {
+			current[st.ID] = st.AdvisorID
+		}
+
+		for _, a := range assignments {
+			advisor, found := current[a.StudentID]
+			if !found || !sameLecturer(advisor, a.PreviousLecturerID) {
+				continue
+			}
+			if err := tx.Model(&models.AdvisorAssignment{}).
+				Where("student_id = ? AND effective_to IS NULL", a.StudentID).
+				Update("effective_to", a.EffectiveFrom).Error; err != nil {
+				return err
+			}
+			if err := tx.Omit("Lecturer").Create(&a).Error; err != nil {
+				return err
+			}
+			if err := tx.Model(&models.Student{}).Where("id = ?", a.StudentID).
+				Update("advisor_id", a.LecturerID).Error; err != nil {
+				return err
+			}
+			lecturerID := a.LecturerID
+			current[a.StudentID] = &lecturerID
+			applied = append(applied, a)
+		}
+
+		events, err := outbox(applied)
+		if err != nil {
+			return err
+		}
+		return insertOutboxEvents(tx, events)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+func sameLecturer(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func (r *advisorRepository) CountAdvisees(ctx context.Context, lecturerIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	loads := map[uuid.UUID]int64{}
+	if len(lecturerIDs) == 0 {
+		return loads, nil
+	}
+	var rows []struct {
+		AdvisorID uuid.UUID
+		Total     int64
+	}
+	err := r.db.WithContext(ctx).Model(&models.Student{}).
+		Select("advisor_id, COUNT(*) AS total").
+		Where("advisor_id IN ?", lecturerIDs).Group("advisor_id").Scan(&rows).Error
+	for _, row := range rows {
+		loads[row.AdvisorID] = row.Total
+	}
+	return loads, err
+}
+
+func (r *advisorRepository) FindActiveLecturers(ctx context.Context, departmentID uuid.UUID) ([]models.Lecturer, error) {
+	var lecturers []models.Lecturer
+	err := r.db.WithContext(ctx).Preload("User").
+		Joins("JOIN users ON users.id = lecturers.user_id").
+		Where("lecturers.department_id = ? AND users.is_active", departmentID).
+		Order("lecturers.nip").Find(&lecturers).Error
+	return lecturers, err
+}
+
+func (r *advisorRepository) FindUnassignedStudents(ctx context.Context, departmentID uuid.UUID) ([]models.Student, error) {
+	var students []models.Student
+	err := r.db.WithContext(ctx).Preload("User").
+		Where("advisor_id IS NULL AND study_program_id IN (?)",
+			r.db.Model(&models.StudyProgram{}).Select("id").Where("department_id = ?", departmentID)).
+		Order("nim").Find(&students).Error
+	return students, err
+}
+
+func (r *advisorRepository) CountPendingSubmissions(ctx context.Context, studentIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	pending := map[uuid.UUID]int64{}
+	if len(studentIDs) == 0 {
+		return pending, nil
+	}
+	var rows []struct {
+		StudentID uuid.UUID
+		Total     int64
+	}
+	err := r.db.WithContext(ctx).Model(&models.AchievementReference{}).
+		Select("student_id, COUNT(*) AS total").
+		Where("student_id IN ? AND status = ?", studentIDs, models.StatusSubmitted).
+		Group("student_id").Scan(&rows).Error
+	for _, row := range rows {
+		pending[row.StudentID] = row.Total
+	}
+	return pending, err
+}
@@ -1,17 +1,49 @@
 package repository
 
 import (
+	"context"
 	"gouas/app/models"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AuthRepository interface {
-	FindByUsername(username string) (*models.User, error)
-	FindByID(id uuid.UUID) (*models.User, error)
-	// [BARU] Update whitelist token ID
-	UpdateTokenIDs(userID uuid.UUID, accessID *uuid.UUID, refreshID *uuid.UUID) error
+	FindByUsername(ctx context.Context, username string) (*models.User, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+
+	// Session per perangkat; satu user boleh punya banyak session aktif.
+	// CreateSession menyimpan session beserta refresh token pertama family-nya.
+	CreateSession(ctx context.Context, session models.UserSession, refresh models.RefreshToken) (models.UserSession, error)
+	// FindActiveSession mengambil session yang belum dicabut & belum kedaluwarsa
+	FindActiveSession(ctx context.Context, id uuid.UUID) (*models.UserSession, error)
+	FindActiveSessions(ctx context.Context, userID uuid.UUID) ([]models.UserSession, error)
+
+	FindRefreshToken(ctx context.Context, id uuid.UUID) (*models.RefreshToken, error)
+	// RotateRefreshToken menandai token lama sudah dirotasi, menyimpan token pengganti dan
+	// access token baru session. Mengembalikan false jika token lama ternyata sudah dirotasi
+	// (dipakai ulang / request bersamaan).
+	RotateRefreshToken(ctx context.Context, oldID uuid.UUID, next models.RefreshToken, accessID uuid.UUID) (bool, error)
+	// RevokeSession mengembalikan false jika session tidak ditemukan / bukan milik user
+	RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (bool, error)
+	// RevokeAllSessions mencabut semua session selain exceptSessionID (uuid.Nil = semua)
+	RevokeAllSessions(ctx context.Context, userID uuid.UUID, exceptSessionID uuid.UUID) error
+
+	// UpdatePassword menyimpan hash baru, menghapus kewajiban ganti password dan
+	// mencabut session lain selain keepSessionID dalam satu transaksi
+	UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string, keepSessionID uuid.UUID) error
+
+	// CreateResetToken menyimpan token baru dan membatalkan token user yang belum terpakai
+	CreateResetToken(ctx context.Context, token models.PasswordResetToken) error
+	CountResetTokensSince(ctx context.Context, userID uuid.UUID, since time.Time) (int64, error)
+	// FindResetToken mengambil token yang belum terpakai & belum kedaluwarsa beserta user-nya
+	FindResetToken(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error)
+	// ResetPassword memakai token (sekali pakai), mengganti password & mencabut semua session.
+	// Mengembalikan false jika token sudah terpakai / kedaluwarsa.
+	ResetPassword(ctx context.Context, tokenID uuid.UUID, passwordHash string) (bool, error)
 }
 
 type authRepository struct {
@@ -22,39 +54,168 @@ func NewAuthRepository(db *gorm.DB) AuthRepository {
 	return &authRepository{db}
 }
 
-func (r *authRepository) FindByUsername(username string) (*models.User, error) {
+func (r *authRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	err := r.db.WithContext(ctx).Preload("Role.Permissions").Where("username = ?", username).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *authRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	var user models.User
-	err := r.db.Preload("Role.Permissions").Where("username = ?", username).First(&user).Error
+	err := r.db.WithContext(ctx).Preload("Role.Permissions").First(&user, "id = ?", id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *authRepository) FindByID(id uuid.UUID) (*models.User, error) {
+func (r *authRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	err := r.db.Preload("Role.Permissions").First(&user, "id = ?", id).Error
+	err := r.db.WithContext(ctx).Where("lower(email) = lower(?)", email).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-// [BARU] Implementasi Update Token IDs
-func (r *authRepository) UpdateTokenIDs(userID uuid.UUID, accessID *uuid.UUID, refreshID *uuid.UUID) error {
-	updates := map[string]interface{}{}
-	
-	// Selalu update Access Token ID (bisa UUID baru atau nil saat logout)
-	updates["current_access_token_id"] = accessID
-
-	// Refresh Token ID diupdate hanya jika parameter tidak nil 
-	// (Saat refresh token, kita biarkan refresh token ID tetap sama, kecuali logout)
-	if refreshID != nil {
-		updates["current_refresh_token_id"] = refreshID
-	} else if accessID == nil {
-		// Jika AccessID nil (Logout), maka RefreshID juga harus dihapus (nil)
-		updates["current_refresh_token_id"] = nil
+func (r *authRepository) CreateSession(ctx context.Context, session models.UserSession, refresh models.RefreshToken) (models.UserSession, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Bersihkan session lama user yang sudah tidak terpakai (refresh token ikut terhapus)
+		if err := tx.Where("user_id = ? AND (expires_at < ? OR revoked_at IS NOT NULL)", session.UserID, time.Now()).
+			Delete(&models.UserSession{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit("User").Create(&session).Error; err != nil {
+			return err
+		}
+		refresh.SessionID = session.ID
+		return tx.Omit("Session").Create(&refresh).Error
+	})
+	return session, err
+}
+
+func (r *authRepository) FindActiveSession(ctx context.Context, id uuid.UUID) (*models.UserSession, error) {
+	var session models.UserSession
+	err := r.db.WithContext(ctx).Where("id = ? AND revoked_at IS NULL AND expires_at > ?", id, time.Now()).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *authRepository) FindActiveSessions(ctx context.Context, userID uuid.UUID) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	err := r.db.WithContext(ctx).Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at desc").Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *authRepository) FindRefreshToken(ctx context.Context, id uuid.UUID) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.WithContext(ctx).First(&token, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *authRepository) RotateRefreshToken(ctx context.Context, oldID uuid.UUID, next models.RefreshToken, accessID uuid.UUID) (bool, error) {
+	rotated := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		// Klaim token lama secara atomic: hanya satu request yang bisa merotasi
+		res := tx.Model(&models.RefreshToken{}).Where("id = ? AND rotated_at IS NULL", oldID).
+			Updates(map[string]interface{}{"rotated_at": now, "replaced_by_id": next.ID})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		if err := tx.Omit("Session").Create(&next).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return tx.Model(&models.UserSession{}).Where("id = ?", next.SessionID).Updates(map[string]interface{}{
+			"access_token_id": accessID,
+			"last_seen_at":    now,
+			"expires_at":      next.ExpiresAt,
+		}).Error
+	})
+	return rotated && err == nil, err
+}
+
+func (r *authRepository) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&models.UserSession{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).Update("revoked_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *authRepository) RevokeAllSessions(ctx context.Context, userID uuid.UUID, exceptSessionID uuid.UUID) error {
+	return revokeSessions(r.db.WithContext(ctx), userID, exceptSessionID)
+}
+
+func revokeSessions(db *gorm.DB, userID uuid.UUID, exceptSessionID uuid.UUID) error {
+	return db.Model(&models.UserSession{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, exceptSessionID).Update("revoked_at", time.Now()).Error
+}
+
+func (r *authRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string, keepSessionID uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return updatePassword(tx, userID, passwordHash, keepSessionID)
+	})
+}
+
+func updatePassword(tx *gorm.DB, userID uuid.UUID, passwordHash string, keepSessionID uuid.UUID) error {
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"password_hash":        passwordHash,
+		"must_change_password": false,
+		"password_changed_at":  time.Now(),
+	}).Error; err != nil {
+		return err
 	}
+	return revokeSessions(tx, userID, keepSessionID)
+}
+
+func (r *authRepository) CreateResetToken(ctx context.Context, token models.PasswordResetToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.PasswordResetToken{}).Where("user_id = ? AND used_at IS NULL", token.UserID).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Omit("User").Create(&token).Error
+	})
+}
 
-	return r.db.Model(&models.User{}).Where("id = ?", userID).Updates(updates).Error
-}
\ No newline at end of file
+func (r *authRepository) CountResetTokensSince(ctx context.Context, userID uuid.UUID, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND created_at > ?", userID, since).Count(&count).Error
+	return count, err
+}
+
+func (r *authRepository) FindResetToken(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	err := r.db.WithContext(ctx).Preload("User").
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", tokenHash, time.Now()).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *authRepository) ResetPassword(ctx context.Context, tokenID uuid.UUID, passwordHash string) (bool, error) {
+	consumed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var token models.PasswordResetToken
+		// Klaim token secara atomic agar tidak bisa dipakai dua kali
+		res := tx.Model(&token).Clauses(clause.Returning{}).
+			Where("id = ? AND used_at IS NULL AND expires_at > ?", tokenID, time.Now()).Update("used_at", time.Now())
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		consumed = true
+		return updatePassword(tx, token.UserID, passwordHash, uuid.Nil)
+	})
+	return consumed && err == nil, err
+}
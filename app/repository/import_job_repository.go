@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"gouas/app/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ImportJobRepository interface {
+	Create(ctx context.Context, job models.ImportJob) (models.ImportJob, error)
+	Save(ctx context.Context, job models.ImportJob) error
+	FindByID(ctx context.Context, id uuid.UUID) (*models.ImportJob, error)
+	// FailInterrupted menandai job yang masih berjalan saat server mati sebagai failed
+	FailInterrupted(ctx context.Context) (int64, error)
+}
+
+type importJobRepository struct {
+	db *gorm.DB
+}
+
+func NewImportJobRepository(db *gorm.DB) ImportJobRepository {
+	return &importJobRepository{db}
+}
+
+func (r *importJobRepository) Create(ctx context.Context, job models.ImportJob) (models.ImportJob, error) {
+	err := r.db.WithContext(ctx).Create(&job).Error
+	return job, err
+}
+
+func (r *importJobRepository) Save(ctx context.Context, job models.ImportJob) error {
+	return r.db.WithContext(ctx).Save(&job).Error
+}
+
+func (r *importJobRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.ImportJob, error) {
+	var job models.ImportJob
+	err := r.db.WithContext(ctx).First(&job, "id = ?", id).Error
+	return &job, err
+}
+
+func (r *importJobRepository) FailInterrupted(ctx context.Context) (int64, error) {
+	res := r.db.WithContext(ctx).Model(&models.ImportJob{}).
+		Where("status IN ?", []models.ImportJobStatus{models.ImportPending, models.ImportRunning}).
+		Updates(map[string]interface{}{
+			"status":      models.ImportFailed,
+			"last_error":  "interrupted by server restart",
+			"finished_at": time.Now(),
+		})
+	return res.RowsAffected, res.Error
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"gouas/app/models"
 
 	"github.com/google/uuid"
@@ -8,10 +9,10 @@ import (
 )
 
 type LecturerRepository interface {
-	FindAll() ([]models.Lecturer, error)
-	FindByID(id uuid.UUID) (*models.Lecturer, error)
-	FindByUserID(userID uuid.UUID) (*models.Lecturer, error)
-	FindAdvisees(lecturerID uuid.UUID) ([]models.Student, error)
+	FindAll(ctx context.Context) ([]models.Lecturer, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Lecturer, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*models.Lecturer, error)
+	FindAdvisees(ctx context.Context, lecturerID uuid.UUID) ([]models.Student, error)
 }
 
 type lecturerRepository struct {
@@ -22,26 +23,26 @@ func NewLecturerRepository(db *gorm.DB) LecturerRepository {
 	return &lecturerRepository{db}
 }
 
-func (r *lecturerRepository) FindAll() ([]models.Lecturer, error) {
+func (r *lecturerRepository) FindAll(ctx context.Context) ([]models.Lecturer, error) {
 	var lecturers []models.Lecturer
-	err := r.db.Preload("User").Find(&lecturers).Error
+	err := r.db.WithContext(ctx).Preload("User").Find(&lecturers).Error
 	return lecturers, err
 }
 
-func (r *lecturerRepository) FindByID(id uuid.UUID) (*models.Lecturer, error) {
+func (r *lecturerRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Lecturer, error) {
 	var lecturer models.Lecturer
-	err := r.db.Preload("User").First(&lecturer, "id = ?", id).Error
+	err := r.db.WithContext(ctx).Preload("User").First(&lecturer, "id = ?", id).Error
 	return &lecturer, err
 }
 
-func (r *lecturerRepository) FindByUserID(userID uuid.UUID) (*models.Lecturer, error) {
+func (r *lecturerRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*models.Lecturer, error) {
 	var lecturer models.Lecturer
-	err := r.db.Preload("User").Where("user_id = ?", userID).First(&lecturer).Error
+	err := r.db.WithContext(ctx).Preload("User").Where("user_id = ?", userID).First(&lecturer).Error
 	return &lecturer, err
 }
 
-func (r *lecturerRepository) FindAdvisees(lecturerID uuid.UUID) ([]models.Student, error) {
+func (r *lecturerRepository) FindAdvisees(ctx context.Context, lecturerID uuid.UUID) ([]models.Student, error) {
 	var students []models.Student
-	err := r.db.Preload("User").Where("advisor_id = ?", lecturerID).Find(&students).Error
+	err := r.db.WithContext(ctx).Preload("User").Where("advisor_id = ?", lecturerID).Find(&students).Error
 	return students, err
 }
\ No newline at end of file
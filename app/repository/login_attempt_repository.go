@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"gouas/app/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// FailureStats adalah jumlah login gagal dalam window beserta waktu gagal terakhir
+type FailureStats struct {
+	Count    int64
+	LastFail *time.Time
+}
+
+// LoginAttemptRepository mencatat audit login & menghitung counter brute-force
+type LoginAttemptRepository interface {
+	Record(ctx context.Context, attempt models.LoginAttempt) error
+	// Reserve mengunci counter username & IP, memanggil decide dengan jumlah gagal sejak `since`
+	// (counter username dimulai ulang setelah login sukses / unlock admin), lalu mencatat percobaan
+	// di transaksi yang sama. Jika decide tidak menolak (result kosong) percobaan dicatat pending dan
+	// langsung ikut dihitung gagal, sehingga request paralel tidak bisa melewati batas bersamaan.
+	Reserve(ctx context.Context, attempt models.LoginAttempt, since time.Time, decide func(username, ip FailureStats) string) (models.LoginAttempt, error)
+	// Complete mengganti hasil percobaan pending setelah password dicek
+	Complete(ctx context.Context, id uuid.UUID, result string, userID *uuid.UUID) error
+	FindByUsername(ctx context.Context, username string, limit int) ([]models.LoginAttempt, error)
+}
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db}
+}
+
+// Hanya percobaan yang (akan) mengecek password yang dihitung sebagai gagal
+var countedFailures = []string{models.LoginPending, models.LoginInvalid, models.LoginInactive}
+
+func (r *loginAttemptRepository) Record(ctx context.Context, attempt models.LoginAttempt) error {
+	return r.db.WithContext(ctx).Create(&attempt).Error
+}
+
+func (r *loginAttemptRepository) Reserve(ctx context.Context, attempt models.LoginAttempt, since time.Time, decide func(username, ip FailureStats) string) (models.LoginAttempt, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Urutan lock selalu username lalu IP agar tidak deadlock; lock lepas saat commit
+		for _, key := range []string{"login:user:" + attempt.Username, "login:ip:" + attempt.IPAddress} {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", key).Error; err != nil {
+				return err
+			}
+		}
+
+		userStats, err := usernameFailures(tx, attempt.Username, since)
+		if err != nil {
+			return err
+		}
+		ipStats, err := failures(tx.Where("ip_address = ?", attempt.IPAddress), since)
+		if err != nil {
+			return err
+		}
+
+		attempt.Result = decide(userStats, ipStats)
+		if attempt.Result == "" {
+			attempt.Result = models.LoginPending
+		}
+		if attempt.ID == uuid.Nil {
+			attempt.ID = uuid.New()
+		}
+		return tx.Create(&attempt).Error
+	})
+	return attempt, err
+}
+
+func (r *loginAttemptRepository) Complete(ctx context.Context, id uuid.UUID, result string, userID *uuid.UUID) error {
+	return r.db.WithContext(ctx).Model(&models.LoginAttempt{}).
+		Where("id = ? AND result = ?", id, models.LoginPending).
+		Updates(map[string]interface{}{"result": result, "user_id": userID}).Error
+}
+
+func usernameFailures(tx *gorm.DB, username string, since time.Time) (FailureStats, error) {
+	var reset *time.Time
+	if err := tx.Model(&models.LoginAttempt{}).Select("MAX(created_at)").
+		Where("username = ? AND result IN ? AND created_at > ?", username, []string{models.LoginSucceeded, models.LoginAdminUnlock}, since).
+		Scan(&reset).Error; err != nil {
+		return FailureStats{}, err
+	}
+	if reset != nil {
+		since = *reset
+	}
+	return failures(tx.Where("username = ?", username), since)
+}
+
+func failures(scope *gorm.DB, since time.Time) (FailureStats, error) {
+	var stats FailureStats
+	err := scope.Model(&models.LoginAttempt{}).Select("COUNT(*) AS count, MAX(created_at) AS last_fail").
+		Where("result IN ? AND created_at > ?", countedFailures, since).Scan(&stats).Error
+	return stats, err
+}
+
+func (r *loginAttemptRepository) FindByUsername(ctx context.Context, username string, limit int) ([]models.LoginAttempt, error) {
+	var attempts []models.LoginAttempt
+	err := r.db.WithContext(ctx).Where("username = ?", username).Order("created_at desc").Limit(limit).Find(&attempts).Error
+	return attempts, err
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"gouas/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OrganizationRepository mengelola master data fakultas, jurusan & program studi
+type OrganizationRepository interface {
+	FindAllFaculties(ctx context.Context) ([]models.Faculty, error)
+	FindFacultyByID(ctx context.Context, id uuid.UUID) (*models.Faculty, error)
+	CreateFaculty(ctx context.Context, faculty models.Faculty) (models.Faculty, error)
+	UpdateFaculty(ctx context.Context, faculty models.Faculty) error
+	DeleteFaculty(ctx context.Context, id uuid.UUID) error
+
+	// facultyID nil = semua jurusan
+	FindAllDepartments(ctx context.Context, facultyID *uuid.UUID) ([]models.Department, error)
+	FindDepartmentByID(ctx context.Context, id uuid.UUID) (*models.Department, error)
+	CreateDepartment(ctx context.Context, dept models.Department) (models.Department, error)
+	UpdateDepartment(ctx context.Context, dept models.Department) error
+	DeleteDepartment(ctx context.Context, id uuid.UUID) error
+
+	// departmentID nil = semua program studi
+	FindAllStudyPrograms(ctx context.Context, departmentID *uuid.UUID) ([]models.StudyProgram, error)
+	FindStudyProgramByID(ctx context.Context, id uuid.UUID) (*models.StudyProgram, error)
+	CreateStudyProgram(ctx context.Context, program models.StudyProgram) (models.StudyProgram, error)
+	UpdateStudyProgram(ctx context.Context, program models.StudyProgram) error
+	DeleteStudyProgram(ctx context.Context, id uuid.UUID) error
+
+	// NameTaken mengecek nama (case-insensitive) pada unit yang sama di bawah parent yang sama
+	// (fakultas untuk jurusan, jurusan untuk program studi), selain excludeID
+	NameTaken(ctx context.Context, unit string, parentID *uuid.UUID, name string, excludeID uuid.UUID) (bool, error)
+	// CountDependents menghitung data yang masih merujuk unit (sub-unit, profil & scope user)
+	CountDependents(ctx context.Context, unit string, id uuid.UUID) (int64, error)
+	UnitExists(ctx context.Context, unit string, id uuid.UUID) (bool, error)
+	// FindUnitNames mengambil id & nama semua unit sejenis (bahan laporan nama mirip)
+	FindUnitNames(ctx context.Context, unit string) ([]UnitName, error)
+	// MergeUnits memindahkan semua rujukan (sub-unit, profil & scope user) dari source ke target
+	// lalu menghapus source, dalam satu transaksi. Mengembalikan jumlah rujukan yang dipindah.
+	MergeUnits(ctx context.Context, unit string, sourceID, targetID uuid.UUID) (int64, error)
+}
+
+type UnitName struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
+	return &organizationRepository{db}
+}
+
+// unitTables memetakan jenis unit ke tabel master datanya
+var unitTables = map[string]string{
+	models.UnitFaculty:      "faculties",
+	models.UnitDepartment:   "departments",
+	models.UnitProgramStudy: "study_programs",
+}
+
+// unitParentColumns memetakan jenis unit ke kolom parent-nya; nama unit unik per parent
+var unitParentColumns = map[string]string{
+	models.UnitDepartment:   "faculty_id",
+	models.UnitProgramStudy: "department_id",
+}
+
+// --- Faculty ---
+
+func (r *organizationRepository) FindAllFaculties(ctx context.Context) ([]models.Faculty, error) {
+	var faculties []models.Faculty
+	err := r.db.WithContext(ctx).Order("name").Find(&faculties).Error
+	return faculties, err
+}
+
+func (r *organizationRepository) FindFacultyByID(ctx context.Context, id uuid.UUID) (*models.Faculty, error) {
+	var faculty models.Faculty
+	err := r.db.WithContext(ctx).First(&faculty, "id = ?", id).Error
+	return &faculty, err
+}
+
+func (r *organizationRepository) CreateFaculty(ctx context.Context, faculty models.Faculty) (models.Faculty, error) {
+	err := r.db.WithContext(ctx).Create(&faculty).Error
+	return faculty, err
+}
+
+func (r *organizationRepository) UpdateFaculty(ctx context.Context, faculty models.Faculty) error {
+	return r.db.WithContext(ctx).Save(&faculty).Error
+}
+
+func (r *organizationRepository) DeleteFaculty(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.Faculty{}, "id = ?", id).Error
+}
+
+// --- Department ---
+
+func (r *organizationRepository) FindAllDepartments(ctx context.Context, facultyID *uuid.UUID) ([]models.Department, error) {
+	var depts []models.Department
+	q := r.db.WithContext(ctx).Preload("Faculty").Order("name")
+	if facultyID != nil {
+		q = q.Where("faculty_id = ?", *facultyID)
+	}
+	err := q.Find(&depts).Error
+	return depts, err
+}
+
+func (r *organizationRepository) FindDepartmentByID(ctx context.Context, id uuid.UUID) (*models.Department, error) {
+	var dept models.Department
+	err := r.db.WithContext(ctx).Preload("Faculty").First(&dept, "id = ?", id).Error
+	return &dept, err
+}
+
+func (r *organizationRepository) CreateDepartment(ctx context.Context, dept models.Department) (models.Department, error) {
+	err := r.db.WithContext(ctx).Create(&dept).Error
+	return dept, err
+}
+
+func (r *organizationRepository) UpdateDepartment(ctx context.Context, dept models.Department) error {
+	return r.db.WithContext(ctx).Omit("Faculty").Save(&dept).Error
+}
+
+func (r *organizationRepository) DeleteDepartment(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.Department{}, "id = ?", id).Error
+}
+
+// --- Study Program ---
+
+func (r *organizationRepository) FindAllStudyPrograms(ctx context.Context, departmentID *uuid.UUID) ([]models.StudyProgram, error) {
+	var programs []models.StudyProgram
+	q := r.db.WithContext(ctx).Preload("Department.Faculty").Order("name")
+	if departmentID != nil {
+		q = q.Where("department_id = ?", *departmentID)
+	}
+	err := q.Find(&programs).Error
+	return programs, err
+}
+
+func (r *organizationRepository) FindStudyProgramByID(ctx context.Context, id uuid.UUID) (*models.StudyProgram, error) {
+	var program models.StudyProgram
+	err := r.db.WithContext(ctx).Preload("Department.Faculty").First(&program, "id = ?", id).Error
+	return &program, err
+}
+
+func (r *organizationRepository) CreateStudyProgram(ctx context.Context, program models.StudyProgram) (models.StudyProgram, error) {
+	err := r.db.WithContext(ctx).Create(&program).Error
+	return program, err
+}
+
+func (r *organizationRepository) UpdateStudyProgram(ctx context.Context, program models.StudyProgram) error {
+	return r.db.WithContext(ctx).Omit("Department").Save(&program).Error
+}
+
+func (r *organizationRepository) DeleteStudyProgram(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.StudyProgram{}, "id = ?", id).Error
+}
+
+// --- Helper ---
+
+func (r *organizationRepository) NameTaken(ctx context.Context, unit string, parentID *uuid.UUID, name string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	q := r.db.WithContext(ctx).Table(unitTables[unit]).Where("lower(name) = lower(?) AND id <> ?", name, excludeID)
+	if col, ok := unitParentColumns[unit]; ok {
+		q = q.Where(col+" IS NOT DISTINCT FROM ?", parentID)
+	}
+	err := q.Count(&count).Error
+	return count > 0, err
+}
+
+func (r *organizationRepository) CountDependents(ctx context.Context, unit string, id uuid.UUID) (int64, error) {
+	db := r.db.WithContext(ctx)
+	var children []*gorm.DB
+	switch unit {
+	case models.UnitFaculty:
+		children = append(children, db.Table("departments").Where("faculty_id = ?", id))
+	case models.UnitDepartment:
+		children = append(children,
+			db.Table("study_programs").Where("department_id = ?", id),
+			db.Table("lecturers").Where("department_id = ?", id))
+	case models.UnitProgramStudy:
+		children = append(children, db.Table("students").Where("study_program_id = ?", id))
+	}
+	children = append(children, db.Table("user_scopes").Where("unit = ? AND unit_id = ?", unit, id))
+
+	var total int64
+	for _, q := range children {
+		var count int64
+		if err := q.Count(&count).Error; err != nil {
+			return 0, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+func (r *organizationRepository) UnitExists(ctx context.Context, unit string, id uuid.UUID) (bool, error) {
+	table, ok := unitTables[unit]
+	if !ok {
+		return false, nil
+	}
+	var count int64
+	err := r.db.WithContext(ctx).Table(table).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *organizationRepository) FindUnitNames(ctx context.Context, unit string) ([]UnitName, error) {
+	var names []UnitName
+	err := r.db.WithContext(ctx).Table(unitTables[unit]).Select("id, name").Order("name").Scan(&names).Error
+	return names, err
+}
+
+// unitReferences adalah kolom yang merujuk tiap jenis unit (selain user_scopes)
+var unitReferences = map[string][][2]string{
+	models.UnitFaculty:      {{"departments", "faculty_id"}},
+	models.UnitDepartment:   {{"study_programs", "department_id"}, {"lecturers", "department_id"}},
+	models.UnitProgramStudy: {{"students", "study_program_id"}},
+}
+
+func (r *organizationRepository) MergeUnits(ctx context.Context, unit string, sourceID, targetID uuid.UUID) (int64, error) {
+	var moved int64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, ref := range unitReferences[unit] {
+			result := tx.Table(ref[0]).Where(ref[1]+" = ?", sourceID).Update(ref[1], targetID)
+			if result.Error != nil {
+				return result.Error
+			}
+			moved += result.RowsAffected
+		}
+
+		// Scope ke source yang user-nya sudah punya scope ke target cukup dihapus
+		if err := tx.Exec(`DELETE FROM user_scopes s WHERE s.unit = ? AND s.unit_id = ? AND EXISTS
+			(SELECT 1 FROM user_scopes t WHERE t.user_id = s.user_id AND t.unit = s.unit AND t.unit_id = ?)`,
+			unit, sourceID, targetID).Error; err != nil {
+			return err
+		}
+		result := tx.Model(&models.UserScope{}).Where("unit = ? AND unit_id = ?", unit, sourceID).Update("unit_id", targetID)
+		if result.Error != nil {
+			return result.Error
+		}
+		moved += result.RowsAffected
+
+		return tx.Exec("DELETE FROM "+unitTables[unit]+" WHERE id = ?", sourceID).Error
+	})
+	return moved, err
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"gouas/app/models"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepository interface {
+	// Claim mengambil event pending yang sudah jatuh tempo dan menahannya selama lease,
+	// sehingga dispatcher lain tidak memprosesnya bersamaan. Jika worker mati, event diambil lagi setelah lease habis.
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error)
+	MarkDone(ctx context.Context, id uuid.UUID) error
+	// MarkRetry mencatat kegagalan; nextAttempt nil berarti event berhenti dicoba (failed)
+	MarkRetry(ctx context.Context, id uuid.UUID, errMsg string, nextAttempt *time.Time) error
+	FindFailed(ctx context.Context, limit int) ([]models.OutboxEvent, error)
+	// Requeue mengembalikan event failed ke antrean dengan counter percobaan baru; false jika event tidak failed
+	Requeue(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db}
+}
+
+func (r *outboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.OutboxPending, now).
+			Order("next_attempt_at asc").Limit(limit).
+			Find(&events).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(events))
+		for i := range events {
+			ids = append(ids, events[i].ID)
+			events[i].Attempts++
+		}
+		return tx.Model(&models.OutboxEvent{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": now.Add(lease),
+		}).Error
+	})
+	return events, err
+}
+
+func (r *outboxRepository) MarkDone(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	return r.db.WithContext(ctx).Model(&models.OutboxEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       models.OutboxDone,
+		"processed_at": now,
+		"last_error":   "",
+	}).Error
+}
+
+func (r *outboxRepository) MarkRetry(ctx context.Context, id uuid.UUID, errMsg string, nextAttempt *time.Time) error {
+	updates := map[string]interface{}{"last_error": errMsg}
+	if nextAttempt != nil {
+		updates["next_attempt_at"] = *nextAttempt
+	} else {
+		updates["status"] = models.OutboxFailed
+		updates["processed_at"] = time.Now()
+	}
+	return r.db.WithContext(ctx).Model(&models.OutboxEvent{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *outboxRepository) FindFailed(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.WithContext(ctx).Where("status = ?", models.OutboxFailed).
+		Order("processed_at desc").Limit(limit).Find(&events).Error
+	return events, err
+}
+
+func (r *outboxRepository) Requeue(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&models.OutboxEvent{}).
+		Where("id = ? AND status = ?", id, models.OutboxFailed).
+		Updates(map[string]interface{}{
+			"status":          models.OutboxPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+			"processed_at":    nil,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// cancelOutboxEvents membatalkan event yang belum selesai (pending/failed) untuk aggregate tertentu
+func cancelOutboxEvents(tx *gorm.DB, aggregateID uuid.UUID, kind string) error {
+	return tx.Model(&models.OutboxEvent{}).
+		Where("aggregate_id = ? AND kind = ? AND status IN ?", aggregateID, kind, []models.OutboxStatus{models.OutboxPending, models.OutboxFailed}).
+		Updates(map[string]interface{}{"status": models.OutboxCancelled, "processed_at": time.Now()}).Error
+}
+
+// insertOutboxEvents dipakai repository lain untuk menulis outbox di transaksi yang sedang berjalan
+func insertOutboxEvents(tx *gorm.DB, events []models.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return tx.Create(&events).Error
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"gouas/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PointRepository interface {
+	// Award idempotent: award kedua untuk achievement yang sama diabaikan, begitu juga
+	// award untuk achievement yang sudah tidak verified (mis. di-revoke lebih dulu)
+	Award(ctx context.Context, studentID uuid.UUID, achievementID uuid.UUID, amount int, actor models.StatusActor) error
+	FindByStudentID(ctx context.Context, studentID uuid.UUID) ([]models.PointTransaction, error)
+	// Reconcile menghitung ulang total_points dari ledger
+	Reconcile(ctx context.Context, studentID uuid.UUID) (int, error)
+}
+
+type pointRepository struct {
+	db *gorm.DB
+}
+
+func NewPointRepository(db *gorm.DB) PointRepository {
+	return &pointRepository{db}
+}
+
+func (r *pointRepository) Award(ctx context.Context, studentID uuid.UUID, achievementID uuid.UUID, amount int, actor models.StatusActor) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Kunci reference agar tidak balapan dengan Revoke
+		var ref models.AchievementReference
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&ref, "id = ?", achievementID).Error; err != nil {
+			return err
+		}
+		if ref.Status != models.StatusVerified {
+			return nil
+		}
+		return recordPointTransaction(tx, &models.PointTransaction{
+			StudentID:        studentID,
+			AchievementRefID: &achievementID,
+			Reason:           models.PointReasonAward,
+			Amount:           amount,
+			ActorID:          actorID(actor),
+		})
+	})
+}
+
+func (r *pointRepository) FindByStudentID(ctx context.Context, studentID uuid.UUID) ([]models.PointTransaction, error) {
+	var trxs []models.PointTransaction
+	err := r.db.WithContext(ctx).Where("student_id = ?", studentID).Order("created_at asc").Find(&trxs).Error
+	return trxs, err
+}
+
+func (r *pointRepository) Reconcile(ctx context.Context, studentID uuid.UUID) (int, error) {
+	var total int
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.PointTransaction{}).Where("student_id = ?", studentID).
+			Select("COALESCE(SUM(amount), 0)").Scan(&total).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Student{}).Where("id = ?", studentID).Update("total_points", total).Error
+	})
+	return total, err
+}
+
+// recordPointTransaction menulis ledger dan menyesuaikan total_points di transaksi yang sama.
+// Jika transaksi untuk achievement+reason sudah ada, tidak ada yang diubah.
+func recordPointTransaction(tx *gorm.DB, trx *models.PointTransaction) error {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(trx)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+	return tx.Model(&models.Student{}).Where("id = ?", trx.StudentID).
+		Update("total_points", gorm.Expr("total_points + ?", trx.Amount)).Error
+}
+
+func actorID(actor models.StatusActor) *uuid.UUID {
+	if actor.UserID == uuid.Nil {
+		return nil
+	}
+	id := actor.UserID
+	return &id
+}
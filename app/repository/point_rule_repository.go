@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"gouas/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PointRuleRepository interface {
+	FindAll(ctx context.Context) ([]models.PointRule, error)
+	FindActive(ctx context.Context) ([]models.PointRule, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*models.PointRule, error)
+	Create(ctx context.Context, rule models.PointRule) (*models.PointRule, error)
+	Update(ctx context.Context, rule models.PointRule) error
+}
+
+type pointRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewPointRuleRepository(db *gorm.DB) PointRuleRepository {
+	return &pointRuleRepository{db}
+}
+
+func (r *pointRuleRepository) FindAll(ctx context.Context) ([]models.PointRule, error) {
+	var rules []models.PointRule
+	err := r.db.WithContext(ctx).Order("priority desc, created_at asc").Find(&rules).Error
+	return rules, err
+}
+
+func (r *pointRuleRepository) FindActive(ctx context.Context) ([]models.PointRule, error) {
+	var rules []models.PointRule
+	err := r.db.WithContext(ctx).Where("is_active = ?", true).Order("priority desc, created_at asc").Find(&rules).Error
+	return rules, err
+}
+
+func (r *pointRuleRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.PointRule, error) {
+	var rule models.PointRule
+	err := r.db.WithContext(ctx).First(&rule, "id = ?", id).Error
+	return &rule, err
+}
+
+func (r *pointRuleRepository) Create(ctx context.Context, rule models.PointRule) (*models.PointRule, error) {
+	err := r.db.WithContext(ctx).Create(&rule).Error
+	return &rule, err
+}
+
+func (r *pointRuleRepository) Update(ctx context.Context, rule models.PointRule) error {
+	// Select("*") agar nilai nol (IsActive=false, Rank=0) ikut tersimpan
+	return r.db.WithContext(ctx).Model(&rule).Select("*").Omit("created_at").Updates(&rule).Error
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gouas/app/models"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProfileRepository mengelola profile Mahasiswa/Dosen Wali & kontak user
+type ProfileRepository interface {
+	CreateStudent(ctx context.Context, student models.Student) (models.Student, error)
+	UpdateStudent(ctx context.Context, student models.Student) error
+	DeleteStudent(ctx context.Context, id uuid.UUID) error
+	// CountStudentRecords menghitung prestasi & transaksi poin milik mahasiswa
+	CountStudentRecords(ctx context.Context, studentID uuid.UUID) (int64, error)
+
+	CreateLecturer(ctx context.Context, lecturer models.Lecturer) (models.Lecturer, error)
+	UpdateLecturer(ctx context.Context, lecturer models.Lecturer) error
+	DeleteLecturer(ctx context.Context, id uuid.UUID) error
+
+	// Cek unik selain excludeID (uuid.Nil = tanpa pengecualian)
+	NIMTaken(ctx context.Context, nim string, excludeID uuid.UUID) (bool, error)
+	NIPTaken(ctx context.Context, nip string, excludeID uuid.UUID) (bool, error)
+	EmailTaken(ctx context.Context, email string, excludeUserID uuid.UUID) (bool, error)
+	UsernameTaken(ctx context.Context, username string) (bool, error)
+	// TakenValues mengecek banyak nilai sekaligus (satu query IN per kolom) untuk import massal.
+	// field: username, email, nim, nip; email dikembalikan dalam huruf kecil
+	TakenValues(ctx context.Context, field string, values []string) (map[string]bool, error)
+
+	UpdateContact(ctx context.Context, userID uuid.UUID, email string, phone string) error
+}
+
+type profileRepository struct {
+	db *gorm.DB
+}
+
+func NewProfileRepository(db *gorm.DB) ProfileRepository {
+	return &profileRepository{db}
+}
+
+// --- Student ---
+
+func (r *profileRepository) CreateStudent(ctx context.Context, student models.Student) (models.Student, error) {
+	err := r.db.WithContext(ctx).Omit("User", "StudyProgram", "Advisor").Create(&student).Error
+	return student, err
+}
+
+func (r *profileRepository) UpdateStudent(ctx context.Context, student models.Student) error {
+	return r.db.WithContext(ctx).Model(&models.Student{}).Where("id = ?", student.ID).Updates(map[string]interface{}{
+		"nim":              student.NIM,
+		"study_program_id": student.StudyProgramID,
+		"academic_year":    student.AcademicYear,
+	}).Error
+}
+
+func (r *profileRepository) DeleteStudent(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.Student{}, "id = ?", id).Error
+}
+
+func (r *profileRepository) CountStudentRecords(ctx context.Context, studentID uuid.UUID) (int64, error) {
+	db := r.db.WithContext(ctx)
+	var refs, txs int64
+	if err := db.Model(&models.AchievementReference{}).Where("student_id = ?", studentID).Count(&refs).Error; err != nil {
+		return 0, err
+	}
+	if err := db.Model(&models.PointTransaction{}).Where("student_id = ?", studentID).Count(&txs).Error; err != nil {
+		return 0, err
+	}
+	return refs + txs, nil
+}
+
+// --- Lecturer ---
+
+func (r *profileRepository) CreateLecturer(ctx context.Context, lecturer models.Lecturer) (models.Lecturer, error) {
+	err := r.db.WithContext(ctx).Omit("User", "Department").Create(&lecturer).Error
+	return lecturer, err
+}
+
+func (r *profileRepository) UpdateLecturer(ctx context.Context, lecturer models.Lecturer) error {
+	return r.db.WithContext(ctx).Model(&models.Lecturer{}).Where("id = ?", lecturer.ID).Updates(map[string]interface{}{
+		"nip":           lecturer.NIP,
+		"department_id": lecturer.DepartmentID,
+	}).Error
+}
+
+func (r *profileRepository) DeleteLecturer(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&models.Lecturer{}, "id = ?", id).Error
+}
+
+// --- Unik & kontak ---
+
+func (r *profileRepository) NIMTaken(ctx context.Context, nim string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Student{}).Where("nim = ? AND id <> ?", nim, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *profileRepository) NIPTaken(ctx context.Context, nip string, excludeID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Lecturer{}).Where("nip = ? AND id <> ?", nip, excludeID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *profileRepository) EmailTaken(ctx context.Context, email string, excludeUserID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where("lower(email) = lower(?) AND id <> ?", email, excludeUserID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *profileRepository) UsernameTaken(ctx context.Context, username string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where("username = ?", username).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *profileRepository) TakenValues(ctx context.Context, field string, values []string) (map[string]bool, error) {
+	taken := map[string]bool{}
+	if len(values) == 0 {
+		return taken, nil
+	}
+	q := r.db.WithContext(ctx)
+	switch field {
+	case "username":
+		q = q.Model(&models.User{}).Where("username IN ?", values).Select("username")
+	case "email":
+		lowered := make([]string, len(values))
+		for i, v := range values {
+			lowered[i] = strings.ToLower(v)
+		}
+		q = q.Model(&models.User{}).Where("lower(email) IN ?", lowered).Select("lower(email)")
+	case "nim":
+		q = q.Model(&models.Student{}).Where("nim IN ?", values).Select("nim")
+	case "nip":
+		q = q.Model(&models.Lecturer{}).Where("nip IN ?", values).Select("nip")
+	default:
+		return nil, fmt.Errorf("unknown unique field %q", field)
+	}
+	var found []string
+	if err := q.Pluck(field, &found).Error; err != nil {
+		return nil, err
+	}
+	for _, v := range found {
+		taken[v] = true
+	}
+	return taken, nil
+}
+
+func (r *profileRepository) UpdateContact(ctx context.Context, userID uuid.UUID, email string, phone string) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"email": email,
+		"phone": phone,
+	}).Error
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"gouas/app/models"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"gorm.io/gorm"
+)
+
+// MongoAchievementStub adalah proyeksi ringan dokumen Mongo untuk rekonsiliasi
+type MongoAchievementStub struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	StudentID string             `bson:"studentId"`
+	CreatedAt time.Time          `bson:"createdAt"`
+}
+
+// ReconcileRepository membaca & memperbaiki inkonsistensi antara Postgres dan Mongo
+type ReconcileRepository interface {
+	ListMongoAchievements(ctx context.Context) ([]MongoAchievementStub, error)
+	ListReferences(ctx context.Context) ([]models.AchievementReference, error)
+	DeleteMongoAchievement(ctx context.Context, mongoID string) error
+	SetMongoStudentID(ctx context.Context, mongoID string, studentID uuid.UUID) error
+	// MarkReferenceDeleted soft delete reference yang dokumennya hilang (dicatat di riwayat status)
+	MarkReferenceDeleted(ctx context.Context, id uuid.UUID, note string) error
+}
+
+type reconcileRepository struct {
+	pg    *gorm.DB
+	mongo *mongo.Collection
+}
+
+func NewReconcileRepository(pg *gorm.DB, mongoDB *mongo.Database) ReconcileRepository {
+	return &reconcileRepository{pg: pg, mongo: mongoDB.Collection("achievements")}
+}
+
+func (r *reconcileRepository) ListMongoAchievements(ctx context.Context) ([]MongoAchievementStub, error) {
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "studentId": 1, "createdAt": 1})
+	cursor, err := r.mongo.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var docs []MongoAchievementStub
+	err = cursor.All(ctx, &docs)
+	return docs, err
+}
+
+func (r *reconcileRepository) ListReferences(ctx context.Context) ([]models.AchievementReference, error) {
+	var refs []models.AchievementReference
+	err := r.pg.WithContext(ctx).Select("id", "student_id", "mongo_achievement_id", "status", "created_at").Find(&refs).Error
+	return refs, err
+}
+
+func (r *reconcileRepository) DeleteMongoAchievement(ctx context.Context, mongoID string) error {
+	objID, err := primitive.ObjectIDFromHex(mongoID)
+	if err != nil {
+		return err
+	}
+	_, err = r.mongo.DeleteOne(ctx, bson.M{"_id": objID})
+	return err
+}
+
+func (r *reconcileRepository) SetMongoStudentID(ctx context.Context, mongoID string, studentID uuid.UUID) error {
+	objID, err := primitive.ObjectIDFromHex(mongoID)
+	if err != nil {
+		return err
+	}
+	_, err = r.mongo.UpdateOne(ctx, bson.M{"_id": objID}, bson.M{"$set": bson.M{"studentId": studentID.String()}})
+	return err
+}
+
+func (r *reconcileRepository) MarkReferenceDeleted(ctx context.Context, id uuid.UUID, note string) error {
+	return r.pg.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ref models.AchievementReference
+		if err := tx.First(&ref, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&ref).Updates(map[string]interface{}{
+			"status":     models.StatusDeleted,
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.AchievementStatusEvent{
+			AchievementRefID: id,
+			ActorRole:        models.SystemActorRole,
+			FromStatus:       ref.Status,
+			ToStatus:         models.StatusDeleted,
+			Note:             note,
+		}).Error
+	})
+}
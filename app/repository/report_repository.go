@@ -4,13 +4,15 @@ import (
 	"context"
 	"gouas/app/models"
 
+	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"gorm.io/gorm"
 )
 
 type ReportRepository interface {
-	GetAchievementStats() (map[string]interface{}, error)
+	// studentIDs nil = statistik global
+	GetAchievementStats(ctx context.Context, studentIDs []uuid.UUID) (map[string]interface{}, error)
 }
 
 type reportRepository struct {
@@ -25,7 +27,7 @@ func NewReportRepository(pg *gorm.DB, mongoDB *mongo.Database) ReportRepository
 	}
 }
 
-func (r *reportRepository) GetAchievementStats() (map[string]interface{}, error) {
+func (r *reportRepository) GetAchievementStats(ctx context.Context, studentIDs []uuid.UUID) (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
 
 	// 1. PostgreSQL: Count by Status
@@ -33,21 +35,53 @@ func (r *reportRepository) GetAchievementStats() (map[string]interface{}, error)
 		Status string
 		Count  int
 	}
-	r.pg.Model(&models.AchievementReference{}).Select("status, count(*) as count").Group("status").Scan(&statusCounts)
+	q := r.pg.WithContext(ctx).Model(&models.AchievementReference{})
+	if studentIDs != nil && len(studentIDs) == 0 {
+		q = q.Where("1 = 0")
+	} else if studentIDs != nil {
+		q = q.Where("student_id IN ?", studentIDs)
+	}
+	q.Select("status, count(*) as count").Group("status").Scan(&statusCounts)
 	stats["by_status"] = statusCounts
 
-	// 2. MongoDB: Count by Type (Aggregation)
-	pipeline := mongo.Pipeline{
-		{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: "$achievementType"},
-			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
+	// 2. PostgreSQL: Count by Program Studi (master data, bukan teks bebas)
+	var programCounts []struct {
+		StudyProgramID *string
+		StudyProgram   string
+		Count          int
+	}
+	pq := r.pg.WithContext(ctx).Table("achievement_references ar").
+		Select("sp.id AS study_program_id, COALESCE(sp.name, '(unassigned)') AS study_program, count(*) AS count").
+		Joins("JOIN students s ON s.id = ar.student_id").
+		Joins("LEFT JOIN study_programs sp ON sp.id = s.study_program_id").
+		Where("ar.status <> ?", models.StatusDeleted).
+		Group("sp.id, sp.name").Order("count DESC")
+	if studentIDs != nil && len(studentIDs) == 0 {
+		pq = pq.Where("1 = 0")
+	} else if studentIDs != nil {
+		pq = pq.Where("ar.student_id IN ?", studentIDs)
+	}
+	pq.Scan(&programCounts)
+	stats["by_program"] = programCounts
+
+	// 3. MongoDB: Count by Type (Aggregation)
+	pipeline := mongo.Pipeline{}
+	if studentIDs != nil {
+		ids := make([]string, 0, len(studentIDs))
+		for _, id := range studentIDs {
+			ids = append(ids, id.String())
+		}
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"studentId": bson.M{"$in": ids}}}})
 	}
+	pipeline = append(pipeline, bson.D{{Key: "$group", Value: bson.D{
+		{Key: "_id", Value: "$achievementType"},
+		{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+	}}})
 
-	cursor, err := r.mongo.Aggregate(context.Background(), pipeline)
+	cursor, err := r.mongo.Aggregate(ctx, pipeline)
 	if err == nil {
 		var typeCounts []bson.M
-		if err = cursor.All(context.Background(), &typeCounts); err == nil {
+		if err = cursor.All(ctx, &typeCounts); err == nil {
 			stats["by_type"] = typeCounts
 		}
 	}
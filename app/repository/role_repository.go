@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"gouas/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RoleRepository interface {
+	FindAllRoles(ctx context.Context) ([]models.Role, error)
+	FindRoleByID(ctx context.Context, id uuid.UUID) (*models.Role, error)
+	CreateRole(ctx context.Context, role models.Role) (models.Role, error)
+	UpdateRole(ctx context.Context, role models.Role) error
+	DeleteRole(ctx context.Context, id uuid.UUID) error
+	CountUsersByRole(ctx context.Context, roleID uuid.UUID) (int64, error)
+
+	FindAllPermissions(ctx context.Context) ([]models.Permission, error)
+	FindPermissionByID(ctx context.Context, id uuid.UUID) (*models.Permission, error)
+	CreatePermission(ctx context.Context, perm models.Permission) (models.Permission, error)
+	UpdatePermission(ctx context.Context, perm models.Permission) error
+	DeletePermission(ctx context.Context, id uuid.UUID) error
+
+	// Relasi role_permissions
+	AttachPermission(ctx context.Context, roleID uuid.UUID, perm models.Permission) error
+	DetachPermission(ctx context.Context, roleID uuid.UUID, perm models.Permission) error
+
+	// CountUsersWithPermission menghitung user aktif yang memiliki permission lewat role-nya.
+	// excludeRoleID / excludeUserID = uuid.Nil berarti tanpa pengecualian.
+	CountUsersWithPermission(ctx context.Context, perm string, excludeRoleID, excludeUserID uuid.UUID) (int64, error)
+	// LockPermissionHolders mengambil advisory lock "lockout-permissions" sampai transaksi selesai,
+	// agar cek pemegang terakhir & perubahannya tidak balapan. Hanya berarti di dalam UnitOfWork.
+	LockPermissionHolders(ctx context.Context) error
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db}
+}
+
+func (r *roleRepository) FindAllRoles(ctx context.Context) ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Order("name").Find(&roles).Error
+	return roles, err
+}
+
+func (r *roleRepository) FindRoleByID(ctx context.Context, id uuid.UUID) (*models.Role, error) {
+	var role models.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").First(&role, "id = ?", id).Error
+	return &role, err
+}
+
+func (r *roleRepository) CreateRole(ctx context.Context, role models.Role) (models.Role, error) {
+	err := r.db.WithContext(ctx).Create(&role).Error
+	return role, err
+}
+
+func (r *roleRepository) UpdateRole(ctx context.Context, role models.Role) error {
+	return r.db.WithContext(ctx).Model(&models.Role{}).Where("id = ?", role.ID).
+		Updates(map[string]interface{}{"name": role.Name, "description": role.Description}).Error
+}
+
+func (r *roleRepository) DeleteRole(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		role := models.Role{ID: id}
+		if err := tx.Model(&role).Association("Permissions").Clear(); err != nil {
+			return err
+		}
+		return tx.Delete(&models.Role{}, "id = ?", id).Error
+	})
+}
+
+func (r *roleRepository) CountUsersByRole(ctx context.Context, roleID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.User{}).Where("role_id = ?", roleID).Count(&count).Error
+	return count, err
+}
+
+func (r *roleRepository) FindAllPermissions(ctx context.Context) ([]models.Permission, error) {
+	var perms []models.Permission
+	err := r.db.WithContext(ctx).Order("resource, action").Find(&perms).Error
+	return perms, err
+}
+
+func (r *roleRepository) FindPermissionByID(ctx context.Context, id uuid.UUID) (*models.Permission, error) {
+	var perm models.Permission
+	err := r.db.WithContext(ctx).First(&perm, "id = ?", id).Error
+	return &perm, err
+}
+
+func (r *roleRepository) CreatePermission(ctx context.Context, perm models.Permission) (models.Permission, error) {
+	err := r.db.WithContext(ctx).Create(&perm).Error
+	return perm, err
+}
+
+func (r *roleRepository) UpdatePermission(ctx context.Context, perm models.Permission) error {
+	return r.db.WithContext(ctx).Model(&models.Permission{}).Where("id = ?", perm.ID).
+		Update("description", perm.Description).Error
+}
+
+func (r *roleRepository) DeletePermission(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM role_permissions WHERE permission_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Permission{}, "id = ?", id).Error
+	})
+}
+
+func (r *roleRepository) AttachPermission(ctx context.Context, roleID uuid.UUID, perm models.Permission) error {
+	role := models.Role{ID: roleID}
+	return r.db.WithContext(ctx).Model(&role).Association("Permissions").Append(&perm)
+}
+
+func (r *roleRepository) DetachPermission(ctx context.Context, roleID uuid.UUID, perm models.Permission) error {
+	role := models.Role{ID: roleID}
+	return r.db.WithContext(ctx).Model(&role).Association("Permissions").Delete(&perm)
+}
+
+func (r *roleRepository) CountUsersWithPermission(ctx context.Context, perm string, excludeRoleID, excludeUserID uuid.UUID) (int64, error) {
+	var count int64
+	q := r.db.WithContext(ctx).Model(&models.User{}).
+		Joins("JOIN role_permissions rp ON rp.role_id = users.role_id").
+		Joins("JOIN permissions p ON p.id = rp.permission_id").
+		Where("p.name = ? AND users.is_active = ?", perm, true)
+	if excludeRoleID != uuid.Nil {
+		q = q.Where("users.role_id <> ?", excludeRoleID)
+	}
+	if excludeUserID != uuid.Nil {
+		q = q.Where("users.id <> ?", excludeUserID)
+	}
+	err := q.Count(&count).Error
+	return count, err
+}
+
+func (r *roleRepository) LockPermissionHolders(ctx context.Context) error {
+	return r.db.WithContext(ctx).Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "lockout-permissions").Error
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"gouas/app/models"
 
 	"github.com/google/uuid"
@@ -8,13 +9,11 @@ import (
 )
 
 type StudentRepository interface {
-	FindAll() ([]models.Student, error)
-	FindByID(id uuid.UUID) (*models.Student, error)
-	FindByUserID(userID uuid.UUID) (*models.Student, error)
-	UpdateAdvisor(studentID uuid.UUID, advisorID uuid.UUID) error
-	
-	// [BARU] Method Tambah Poin
-	AddPoints(studentID uuid.UUID, points int) error
+	FindAll(ctx context.Context) ([]models.Student, error)
+	FindByUnits(ctx context.Context, scopes []models.UserScope) ([]models.Student, error)
+	InUnits(ctx context.Context, studentID uuid.UUID, scopes []models.UserScope) (bool, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*models.Student, error)
+	FindByUserID(ctx context.Context, userID uuid.UUID) (*models.Student, error)
 }
 
 type studentRepository struct {
@@ -25,30 +24,36 @@ func NewStudentRepository(db *gorm.DB) StudentRepository {
 	return &studentRepository{db}
 }
 
-func (r *studentRepository) FindAll() ([]models.Student, error) {
+func (r *studentRepository) FindAll(ctx context.Context) ([]models.Student, error) {
 	var students []models.Student
-	err := r.db.Preload("User").Preload("Advisor.User").Find(&students).Error
+	err := r.db.WithContext(ctx).Preload("User").Preload("Advisor.User").Preload("StudyProgram.Department.Faculty").Find(&students).Error
 	return students, err
 }
 
-func (r *studentRepository) FindByID(id uuid.UUID) (*models.Student, error) {
-	var student models.Student
-	err := r.db.Preload("User").Preload("Advisor.User").First(&student, "id = ?", id).Error
-	return &student, err
+// FindByUnits mengambil mahasiswa yang berada di unit organisasi scope
+func (r *studentRepository) FindByUnits(ctx context.Context, scopes []models.UserScope) ([]models.Student, error) {
+	var students []models.Student
+	q := r.db.WithContext(ctx).Preload("User").Preload("Advisor.User").Preload("StudyProgram.Department.Faculty")
+	err := applyUnitScope(q, scopes).Find(&students).Error
+	return students, err
 }
 
-func (r *studentRepository) FindByUserID(userID uuid.UUID) (*models.Student, error) {
+// InUnits mengecek apakah mahasiswa berada di unit organisasi scope
+func (r *studentRepository) InUnits(ctx context.Context, studentID uuid.UUID, scopes []models.UserScope) (bool, error) {
+	var count int64
+	q := r.db.WithContext(ctx).Model(&models.Student{}).Where("students.id = ?", studentID)
+	err := applyUnitScope(q, scopes).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *studentRepository) FindByID(ctx context.Context, id uuid.UUID) (*models.Student, error) {
 	var student models.Student
-	err := r.db.Preload("User").Where("user_id = ?", userID).First(&student).Error
+	err := r.db.WithContext(ctx).Preload("User").Preload("Advisor.User").Preload("StudyProgram.Department.Faculty").First(&student, "id = ?", id).Error
 	return &student, err
 }
 
-func (r *studentRepository) UpdateAdvisor(studentID uuid.UUID, advisorID uuid.UUID) error {
-	return r.db.Model(&models.Student{}).Where("id = ?", studentID).Update("advisor_id", advisorID).Error
+func (r *studentRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (*models.Student, error) {
+	var student models.Student
+	err := r.db.WithContext(ctx).Preload("User").Where("user_id = ?", userID).First(&student).Error
+	return &student, err
 }
-
-// [BARU] Implementasi Tambah Poin (Atomic Update)
-func (r *studentRepository) AddPoints(studentID uuid.UUID, points int) error {
-	return r.db.Model(&models.Student{}).Where("id = ?", studentID).
-		Update("total_points", gorm.Expr("total_points + ?", points)).Error
-}
\ No newline at end of file
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
+)
+
+// TxRepositories berisi repository yang terikat ke satu transaksi Postgres.
+// Operasi Mongo di AchievementRepository TIDAK ikut transaksi.
+type TxRepositories struct {
+	Admin       AdminRepository
+	Student     StudentRepository
+	Lecturer    LecturerRepository
+	Achievement AchievementRepository
+	Point       PointRepository
+	Outbox      OutboxRepository
+	Role        RoleRepository
+	Advisor     AdvisorRepository
+}
+
+// UnitOfWork menjalankan beberapa operasi repository secara atomic:
+// commit jika fn mengembalikan nil, rollback jika error/panic.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(repos TxRepositories) error) error
+}
+
+type unitOfWork struct {
+	db      *gorm.DB
+	mongoDB *mongo.Database
+}
+
+func NewUnitOfWork(db *gorm.DB, mongoDB *mongo.Database) UnitOfWork {
+	return &unitOfWork{db: db, mongoDB: mongoDB}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(repos TxRepositories) error) error {
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(TxRepositories{
+			Admin:       NewAdminRepository(tx),
+			Student:     NewStudentRepository(tx),
+			Lecturer:    NewLecturerRepository(tx),
+			Achievement: NewAchievementRepository(tx, u.mongoDB),
+			Point:       NewPointRepository(tx),
+			Outbox:      NewOutboxRepository(tx),
+			Role:        NewRoleRepository(tx),
+			Advisor:     NewAdvisorRepository(tx),
+		})
+	})
+}
@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"gouas/app/models"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// unitConditions memetakan jenis unit ke kondisi pada students.study_program_id
+var unitConditions = map[string]string{
+	models.UnitFaculty: "students.study_program_id IN (SELECT sp.id FROM study_programs sp " +
+		"JOIN departments d ON d.id = sp.department_id WHERE d.faculty_id IN ?)",
+	models.UnitDepartment:   "students.study_program_id IN (SELECT id FROM study_programs WHERE department_id IN ?)",
+	models.UnitProgramStudy: "students.study_program_id IN ?",
+}
+
+// applyUnitScope membatasi query (yang memuat tabel students) ke unit organisasi scope.
+// Beberapa scope digabung dengan OR; scope kosong tidak membatasi apa pun.
+func applyUnitScope(q *gorm.DB, scopes []models.UserScope) *gorm.DB {
+	if len(scopes) == 0 {
+		return q
+	}
+	ids := map[string][]uuid.UUID{}
+	for _, sc := range scopes {
+		if _, ok := unitConditions[sc.Unit]; ok {
+			ids[sc.Unit] = append(ids[sc.Unit], sc.UnitID)
+		}
+	}
+	if len(ids) == 0 {
+		// Semua scope tidak dikenal: jangan bocorkan data
+		return q.Where("1 = 0")
+	}
+
+	cond := q.Session(&gorm.Session{NewDB: true})
+	first := true
+	for _, unit := range []string{models.UnitFaculty, models.UnitDepartment, models.UnitProgramStudy} {
+		unitIDs, ok := ids[unit]
+		if !ok {
+			continue
+		}
+		if first {
+			cond = cond.Where(unitConditions[unit], unitIDs)
+			first = false
+		} else {
+			cond = cond.Or(unitConditions[unit], unitIDs)
+		}
+	}
+	return q.Where(cond)
+}
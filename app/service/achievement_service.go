@@ -1,7 +1,11 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
 	"gouas/app/models"
@@ -16,6 +20,7 @@ import (
 type AchievementService interface {
 	// Handler methods (Menerima Fiber Ctx)
 	GetAll(c *fiber.Ctx) error
+	Search(c *fiber.Ctx) error
 	GetDetail(c *fiber.Ctx) error
 	Create(c *fiber.Ctx) error
 	Update(c *fiber.Ctx) error
@@ -23,27 +28,42 @@ type AchievementService interface {
 	Submit(c *fiber.Ctx) error
 	Verify(c *fiber.Ctx) error
 	Reject(c *fiber.Ctx) error
+	Revoke(c *fiber.Ctx) error
 	GetHistory(c *fiber.Ctx) error
 	AddAttachment(c *fiber.Ctx) error
 
 	// Pure Business Logic (Murni Logic, untuk Unit Test)
-	CreateAchievement(data models.Achievement, studentID uuid.UUID) (*models.AchievementReference, error)
-	SubmitAchievement(id uuid.UUID, studentID uuid.UUID) error
-	VerifyAchievement(id uuid.UUID, verifierUserID uuid.UUID) error
-	RejectAchievement(id uuid.UUID, verifierUserID uuid.UUID, note string) error
+	CreateAchievement(ctx context.Context, data models.Achievement, studentID uuid.UUID) (*models.AchievementReference, error)
+	SubmitAchievement(ctx context.Context, id uuid.UUID, studentID uuid.UUID, actor models.StatusActor) error
+	VerifyAchievement(ctx context.Context, id uuid.UUID, verifier models.StatusActor) error
+	RejectAchievement(ctx context.Context, id uuid.UUID, verifier models.StatusActor, note string) error
+	UpdateAchievement(ctx context.Context, id uuid.UUID, studentID uuid.UUID, actor models.StatusActor, data models.Achievement) error
+	DeleteAchievement(ctx context.Context, id uuid.UUID, actor models.StatusActor) error
+	RevokeAchievement(ctx context.Context, id uuid.UUID, actor models.StatusActor, reason string) error
 }
 
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
 type achievementService struct {
 	repo         repository.AchievementRepository
 	studentRepo  repository.StudentRepository
 	lecturerRepo repository.LecturerRepository
+	ruleRepo     repository.PointRuleRepository
+	typeRepo     repository.AchievementTypeRepository
+	workflow     AchievementWorkflow
 }
 
-func NewAchievementService(repo repository.AchievementRepository, studentRepo repository.StudentRepository, lecturerRepo repository.LecturerRepository) AchievementService {
+func NewAchievementService(repo repository.AchievementRepository, studentRepo repository.StudentRepository, lecturerRepo repository.LecturerRepository, ruleRepo repository.PointRuleRepository, typeRepo repository.AchievementTypeRepository) AchievementService {
 	return &achievementService{
 		repo:         repo,
 		studentRepo:  studentRepo,
 		lecturerRepo: lecturerRepo,
+		ruleRepo:     ruleRepo,
+		typeRepo:     typeRepo,
+		workflow:     NewAchievementWorkflow(),
 	}
 }
 
@@ -51,104 +71,296 @@ func NewAchievementService(repo repository.AchievementRepository, studentRepo re
 // 1. PURE BUSINESS LOGIC (Untuk di-test di Unit Test)
 // =========================================================================
 
-func (s *achievementService) CreateAchievement(data models.Achievement, studentID uuid.UUID) (*models.AchievementReference, error) {
-	// Validasi Title & Type
-	if data.Title == "" || data.AchievementType == "" {
-		return nil, fmt.Errorf("title and type are required")
+func (s *achievementService) CreateAchievement(ctx context.Context, data models.Achievement, studentID uuid.UUID) (*models.AchievementReference, error) {
+	// Validasi field umum + field khusus per tipe
+	data.AchievementType = strings.ToLower(data.AchievementType)
+	if err := s.validate(ctx, data); err != nil {
+		return nil, err
+	}
+
+	return s.repo.Create(ctx, data, studentID)
+}
+
+func (s *achievementService) SubmitAchievement(ctx context.Context, id uuid.UUID, studentID uuid.UUID, actor models.StatusActor) error {
+	ref, err := s.repo.FindReferenceByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("achievement not found")
+	}
+	if ref.StudentID != studentID {
+		return fmt.Errorf("unauthorized: you don't own this")
+	}
+
+	t, err := s.workflow.Resolve(ActionSubmit, ref.Status, actor.Permissions)
+	if err != nil {
+		return err
+	}
+
+	student, err := s.studentRepo.FindByID(ctx, ref.StudentID)
+	if err != nil {
+		return fmt.Errorf("student profile not found")
+	}
+	return s.applyTransition(ctx, t, ref, actor, transitionInput{Student: student})
+}
+
+func (s *achievementService) VerifyAchievement(ctx context.Context, id uuid.UUID, verifier models.StatusActor) error {
+	// 1. Ambil Reference dari Postgres & cek transisi
+	ref, err := s.repo.FindReferenceByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("achievement not found")
+	}
+	t, err := s.workflow.Resolve(ActionVerify, ref.Status, verifier.Permissions)
+	if err != nil {
+		return err
+	}
+
+	// 2. Validasi Dosen Wali
+	student, err := s.ensureAdvisor(ctx, ref, verifier)
+	if err != nil {
+		return err
+	}
+
+	// 3. Ambil Detail dari MongoDB untuk dicocokkan dengan rule
+	mongoDetail, errM := s.repo.GetMongoDetail(ctx, ref.MongoAchievementID)
+	if errM != nil {
+		return fmt.Errorf("could not fetch achievement details from mongo")
 	}
 
-	// [BARU] Validasi Points Wajib diisi dan harus positif
-	// Karena tipe int, jika tidak dikirim akan bernilai 0.
-	if data.Points <= 0 {
-		return nil, fmt.Errorf("points field is required and must be a positive number (minimum 1)")
+	// 4. Tentukan Poin berdasarkan Point Rules yang aktif
+	rules, err := s.ruleRepo.FindActive(ctx)
+	if err != nil {
+		return fmt.Errorf("could not load point rules")
 	}
+	pointAwarded, _ := ResolvePoints(rules, *mongoDetail, time.Now())
 
-	return s.repo.Create(data, studentID)
+	// 5. Update Status + efek samping (poin & notifikasi)
+	return s.applyTransition(ctx, t, ref, verifier, transitionInput{Student: student, Points: pointAwarded})
 }
 
-func (s *achievementService) SubmitAchievement(id uuid.UUID, studentID uuid.UUID) error {
-	ref, err := s.repo.FindReferenceByID(id)
+func (s *achievementService) RejectAchievement(ctx context.Context, id uuid.UUID, verifier models.StatusActor, note string) error {
+	ref, err := s.repo.FindReferenceByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("achievement not found")
+	}
+	t, err := s.workflow.Resolve(ActionReject, ref.Status, verifier.Permissions)
+	if err != nil {
+		return err
+	}
+
+	student, err := s.ensureAdvisor(ctx, ref, verifier)
+	if err != nil {
+		return err
+	}
+
+	return s.applyTransition(ctx, t, ref, verifier, transitionInput{Student: student, Note: note})
+}
+
+func (s *achievementService) RevokeAchievement(ctx context.Context, id uuid.UUID, actor models.StatusActor, reason string) error {
+	ref, err := s.repo.FindReferenceByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("achievement not found")
+	}
+	t, err := s.workflow.Resolve(ActionRevoke, ref.Status, actor.Permissions)
+	if err != nil {
+		return err
+	}
+
+	// achievement:manage hanya melewati cek dosen wali untuk mahasiswa di unit scope-nya
+	var student *models.Student
+	if actor.Can(models.PermAchievementManage) && s.inScope(ctx, actor, ref.StudentID) {
+		student, err = s.studentRepo.FindByID(ctx, ref.StudentID)
+	} else {
+		student, err = s.ensureAdvisor(ctx, ref, actor)
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.applyTransition(ctx, t, ref, actor, transitionInput{Student: student, Note: reason})
+}
+
+func (s *achievementService) UpdateAchievement(ctx context.Context, id uuid.UUID, studentID uuid.UUID, actor models.StatusActor, data models.Achievement) error {
+	ref, err := s.repo.FindReferenceByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("achievement not found")
 	}
 	if ref.StudentID != studentID {
 		return fmt.Errorf("unauthorized: you don't own this")
 	}
-	if ref.Status != models.StatusDraft && ref.Status != models.StatusRejected {
-		return fmt.Errorf("only draft or rejected can be submitted")
+	if _, err := s.workflow.Resolve(ActionUpdate, ref.Status, actor.Permissions); err != nil {
+		return fmt.Errorf("cannot update: current status is %s", ref.Status)
+	}
+
+	data.AchievementType = strings.ToLower(data.AchievementType)
+	if err := s.validate(ctx, data); err != nil {
+		return err
 	}
-	return s.repo.UpdateStatus(id, models.StatusSubmitted)
+	return s.repo.UpdateMongo(ctx, ref.MongoAchievementID, data)
 }
 
-func (s *achievementService) VerifyAchievement(id uuid.UUID, verifierUserID uuid.UUID) error {
-	// 1. Ambil Reference dari Postgres
-	ref, err := s.repo.FindReferenceByID(id)
-	if err != nil || ref.Status != models.StatusSubmitted {
-		return fmt.Errorf("invalid achievement or status")
+func (s *achievementService) DeleteAchievement(ctx context.Context, id uuid.UUID, actor models.StatusActor) error {
+	ref, err := s.repo.FindReferenceByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("achievement not found")
+	}
+	t, err := s.workflow.Resolve(ActionDelete, ref.Status, actor.Permissions)
+	if err != nil {
+		return err
 	}
 
-	// 2. Validasi Dosen Wali (Logic tetap sama)
-	student, _ := s.studentRepo.FindByID(ref.StudentID)
-	lecturer, errL := s.lecturerRepo.FindByUserID(verifierUserID)
+	// Tanpa achievement:manage (atau di luar unit scope-nya) hanya boleh menghapus miliknya sendiri
+	if !actor.Can(models.PermAchievementManage) || !s.inScope(ctx, actor, ref.StudentID) {
+		student, err := s.studentRepo.FindByUserID(ctx, actor.UserID)
+		if err != nil || ref.StudentID != student.ID {
+			return ErrForbiddenTransition
+		}
+	}
+	return s.applyTransition(ctx, t, ref, actor, transitionInput{})
+}
+
+// validate memakai aturan bawaan, atau JSON Schema jika tipe adalah custom type terdaftar
+func (s *achievementService) validate(ctx context.Context, data models.Achievement) error {
+	if data.AchievementType != "" && !IsBuiltinAchievementType(data.AchievementType) {
+		if t, err := s.typeRepo.FindByName(ctx, data.AchievementType); err == nil {
+			return ValidateCustomAchievement(data, *t)
+		}
+	}
+	return ValidateAchievement(data)
+}
+
+// transitionInput membawa data tambahan yang dibutuhkan saat transisi & efek samping
+type transitionInput struct {
+	Student *models.Student
+	Note    string
+	Points  int
+}
+
+// ensureAdvisor memastikan verifier adalah Dosen Wali dari pemilik prestasi
+func (s *achievementService) ensureAdvisor(ctx context.Context, ref *models.AchievementReference, verifier models.StatusActor) (*models.Student, error) {
+	student, err := s.studentRepo.FindByID(ctx, ref.StudentID)
+	if err != nil {
+		return nil, fmt.Errorf("student profile not found")
+	}
+	// Approver tingkat fakultas (student:read_all) tidak harus menjadi dosen wali,
+	// selama mahasiswa berada di unit scope-nya
+	if verifier.Can(models.PermStudentReadAll) && s.inScope(ctx, verifier, student.ID) {
+		return student, nil
+	}
+	lecturer, errL := s.lecturerRepo.FindByUserID(ctx, verifier.UserID)
 	if errL != nil {
-		return fmt.Errorf("lecturer profile not found")
+		return nil, fmt.Errorf("lecturer profile not found")
 	}
 	if student.AdvisorID == nil || *student.AdvisorID != lecturer.ID {
-		return fmt.Errorf("forbidden: you are not the advisor for this student")
+		return nil, fmt.Errorf("forbidden: you are not the advisor for this student")
 	}
+	return student, nil
+}
 
-	// 3. [BARU] Ambil Detail dari MongoDB untuk Cek Level
-	mongoDetail, errM := s.repo.GetMongoDetail(ref.MongoAchievementID)
-	if errM != nil {
-		return fmt.Errorf("could not fetch achievement details from mongo")
+// inScope: actor tanpa scope bersifat global; selain itu mahasiswa harus berada di
+// unit scope-nya (sama dengan canAccessStudent)
+func (s *achievementService) inScope(ctx context.Context, actor models.StatusActor, studentID uuid.UUID) bool {
+	if len(actor.Scopes) == 0 {
+		return true
 	}
+	ok, err := s.studentRepo.InUnits(ctx, studentID, actor.Scopes)
+	return err == nil && ok
+}
 
-	// 4. [BARU] Tentukan Poin berdasarkan CompetitionLevel
-	var pointAwarded int
-	switch mongoDetail.Details.CompetitionLevel {
-	case "International":
-		pointAwarded = 50
-	case "National":
-		pointAwarded = 30
-	case "Provincial":
-		pointAwarded = 20
-	case "Campus":
-		pointAwarded = 10
-	default:
-		pointAwarded = 5 // Poin dasar jika level tidak diisi/lainnya
+// applyTransition menyimpan status baru lalu menjalankan efek samping transisi
+func (s *achievementService) applyTransition(ctx context.Context, t *Transition, ref *models.AchievementReference, actor models.StatusActor, in transitionInput) error {
+	if t.RequireNote && in.Note == "" {
+		return ErrNoteRequired
 	}
 
-	// 5. Update Status di Postgres
-	if err := s.repo.Verify(id, verifierUserID); err != nil {
+	outbox, err := s.outboxEvents(t, ref, actor, in)
+	if err != nil {
 		return err
 	}
-	
-	// 6. [BARU] Tambah Poin sesuai Level yang sudah dihitung
-	return s.studentRepo.AddPoints(ref.StudentID, pointAwarded)
-}
 
-func (s *achievementService) RejectAchievement(id uuid.UUID, verifierUserID uuid.UUID, note string) error {
-	if note == "" {
-		return fmt.Errorf("rejection note is required")
+	switch t.To {
+	case "":
+		// Transisi tanpa perubahan status
+	case models.StatusVerified:
+		err = s.repo.Verify(ctx, ref.ID, t.From, actor, in.Points, outbox)
+	case models.StatusRejected:
+		err = s.repo.Reject(ctx, ref.ID, t.From, in.Note, actor, outbox)
+	case models.StatusDeleted:
+		err = s.repo.SoftDelete(ctx, ref.ID, t.From, actor, outbox)
+	case models.StatusRevoked:
+		err = s.repo.Revoke(ctx, ref.ID, t.From, in.Note, actor, outbox)
+	default:
+		err = s.repo.UpdateStatus(ctx, ref.ID, t.From, t.To, actor, outbox)
 	}
+	return err
+}
 
-	ref, err := s.repo.FindReferenceByID(id)
-	if err != nil || ref.Status != models.StatusSubmitted {
-		return fmt.Errorf("invalid achievement or status")
+// outboxEvents menerjemahkan efek samping transisi menjadi event outbox.
+// Event disimpan bersama perubahan status dan dijalankan oleh OutboxDispatcher.
+func (s *achievementService) outboxEvents(t *Transition, ref *models.AchievementReference, actor models.StatusActor, in transitionInput) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	add := func(kind string, payload interface{}) error {
+		event, err := models.NewOutboxEvent(kind, ref.ID, payload)
+		if err != nil {
+			return err
+		}
+		events = append(events, event)
+		return nil
+	}
+
+	for _, effect := range t.Effects {
+		var err error
+		switch effect {
+		case EffectAwardPoints:
+			// Ledger ada di database yang sama: poin ditulis repo.Verify di transaksi status, bukan lewat outbox
+		case EffectSyncMongo:
+			points := in.Points
+			if t.To != models.StatusVerified {
+				points = 0
+			}
+			err = add(models.OutboxSyncMongo, models.SyncMongoPayload{
+				MongoID:       ref.MongoAchievementID,
+				Status:        t.To,
+				PointsAwarded: points,
+			})
+		case EffectNotifyAdvisor:
+			if in.Student != nil && in.Student.Advisor != nil {
+				err = add(models.OutboxNotify, notification(in.Student.Advisor.UserID, t, ref))
+			}
+		case EffectNotifyStudent:
+			if in.Student != nil {
+				err = add(models.OutboxNotify, notification(in.Student.UserID, t, ref))
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
 	}
+	return events, nil
+}
 
-	// Validasi Dosen Wali
-	student, _ := s.studentRepo.FindByID(ref.StudentID)
-	lecturer, errL := s.lecturerRepo.FindByUserID(verifierUserID)
-	if errL != nil {
-		return fmt.Errorf("lecturer profile not found")
+func notification(userID uuid.UUID, t *Transition, ref *models.AchievementReference) models.NotifyPayload {
+	return models.NotifyPayload{
+		UserID:  userID,
+		Subject: fmt.Sprintf("Achievement %s", t.To),
+		Message: fmt.Sprintf("Achievement %s is now %s", ref.ID, t.To),
 	}
+}
 
-	if student.AdvisorID == nil || *student.AdvisorID != lecturer.ID {
-		return fmt.Errorf("forbidden: you are not the advisor")
+// statusActor membentuk actor workflow dari user yang sedang login
+func statusActor(authData *middleware.AuthResult) models.StatusActor {
+	return models.StatusActor{
+		UserID:      uuid.MustParse(authData.UserID),
+		Role:        authData.Role,
+		Permissions: authData.Permissions,
+		Scopes:      authData.Scopes,
 	}
+}
 
-	return s.repo.Reject(id, note)
+// transitionStatusCode memetakan error workflow ke HTTP status
+func transitionStatusCode(err error) int {
+	if errors.Is(err, ErrForbiddenTransition) {
+		return 403
+	}
+	return 400
 }
 
 // =========================================================================
@@ -156,64 +368,112 @@ func (s *achievementService) RejectAchievement(id uuid.UUID, verifierUserID uuid
 // =========================================================================
 
 func (s *achievementService) GetAll(c *fiber.Ctx) error {
-	authData, _ := middleware.CheckAuth(c.Get("Authorization"))
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
 	userID, _ := uuid.Parse(authData.UserID)
 
-	var data []models.AchievementReference
-	var err error
+	filter, err := parseAchievementFilter(c)
+	if err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
 
-	switch authData.Role {
-	case "Mahasiswa":
-		student, _ := s.studentRepo.FindByUserID(userID)
-		data, err = s.repo.FindReferencesByStudentID(student.ID)
-	case "Dosen Wali":
-		lecturer, _ := s.lecturerRepo.FindByUserID(userID)
-		advisees, _ := s.lecturerRepo.FindAdvisees(lecturer.ID)
-		var studentIDs []uuid.UUID
-		for _, st := range advisees {
-			studentIDs = append(studentIDs, st.ID)
+	// Scope data sesuai permission, menimpa filter dari query
+	switch scopeOf(authData) {
+	case scopeOwn:
+		filter.StudentIDs = []uuid.UUID{}
+		if student, err := s.studentRepo.FindByUserID(ctx, userID); err == nil {
+			filter.StudentIDs = []uuid.UUID{student.ID}
 		}
-		data, err = s.repo.FindReferencesByStudentIDs(studentIDs)
-	default:
-		data, err = s.repo.FindAllReferences()
+	case scopeAdvisees:
+		lecturer, err := s.lecturerRepo.FindByUserID(ctx, userID)
+		if err != nil {
+			return c.Status(404).JSON(helper.APIResponse("error", "Lecturer profile not found", nil))
+		}
+		filter.AdvisorID = &lecturer.ID
+	case scopeUnits:
+		filter.Units = authData.Scopes
 	}
 
+	data, total, err := s.repo.FindReferences(ctx, filter)
 	if err != nil {
 		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
-	return c.Status(200).JSON(helper.APIResponse("success", "Achievement list retrieved", data))
+	meta := helper.NewPaginationMeta(filter.Page, filter.Limit, total)
+	return c.Status(200).JSON(helper.APIResponseWithMeta("success", "Achievement list retrieved", data, meta))
 }
 
-func (s *achievementService) GetDetail(c *fiber.Ctx) error {
-	authData, _ := middleware.CheckAuth(c.Get("Authorization"))
-	id, _ := uuid.Parse(c.Params("id"))
+// Search mencari prestasi (full-text Mongo) dengan scope yang sama seperti GetAll
+func (s *achievementService) Search(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
+
+	query := strings.TrimSpace(c.Query("q"))
+	if len([]rune(query)) < 2 {
+		return c.Status(400).JSON(helper.APIResponse("error", "q must be at least 2 characters", nil))
+	}
+	limit := c.QueryInt("limit", defaultPageLimit)
+	if limit < 1 || limit > maxPageLimit {
+		return c.Status(400).JSON(helper.APIResponse("error", fmt.Sprintf("limit must be between 1 and %d", maxPageLimit), nil))
+	}
+
+	studentIDs := visibleStudentIDs(ctx, s.studentRepo, s.lecturerRepo, authData)
 
-	ref, err := s.repo.FindReferenceByID(id)
+	hits, err := s.repo.SearchMongo(ctx, query, studentIDs, limit)
 	if err != nil {
-		return c.Status(404).JSON(helper.APIResponse("error", "Achievement not found", nil))
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
 
-	allowed := false
-	switch authData.Role {
-	case "Admin":
-		allowed = true
-	case "Mahasiswa":
-		student, _ := s.studentRepo.FindByUserID(uuid.MustParse(authData.UserID))
-		if ref.StudentID == student.ID {
-			allowed = true
-		}
-	case "Dosen Wali":
-		lecturer, _ := s.lecturerRepo.FindByUserID(uuid.MustParse(authData.UserID))
-		if ref.Student.AdvisorID != nil && *ref.Student.AdvisorID == lecturer.ID {
-			allowed = true
+	mongoIDs := make([]string, 0, len(hits))
+	for _, h := range hits {
+		mongoIDs = append(mongoIDs, h.Achievement.ID.Hex())
+	}
+	refs, err := s.repo.FindReferencesByMongoIDs(ctx, mongoIDs)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	refByMongoID := make(map[string]models.AchievementReference, len(refs))
+	for _, ref := range refs {
+		refByMongoID[ref.MongoAchievementID] = ref
+	}
+
+	// Urutan mengikuti skor Mongo; dokumen tanpa reference aktif dilewati
+	results := []fiber.Map{}
+	for _, h := range hits {
+		ref, ok := refByMongoID[h.Achievement.ID.Hex()]
+		if !ok {
+			continue
 		}
+		results = append(results, fiber.Map{
+			"reference": ref,
+			"details":   h.Achievement,
+			"score":     h.Score,
+		})
 	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Search results", results))
+}
 
-	if !allowed {
+func (s *achievementService) GetDetail(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
+	id, _ := uuid.Parse(c.Params("id"))
+
+	ref, err := s.repo.FindReferenceByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Achievement not found", nil))
+	}
+
+	if !s.canAccess(ctx, authData, ref) {
 		return c.Status(403).JSON(helper.APIResponse("error", "Forbidden access", nil))
 	}
 
-	mongoData, _ := s.repo.GetMongoDetail(ref.MongoAchievementID)
+	mongoData, err := s.repo.GetMongoDetail(ctx, ref.MongoAchievementID)
+	if errors.Is(err, repository.ErrMongoDocumentMissing) {
+		log.Printf("[ACHIEVEMENT] reference %s points at missing mongo document %s", ref.ID, ref.MongoAchievementID)
+		return c.Status(500).JSON(helper.APIResponse("error", "Achievement details are missing, please contact an administrator", nil))
+	}
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
 	return c.Status(200).JSON(helper.APIResponse("success", "Achievement detail", fiber.Map{
 		"reference": ref,
 		"details":   mongoData,
@@ -221,12 +481,10 @@ func (s *achievementService) GetDetail(c *fiber.Ctx) error {
 }
 
 func (s *achievementService) Create(c *fiber.Ctx) error {
-	authData, _ := middleware.CheckAuth(c.Get("Authorization"))
-	if authData.Role != "Mahasiswa" {
-		return c.Status(403).JSON(helper.APIResponse("error", "Only students can create achievements", nil))
-	}
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
 
-	student, err := s.studentRepo.FindByUserID(uuid.MustParse(authData.UserID))
+	student, err := s.studentRepo.FindByUserID(ctx, uuid.MustParse(authData.UserID))
 	if err != nil {
 		return c.Status(404).JSON(helper.APIResponse("error", "Student profile not found", nil))
 	}
@@ -240,129 +498,153 @@ func (s *achievementService) Create(c *fiber.Ctx) error {
 		return c.Status(400).JSON(helper.APIResponse("error", "Invalid JSON input", nil))
 	}
 
-	result, err := s.CreateAchievement(input, student.ID)
+	result, err := s.CreateAchievement(ctx, input, student.ID)
 	if err != nil {
-		// [BARU] Memberikan respon spesifik untuk membenarkan input
-		return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: "+err.Error(), nil))
+		// Memberikan daftar error per field untuk membenarkan input
+		var vErr *ValidationError
+		if errors.As(err, &vErr) {
+			return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed", vErr.Fields))
+		}
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
 
 	return c.Status(201).JSON(helper.APIResponse("success", "Achievement created successfully", result))
 }
 
 func (s *achievementService) Update(c *fiber.Ctx) error {
+	ctx := c.UserContext()
 	id, _ := uuid.Parse(c.Params("id"))
-	authData, _ := middleware.CheckAuth(c.Get("Authorization"))
-
-	ref, err := s.repo.FindReferenceByID(id)
-	if err != nil {
-		return c.Status(404).JSON(helper.APIResponse("error", "Not found", nil))
-	}
-
-	student, _ := s.studentRepo.FindByUserID(uuid.MustParse(authData.UserID))
-	if ref.StudentID != student.ID {
-		return c.Status(403).JSON(helper.APIResponse("error", "Unauthorized", nil))
-	}
-
-	if ref.Status != models.StatusDraft && ref.Status != models.StatusRejected {
-		return c.Status(400).JSON(helper.APIResponse("error", "Cannot update: Current status is "+string(ref.Status), nil))
-	}
+	authData := middleware.CurrentAuth(c)
+	userID := uuid.MustParse(authData.UserID)
 
 	var input models.Achievement
 	c.BodyParser(&input)
-	if err := s.repo.UpdateMongo(ref.MongoAchievementID, input); err != nil {
-		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+
+	student, _ := s.studentRepo.FindByUserID(ctx, userID)
+	actor := statusActor(authData)
+	if err := s.UpdateAchievement(ctx, id, student.ID, actor, input); err != nil {
+		var vErr *ValidationError
+		if errors.As(err, &vErr) {
+			return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed", vErr.Fields))
+		}
+		return c.Status(transitionStatusCode(err)).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
 
 	return c.Status(200).JSON(helper.APIResponse("success", "Achievement updated", nil))
 }
 
 func (s *achievementService) Delete(c *fiber.Ctx) error {
+	ctx := c.UserContext()
 	id, _ := uuid.Parse(c.Params("id"))
-	authData, _ := middleware.CheckAuth(c.Get("Authorization"))
+	authData := middleware.CurrentAuth(c)
 
-	ref, err := s.repo.FindReferenceByID(id)
-	if err != nil {
-		return c.Status(404).JSON(helper.APIResponse("error", "Not found", nil))
+	actor := statusActor(authData)
+	if err := s.DeleteAchievement(ctx, id, actor); err != nil {
+		return c.Status(transitionStatusCode(err)).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
-
-	if authData.Role == "Mahasiswa" {
-		student, _ := s.studentRepo.FindByUserID(uuid.MustParse(authData.UserID))
-		if ref.StudentID != student.ID || ref.Status != models.StatusDraft {
-			return c.Status(403).JSON(helper.APIResponse("error", "Cannot delete", nil))
-		}
-	}
-
-	s.repo.SoftDelete(id)
 	return c.Status(200).JSON(helper.APIResponse("success", "Achievement deleted", nil))
 }
 
 func (s *achievementService) Submit(c *fiber.Ctx) error {
-	authData, _ := middleware.CheckAuth(c.Get("Authorization"))
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
 	userID, _ := uuid.Parse(authData.UserID)
 	id, _ := uuid.Parse(c.Params("id"))
 
-	student, _ := s.studentRepo.FindByUserID(userID)
-	
-	if err := s.SubmitAchievement(id, student.ID); err != nil {
-		return c.Status(400).JSON(helper.APIResponse("error", err.Error(), nil))
+	student, _ := s.studentRepo.FindByUserID(ctx, userID)
+	actor := statusActor(authData)
+
+	if err := s.SubmitAchievement(ctx, id, student.ID, actor); err != nil {
+		return c.Status(transitionStatusCode(err)).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
 	
 	return c.Status(200).JSON(helper.APIResponse("success", "Achievement submitted for verification", nil))
 }
 
 func (s *achievementService) Verify(c *fiber.Ctx) error {
-	authData, _ := middleware.CheckAuth(c.Get("Authorization"))
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
 	id, _ := uuid.Parse(c.Params("id"))
-	verifierUserID := uuid.MustParse(authData.UserID)
+	verifier := statusActor(authData)
 
-	if err := s.VerifyAchievement(id, verifierUserID); err != nil {
-		return c.Status(400).JSON(helper.APIResponse("error", err.Error(), nil))
+	if err := s.VerifyAchievement(ctx, id, verifier); err != nil {
+		return c.Status(transitionStatusCode(err)).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
 
 	return c.Status(200).JSON(helper.APIResponse("success", "Achievement verified", nil))
 }
 
 func (s *achievementService) Reject(c *fiber.Ctx) error {
-	authData, _ := middleware.CheckAuth(c.Get("Authorization"))
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
 	id, _ := uuid.Parse(c.Params("id"))
-	verifierUserID := uuid.MustParse(authData.UserID)
+	verifier := statusActor(authData)
 
 	var input struct {
 		Note string `json:"note"`
 	}
 	c.BodyParser(&input)
 
-	if err := s.RejectAchievement(id, verifierUserID, input.Note); err != nil {
-		return c.Status(400).JSON(helper.APIResponse("error", err.Error(), nil))
+	if err := s.RejectAchievement(ctx, id, verifier, input.Note); err != nil {
+		return c.Status(transitionStatusCode(err)).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
 
 	return c.Status(200).JSON(helper.APIResponse("success", "Achievement rejected", nil))
 }
 
+func (s *achievementService) Revoke(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
+	id, _ := uuid.Parse(c.Params("id"))
+	actor := statusActor(authData)
+
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	c.BodyParser(&input)
+
+	if err := s.RevokeAchievement(ctx, id, actor, input.Reason); err != nil {
+		return c.Status(transitionStatusCode(err)).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+
+	return c.Status(200).JSON(helper.APIResponse("success", "Achievement revoked", nil))
+}
+
 func (s *achievementService) GetHistory(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
 	id, _ := uuid.Parse(c.Params("id"))
-	ref, err := s.repo.FindReferenceByID(id)
+	ref, err := s.repo.FindReferenceByID(ctx, id)
 	if err != nil {
 		return c.Status(404).JSON(helper.APIResponse("error", "Not found", nil))
 	}
+	if !s.canAccess(ctx, authData, ref) {
+		return c.Status(403).JSON(helper.APIResponse("error", "Forbidden access", nil))
+	}
+
+	events, err := s.repo.FindStatusEvents(ctx, id)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+
 	return c.Status(200).JSON(helper.APIResponse("success", "History retrieved", fiber.Map{
 		"current_status": ref.Status,
-		"submitted_at":   ref.SubmittedAt,
-		"verified_at":    ref.VerifiedAt,
-		"rejected_note":  ref.RejectionNote,
+		"created_at":     ref.CreatedAt,
+		"timeline":       events,
 	}))
 }
 
 func (s *achievementService) AddAttachment(c *fiber.Ctx) error {
-	authData, _ := middleware.CheckAuth(c.Get("Authorization"))
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
 	id, _ := uuid.Parse(c.Params("id"))
 	file, err := c.FormFile("file")
 	if err != nil {
 		return c.Status(400).JSON(helper.APIResponse("error", "File is required", nil))
 	}
 
-	ref, _ := s.repo.FindReferenceByID(id)
-	student, _ := s.studentRepo.FindByUserID(uuid.MustParse(authData.UserID))
+	ref, _ := s.repo.FindReferenceByID(ctx, id)
+	student, _ := s.studentRepo.FindByUserID(ctx, uuid.MustParse(authData.UserID))
 	if ref.StudentID != student.ID {
 		return c.Status(403).JSON(helper.APIResponse("error", "Unauthorized", nil))
 	}
@@ -380,6 +662,96 @@ func (s *achievementService) AddAttachment(c *fiber.Ctx) error {
 		UploadedAt: time.Now(),
 	}
 
-	s.repo.AddAttachment(ref.MongoAchievementID, attachment)
+	s.repo.AddAttachment(ctx, ref.MongoAchievementID, attachment)
 	return c.Status(200).JSON(helper.APIResponse("success", "File uploaded", attachment))
-}
\ No newline at end of file
+}
+
+// canAccess: prestasi milik mahasiswa yang boleh diakses (lihat canAccessStudent)
+func (s *achievementService) canAccess(ctx context.Context, authData *middleware.AuthResult, ref *models.AchievementReference) bool {
+	return canAccessStudent(ctx, s.studentRepo, s.lecturerRepo, authData, &ref.Student)
+}
+
+// parseAchievementFilter membaca query string:
+// status (dipisah koma), type, from, to (YYYY-MM-DD / RFC3339), studentId, studyProgramId,
+// academicYear, advisorId, sort (prefix "-" untuk descending), page, limit
+func parseAchievementFilter(c *fiber.Ctx) (repository.AchievementFilter, error) {
+	f := repository.AchievementFilter{
+		AchievementType: strings.ToLower(c.Query("type")),
+		AcademicYear:    c.Query("academicYear"),
+		SortBy:          "created_at",
+		SortDesc:        true,
+		Page:            c.QueryInt("page", 1),
+		Limit:           c.QueryInt("limit", defaultPageLimit),
+	}
+
+	if f.Page < 1 {
+		f.Page = 1
+	}
+	if f.Limit < 1 || f.Limit > maxPageLimit {
+		return f, fmt.Errorf("limit must be between 1 and %d", maxPageLimit)
+	}
+
+	if status := c.Query("status"); status != "" {
+		for _, st := range strings.Split(status, ",") {
+			f.Statuses = append(f.Statuses, models.AchievementStatus(strings.TrimSpace(st)))
+		}
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		f.SortDesc = strings.HasPrefix(sort, "-")
+		f.SortBy = strings.TrimPrefix(sort, "-")
+		if _, ok := repository.AchievementSortColumns[f.SortBy]; !ok {
+			return f, fmt.Errorf("invalid sort field: %s", f.SortBy)
+		}
+	}
+
+	var err error
+	if f.CreatedFrom, err = parseDateQuery(c.Query("from"), false); err != nil {
+		return f, fmt.Errorf("invalid from date")
+	}
+	if f.CreatedTo, err = parseDateQuery(c.Query("to"), true); err != nil {
+		return f, fmt.Errorf("invalid to date")
+	}
+
+	if v := c.Query("studentId"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid studentId")
+		}
+		f.StudentIDs = []uuid.UUID{id}
+	}
+	if v := c.Query("advisorId"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid advisorId")
+		}
+		f.AdvisorID = &id
+	}
+	if v := c.Query("studyProgramId"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid studyProgramId")
+		}
+		f.StudyProgramID = &id
+	}
+
+	return f, nil
+}
+
+// parseDateQuery menerima YYYY-MM-DD atau RFC3339. endOfDay dipakai untuk batas "to".
+func parseDateQuery(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &t, nil
+}
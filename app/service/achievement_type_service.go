@@ -0,0 +1,164 @@
+package service
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"gouas/app/models"
+	"gouas/app/repository"
+	"gouas/helper"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var typeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,49}$`)
+
+type AchievementTypeService interface {
+	GetAll(c *fiber.Ctx) error
+	GetDetail(c *fiber.Ctx) error
+	Create(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	Delete(c *fiber.Ctx) error
+}
+
+type achievementTypeService struct {
+	repo repository.AchievementTypeRepository
+}
+
+func NewAchievementTypeService(repo repository.AchievementTypeRepository) AchievementTypeService {
+	return &achievementTypeService{repo}
+}
+
+func (s *achievementTypeService) GetAll(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	custom, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+
+	// Mahasiswa hanya perlu tipe yang masih aktif
+	active := c.Query("includeInactive") != "true"
+	list := []fiber.Map{}
+	for _, name := range BuiltinAchievementTypes {
+		list = append(list, fiber.Map{"name": name, "builtin": true})
+	}
+	for _, t := range custom {
+		if active && !t.IsActive {
+			continue
+		}
+		list = append(list, fiber.Map{
+			"name":        t.Name,
+			"label":       t.Label,
+			"description": t.Description,
+			"schema":      t.Schema,
+			"isActive":    t.IsActive,
+			"builtin":     false,
+		})
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Achievement types retrieved", list))
+}
+
+func (s *achievementTypeService) GetDetail(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	t, err := s.repo.FindByName(ctx, c.Params("name"))
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Achievement type not found", nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Achievement type detail", t))
+}
+
+func (s *achievementTypeService) Create(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	var input struct {
+		Name        string          `json:"name"`
+		Label       string          `json:"label"`
+		Description string          `json:"description"`
+		Schema      json.RawMessage `json:"schema"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+
+	input.Name = strings.ToLower(strings.TrimSpace(input.Name))
+	if !typeNamePattern.MatchString(input.Name) {
+		return c.Status(400).JSON(helper.APIResponse("error", "name must be 2-50 chars of lowercase letters, digits, '-' or '_'", nil))
+	}
+	if IsBuiltinAchievementType(input.Name) {
+		return c.Status(409).JSON(helper.APIResponse("error", "name conflicts with a built-in achievement type", nil))
+	}
+	if input.Label == "" {
+		input.Label = input.Name
+	}
+	if _, err := helper.ParseJSONSchema(input.Schema); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid schema: "+err.Error(), nil))
+	}
+	if _, err := s.repo.FindByName(ctx, input.Name); err == nil {
+		return c.Status(409).JSON(helper.APIResponse("error", "Achievement type already exists", nil))
+	}
+
+	created, err := s.repo.Create(ctx, models.AchievementType{
+		Name:        input.Name,
+		Label:       input.Label,
+		Description: input.Description,
+		Schema:      input.Schema,
+		IsActive:    true,
+	})
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(201).JSON(helper.APIResponse("success", "Achievement type created", created))
+}
+
+func (s *achievementTypeService) Update(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	t, err := s.repo.FindByName(ctx, c.Params("name"))
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Achievement type not found", nil))
+	}
+
+	var input struct {
+		Label       *string         `json:"label"`
+		Description *string         `json:"description"`
+		Schema      json.RawMessage `json:"schema"`
+		IsActive    *bool           `json:"isActive"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+
+	if input.Label != nil {
+		t.Label = *input.Label
+	}
+	if input.Description != nil {
+		t.Description = *input.Description
+	}
+	if len(input.Schema) > 0 {
+		if _, err := helper.ParseJSONSchema(input.Schema); err != nil {
+			return c.Status(400).JSON(helper.APIResponse("error", "Invalid schema: "+err.Error(), nil))
+		}
+		t.Schema = input.Schema
+	}
+	if input.IsActive != nil {
+		t.IsActive = *input.IsActive
+	}
+
+	if err := s.repo.Update(ctx, *t); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Achievement type updated", t))
+}
+
+// Delete hanya menonaktifkan tipe, prestasi lama dengan tipe ini tetap bisa dibaca
+func (s *achievementTypeService) Delete(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	t, err := s.repo.FindByName(ctx, c.Params("name"))
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Achievement type not found", nil))
+	}
+	t.IsActive = false
+	if err := s.repo.Update(ctx, *t); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Achievement type deactivated", nil))
+}
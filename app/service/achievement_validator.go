@@ -0,0 +1,203 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gouas/app/models"
+	"gouas/helper"
+)
+
+const (
+	TypeAcademic      = "academic"
+	TypeCompetition   = "competition"
+	TypeOrganization  = "organization"
+	TypePublication   = "publication"
+	TypeCertification = "certification"
+	TypeOther         = "other"
+)
+
+var (
+	BuiltinAchievementTypes = []string{TypeAcademic, TypeCompetition, TypeOrganization, TypePublication, TypeCertification, TypeOther}
+	CompetitionLevels       = []string{"International", "National", "Provincial", "Campus"}
+	MedalTypes              = []string{"Gold", "Silver", "Bronze"}
+	PublicationTypes        = []string{"journal", "conference", "book"}
+
+	issnPattern = regexp.MustCompile(`^\d{4}-?\d{3}[\dXx]$`)
+)
+
+// FieldError adalah satu kesalahan validasi pada field tertentu
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError mengumpulkan semua FieldError agar bisa dikembalikan sekaligus
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Field+": "+f.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) add(field, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// ValidateAchievement memvalidasi field umum dan field khusus per AchievementType bawaan
+func ValidateAchievement(a models.Achievement) error {
+	v := &ValidationError{}
+	validateCommon(v, a)
+
+	d := a.Details
+	switch strings.ToLower(a.AchievementType) {
+	case "":
+		v.add("achievementType", "achievementType is required")
+	case TypeCompetition:
+		requireField(v, "details.competitionName", d.CompetitionName)
+		requireField(v, "details.competitionLevel", d.CompetitionLevel)
+		checkEnum(v, "details.competitionLevel", d.CompetitionLevel, CompetitionLevels)
+		checkEnum(v, "details.medalType", d.MedalType, MedalTypes)
+		if d.Rank < 0 {
+			v.add("details.rank", "rank must not be negative")
+		}
+	case TypePublication:
+		requireField(v, "details.publicationTitle", d.PublicationTitle)
+		requireField(v, "details.publicationType", d.PublicationType)
+		checkEnum(v, "details.publicationType", d.PublicationType, PublicationTypes)
+		if len(d.Authors) == 0 {
+			v.add("details.authors", "at least one author is required")
+		}
+		for i, author := range d.Authors {
+			if strings.TrimSpace(author) == "" {
+				v.add(fmt.Sprintf("details.authors[%d]", i), "author name must not be empty")
+			}
+		}
+		if d.ISSN != "" && !ValidISSN(d.ISSN) {
+			v.add("details.issn", "invalid ISSN (format NNNN-NNNC with a valid check digit)")
+		}
+	case TypeOrganization:
+		requireField(v, "details.organizationName", d.OrganizationName)
+		requireField(v, "details.position", d.Position)
+		if d.StartDate == nil {
+			v.add("details.startDate", "startDate is required")
+		}
+	case TypeCertification:
+		requireField(v, "details.certificationName", d.CertificationName)
+		requireField(v, "details.issuedBy", d.IssuedBy)
+	case TypeAcademic, TypeOther:
+		// Tidak ada field khusus
+	default:
+		v.add("achievementType", "achievementType must be one of: "+strings.Join(BuiltinAchievementTypes, ", ")+" or a registered custom type")
+	}
+
+	return v.orNil()
+}
+
+// ValidateCustomAchievement memvalidasi prestasi bertipe custom terhadap JSON Schema tipenya
+func ValidateCustomAchievement(a models.Achievement, t models.AchievementType) error {
+	v := &ValidationError{}
+	validateCommon(v, a)
+
+	if !t.IsActive {
+		v.add("achievementType", "achievement type "+t.Name+" is no longer active")
+		return v.orNil()
+	}
+
+	schema, err := helper.ParseJSONSchema(t.Schema)
+	if err != nil {
+		v.add("achievementType", "achievement type "+t.Name+" has an invalid schema")
+		return v.orNil()
+	}
+
+	var fields interface{} = map[string]interface{}{}
+	if a.Details.CustomFields != nil {
+		fields = a.Details.CustomFields
+	}
+	for _, e := range helper.ValidateJSONSchema(schema, fields, "details.customFields") {
+		v.add(e.Path, e.Message)
+	}
+	return v.orNil()
+}
+
+// IsBuiltinAchievementType mengecek apakah tipe adalah tipe bawaan (bukan custom)
+func IsBuiltinAchievementType(name string) bool {
+	for _, t := range BuiltinAchievementTypes {
+		if t == strings.ToLower(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCommon berlaku untuk semua tipe, bawaan maupun custom
+func validateCommon(v *ValidationError, a models.Achievement) {
+	if strings.TrimSpace(a.Title) == "" {
+		v.add("title", "title is required")
+	}
+	if a.Points <= 0 {
+		v.add("points", "points field is required and must be a positive number (minimum 1)")
+	}
+
+	d := a.Details
+	if d.StartDate != nil && d.EndDate != nil && d.EndDate.Before(*d.StartDate) {
+		v.add("details.endDate", "endDate must not be before startDate")
+	}
+	if d.EventDate != nil && d.ValidUntil != nil && d.ValidUntil.Before(*d.EventDate) {
+		v.add("details.validUntil", "validUntil must not be before eventDate")
+	}
+	if d.Score < 0 {
+		v.add("details.score", "score must not be negative")
+	}
+}
+
+func (e *ValidationError) orNil() error {
+	if len(e.Fields) > 0 {
+		return e
+	}
+	return nil
+}
+
+// ValidISSN mengecek format dan check digit ISSN (modulo 11)
+func ValidISSN(issn string) bool {
+	if !issnPattern.MatchString(issn) {
+		return false
+	}
+	digits := strings.ToUpper(strings.ReplaceAll(issn, "-", ""))
+
+	sum := 0
+	for i := 0; i < 7; i++ {
+		sum += int(digits[i]-'0') * (8 - i)
+	}
+	check := (11 - sum%11) % 11
+
+	expected := byte('0' + check)
+	if check == 10 {
+		expected = 'X'
+	}
+	return digits[7] == expected
+}
+
+func requireField(v *ValidationError, field, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.add(field, field[strings.LastIndex(field, ".")+1:]+" is required")
+	}
+}
+
+func checkEnum(v *ValidationError, field, value string, allowed []string) {
+	if value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if strings.EqualFold(a, value) {
+			return
+		}
+	}
+	v.add(field, "must be one of: "+strings.Join(allowed, ", "))
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"errors"
+
+	"gouas/app/models"
+)
+
+type AchievementAction string
+
+const (
+	ActionSubmit AchievementAction = "submit"
+	ActionVerify AchievementAction = "verify"
+	ActionReject AchievementAction = "reject"
+	ActionUpdate AchievementAction = "update"
+	ActionDelete AchievementAction = "delete"
+	ActionRevoke AchievementAction = "revoke"
+)
+
+// SideEffect dicatat ke outbox bersama perubahan status, lalu dijalankan dispatcher
+type SideEffect string
+
+const (
+	EffectAwardPoints   SideEffect = "award_points"
+	EffectNotifyAdvisor SideEffect = "notify_advisor"
+	EffectNotifyStudent SideEffect = "notify_student"
+	EffectSyncMongo     SideEffect = "sync_mongo"
+)
+
+var (
+	ErrInvalidTransition   = models.ErrInvalidTransition
+	ErrForbiddenTransition = errors.New("forbidden: you do not have permission to perform this action")
+	ErrNoteRequired        = errors.New("note is required for this action")
+)
+
+// Transition mendefinisikan satu perpindahan status yang legal.
+// To kosong berarti status tidak berubah (contoh: edit konten draft).
+type Transition struct {
+	Action      AchievementAction
+	From        []models.AchievementStatus
+	To          models.AchievementStatus
+	Permission  string // permission yang dibutuhkan untuk transisi ini
+	RequireNote bool
+	Effects     []SideEffect
+}
+
+// AchievementTransitions adalah tabel transisi workflow prestasi
+var AchievementTransitions = []Transition{
+	{
+		Action:     ActionSubmit,
+		From:       []models.AchievementStatus{models.StatusDraft, models.StatusRejected},
+		To:         models.StatusSubmitted,
+		Permission: models.PermAchievementSubmit,
+		Effects:    []SideEffect{EffectNotifyAdvisor},
+	},
+	{
+		Action:     ActionVerify,
+		From:       []models.AchievementStatus{models.StatusSubmitted},
+		To:         models.StatusVerified,
+		Permission: models.PermAchievementVerify,
+		Effects:    []SideEffect{EffectAwardPoints, EffectSyncMongo, EffectNotifyStudent},
+	},
+	{
+		Action:      ActionReject,
+		From:        []models.AchievementStatus{models.StatusSubmitted},
+		To:          models.StatusRejected,
+		Permission:  models.PermAchievementVerify,
+		RequireNote: true,
+		Effects:     []SideEffect{EffectNotifyStudent},
+	},
+	{
+		Action:     ActionUpdate,
+		From:       []models.AchievementStatus{models.StatusDraft, models.StatusRejected},
+		Permission: models.PermAchievementUpdate,
+	},
+	{
+		Action:     ActionDelete,
+		From:       []models.AchievementStatus{models.StatusDraft},
+		To:         models.StatusDeleted,
+		Permission: models.PermAchievementDelete,
+	},
+	{
+		Action:     ActionDelete,
+		From:       []models.AchievementStatus{models.StatusDraft, models.StatusSubmitted, models.StatusRejected},
+		To:         models.StatusDeleted,
+		Permission: models.PermAchievementManage,
+	},
+	{
+		// Admin tetap boleh menghapus prestasi yang sudah diverifikasi/di-revoke (seperti sebelum
+		// ada workflow). Poin prestasi verified ditarik atomic di repository seperti revoke.
+		Action:     ActionDelete,
+		From:       []models.AchievementStatus{models.StatusVerified, models.StatusRevoked},
+		To:         models.StatusDeleted,
+		Permission: models.PermAchievementManage,
+		Effects:    []SideEffect{EffectSyncMongo},
+	},
+	{
+		// Pengurangan poin dilakukan atomic bersama perubahan status di repository
+		Action:      ActionRevoke,
+		From:        []models.AchievementStatus{models.StatusVerified},
+		To:          models.StatusRevoked,
+		Permission:  models.PermAchievementRevoke,
+		RequireNote: true,
+		Effects:     []SideEffect{EffectSyncMongo, EffectNotifyStudent},
+	},
+}
+
+type AchievementWorkflow interface {
+	// Resolve mencari transisi untuk action dari status saat ini sesuai permission actor
+	Resolve(action AchievementAction, from models.AchievementStatus, permissions []string) (*Transition, error)
+}
+
+type achievementWorkflow struct {
+	transitions []Transition
+}
+
+func NewAchievementWorkflow() AchievementWorkflow {
+	return &achievementWorkflow{transitions: AchievementTransitions}
+}
+
+func (w *achievementWorkflow) Resolve(action AchievementAction, from models.AchievementStatus, permissions []string) (*Transition, error) {
+	stateMatched := false
+	for i := range w.transitions {
+		t := &w.transitions[i]
+		if t.Action != action || !containsStatus(t.From, from) {
+			continue
+		}
+		stateMatched = true
+		if containsString(permissions, t.Permission) {
+			return t, nil
+		}
+	}
+
+	if stateMatched {
+		return nil, ErrForbiddenTransition
+	}
+	return nil, ErrInvalidTransition
+}
+
+func containsStatus(list []models.AchievementStatus, status models.AchievementStatus) bool {
+	for _, s := range list {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
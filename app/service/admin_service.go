@@ -1,15 +1,20 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"gouas/app/models"
 	"gouas/app/repository"
 	"gouas/helper"
 	"math/rand"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type AdminService interface {
@@ -19,85 +24,140 @@ type AdminService interface {
 	GetUserDetail(c *fiber.Ctx) error
 	UpdateUser(c *fiber.Ctx) error
 	DeleteUser(c *fiber.Ctx) error
+	GetUserScopes(c *fiber.Ctx) error
+	SetUserScopes(c *fiber.Ctx) error
 }
 
 type adminService struct {
 	adminRepo repository.AdminRepository
+	orgRepo   repository.OrganizationRepository
+	uow       repository.UnitOfWork
 }
 
-func NewAdminService(adminRepo repository.AdminRepository) AdminService {
-	return &adminService{adminRepo}
+func NewAdminService(adminRepo repository.AdminRepository, orgRepo repository.OrganizationRepository, uow repository.UnitOfWork) AdminService {
+	return &adminService{adminRepo, orgRepo, uow}
 }
 
-func (s *adminService) CreateUser(c *fiber.Ctx) error {
-	var input struct {
-		Username string `json:"username"`
-		Email    string `json:"email"`
-		Password string `json:"password"`
-		FullName string `json:"fullName"`
-		RoleName string `json:"roleName"`
+// profileInput adalah data awal profile Mahasiswa/Dosen Wali (semua opsional).
+// NIM/NIP kosong diganti placeholder yang bisa diperbaiki lewat endpoint profile.
+type profileInput struct {
+	NIM            string     `json:"nim"`
+	NIP            string     `json:"nip"`
+	StudyProgramID *uuid.UUID `json:"studyProgramId"`
+	AcademicYear   string     `json:"academicYear"`
+	DepartmentID   *uuid.UUID `json:"departmentId"`
+	AdvisorID      *uuid.UUID `json:"-"` // hanya diisi import (dari NIP dosen wali)
+}
+
+// validate memastikan format NIM/NIP, unit organisasi ada & angkatan berformat tahun (YYYY)
+func (in *profileInput) validate(ctx context.Context, orgRepo repository.OrganizationRepository) error {
+	in.NIM = strings.TrimSpace(in.NIM)
+	in.NIP = strings.TrimSpace(in.NIP)
+	if in.NIM != "" {
+		if err := ValidateNIM(in.NIM); err != nil {
+			return fmt.Errorf("nim %v", err)
+		}
 	}
-	if err := c.BodyParser(&input); err != nil {
-		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	if in.NIP != "" {
+		if err := ValidateNIP(in.NIP); err != nil {
+			return fmt.Errorf("nip %v", err)
+		}
+	}
+	if in.AcademicYear == "" {
+		in.AcademicYear = strconv.Itoa(time.Now().Year())
+	}
+	if year, err := strconv.Atoi(in.AcademicYear); err != nil || len(in.AcademicYear) != 4 || year < 1900 {
+		return fmt.Errorf("academicYear must be a 4-digit year")
+	}
+	if in.StudyProgramID != nil {
+		if ok, err := orgRepo.UnitExists(ctx, models.UnitProgramStudy, *in.StudyProgramID); err != nil || !ok {
+			return fmt.Errorf("study program not found")
+		}
 	}
+	if in.DepartmentID != nil {
+		if ok, err := orgRepo.UnitExists(ctx, models.UnitDepartment, *in.DepartmentID); err != nil || !ok {
+			return fmt.Errorf("department not found")
+		}
+	}
+	return nil
+}
 
-	hashedPassword, err := helper.HashPassword(input.Password)
+// newUserInput dipakai bersama oleh CreateUser & import user massal
+type newUserInput struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	FullName string `json:"fullName"`
+	RoleName string `json:"roleName"`
+	profileInput
+}
+
+var ErrRoleNotFound = errors.New("Role not found")
+
+// createUserWithProfile meng-hash password lalu membuat user + profile dalam satu transaksi.
+// Input profile & password policy harus sudah divalidasi pemanggil. User wajib ganti
+// password saat login pertama karena password-nya ditentukan admin.
+func createUserWithProfile(ctx context.Context, adminRepo repository.AdminRepository, uow repository.UnitOfWork, in newUserInput) (models.User, error) {
+	hashedPassword, err := helper.HashPassword(in.Password)
 	if err != nil {
-		return c.Status(500).JSON(helper.APIResponse("error", "Hashing failed", nil))
+		return models.User{}, fmt.Errorf("hashing failed: %w", err)
 	}
 
-	role, err := s.adminRepo.FindRoleByName(input.RoleName)
+	role, err := adminRepo.FindRoleByName(ctx, in.RoleName)
 	if err != nil {
-		return c.Status(400).JSON(helper.APIResponse("error", "Role not found", nil))
+		return models.User{}, ErrRoleNotFound
 	}
 
 	newUser := models.User{
-		Username:     input.Username,
-		Email:        input.Email,
+		Username:     in.Username,
+		Email:        in.Email,
 		PasswordHash: hashedPassword,
-		FullName:     input.FullName,
+		FullName:     in.FullName,
 		RoleID:       role.ID,
 		IsActive:     true,
-	}
 
-	createdUser, err := s.adminRepo.CreateUser(newUser)
-	if err != nil {
-		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+		MustChangePassword: true,
 	}
 
-	// AUTO-CREATE PROFILE
-	randSrc := rand.NewSource(time.Now().UnixNano())
-	r := rand.New(randSrc)
-	randomCode := strconv.Itoa(r.Intn(90000) + 10000)
-
-	switch input.RoleName {
-	case "Mahasiswa":
-		student := models.Student{
-			UserID:       createdUser.ID,
-			NIM:          "NIM-" + input.Username + "-" + randomCode,
-			ProgramStudy: "Informatika",
-			AcademicYear: "2025",
-		}
-		if err := s.adminRepo.CreateStudentProfile(student); err != nil {
-			s.adminRepo.DeleteUser(createdUser.ID)
-			return c.Status(500).JSON(helper.APIResponse("error", "Failed to create student profile", nil))
-		}
-	case "Dosen Wali":
-		lecturer := models.Lecturer{
-			UserID:     createdUser.ID,
-			NIP:        "NIP-" + input.Username + "-" + randomCode,
-			Department: "Informatika",
-		}
-		if err := s.adminRepo.CreateLecturerProfile(lecturer); err != nil {
-			s.adminRepo.DeleteUser(createdUser.ID)
-			return c.Status(500).JSON(helper.APIResponse("error", "Failed to create lecturer profile", nil))
+	var createdUser models.User
+	err = uow.Do(ctx, func(repos repository.TxRepositories) error {
+		var err error
+		createdUser, err = repos.Admin.CreateUser(ctx, newUser)
+		if err != nil {
+			return err
 		}
+		return ensureProfile(ctx, repos, createdUser, in.RoleName, in.profileInput)
+	})
+	return createdUser, err
+}
+
+func (s *adminService) CreateUser(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	var input newUserInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	if err := helper.ValidatePassword(input.Password, input.Username); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: "+err.Error(), nil))
+	}
+	if err := input.profileInput.validate(ctx, s.orgRepo); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: "+err.Error(), nil))
+	}
+
+	// User + profile dibuat dalam satu transaksi
+	createdUser, err := createUserWithProfile(ctx, s.adminRepo, s.uow, input)
+	if errors.Is(err, ErrRoleNotFound) {
+		return c.Status(400).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
 
 	return c.Status(201).JSON(helper.APIResponse("success", "User created", createdUser))
 }
 
 func (s *adminService) AssignRole(c *fiber.Ctx) error {
+	ctx := c.UserContext()
 	id, _ := uuid.Parse(c.Params("id"))
 	var input struct {
 		RoleName string `json:"roleName"`
@@ -106,18 +166,109 @@ func (s *adminService) AssignRole(c *fiber.Ctx) error {
 		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
 	}
 
-	role, err := s.adminRepo.FindRoleByName(input.RoleName)
+	role, err := s.adminRepo.FindRoleByName(ctx, input.RoleName)
 	if err != nil {
 		return c.Status(404).JSON(helper.APIResponse("error", "Role not found", nil))
 	}
-	if err := s.adminRepo.UpdateUserRole(id, role.ID); err != nil {
-		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	user, err := s.adminRepo.FindUserByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "User not found", nil))
+	}
+
+	// Jangan sampai user:manage / role:manage terakhir hilang karena pergantian role
+	var lost []string
+	if user.IsActive {
+		for _, p := range user.Role.Permissions {
+			if !role.HasPermission(p.Name) {
+				lost = append(lost, p.Name)
+			}
+		}
+	}
+
+	// Cek pemegang permission, ganti role + siapkan profile untuk role baru secara atomic
+	err = s.uow.Do(ctx, func(repos repository.TxRepositories) error {
+		if err := ensurePermissionHolders(ctx, repos.Role, lost, uuid.Nil, user.ID); err != nil {
+			return err
+		}
+		if err := repos.Admin.UpdateUserRole(ctx, user.ID, role.ID); err != nil {
+			return err
+		}
+		profile := profileInput{AcademicYear: strconv.Itoa(time.Now().Year())}
+		return ensureProfile(ctx, repos, *user, role.Name, profile)
+	})
+	if err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
 	return c.Status(200).JSON(helper.APIResponse("success", "Role updated", nil))
 }
 
+// ensureProfile membuat profile Mahasiswa/Dosen Wali jika user belum punya
+func ensureProfile(ctx context.Context, repos repository.TxRepositories, user models.User, roleName string, profile profileInput) error {
+	randSrc := rand.NewSource(time.Now().UnixNano())
+	r := rand.New(randSrc)
+	randomCode := strconv.Itoa(r.Intn(90000) + 10000)
+
+	switch roleName {
+	case "Mahasiswa":
+		_, err := repos.Student.FindByUserID(ctx, user.ID)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		// advisor_id tidak diisi langsung: ApplyAssignments yang mengisinya sekaligus mencatat riwayat
+		student := models.Student{
+			ID:             uuid.New(),
+			UserID:         user.ID,
+			NIM:            profile.NIM,
+			StudyProgramID: profile.StudyProgramID,
+			AcademicYear:   profile.AcademicYear,
+		}
+		if student.NIM == "" {
+			student.NIM = "NIM-" + user.Username + "-" + randomCode
+		}
+		if err := repos.Admin.CreateStudentProfile(ctx, student); err != nil {
+			return fmt.Errorf("failed to create student profile: %w", err)
+		}
+		if profile.AdvisorID != nil {
+			assignment := models.AdvisorAssignment{
+				StudentID:     student.ID,
+				LecturerID:    *profile.AdvisorID,
+				Reason:        models.AssignImport,
+				EffectiveFrom: time.Now(),
+			}
+			noOutbox := func([]models.AdvisorAssignment) ([]models.OutboxEvent, error) { return nil, nil }
+			if _, err := repos.Advisor.ApplyAssignments(ctx, []models.AdvisorAssignment{assignment}, noOutbox); err != nil {
+				return fmt.Errorf("failed to assign advisor: %w", err)
+			}
+		}
+	case "Dosen Wali":
+		_, err := repos.Lecturer.FindByUserID(ctx, user.ID)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		lecturer := models.Lecturer{
+			UserID:       user.ID,
+			NIP:          profile.NIP,
+			DepartmentID: profile.DepartmentID,
+		}
+		if lecturer.NIP == "" {
+			lecturer.NIP = "NIP-" + user.Username + "-" + randomCode
+		}
+		if err := repos.Admin.CreateLecturerProfile(ctx, lecturer); err != nil {
+			return fmt.Errorf("failed to create lecturer profile: %w", err)
+		}
+	}
+	return nil
+}
+
 func (s *adminService) GetAllUsers(c *fiber.Ctx) error {
-	users, err := s.adminRepo.FindAllUsers()
+	ctx := c.UserContext()
+	users, err := s.adminRepo.FindAllUsers(ctx)
 	if err != nil {
 		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
@@ -125,8 +276,9 @@ func (s *adminService) GetAllUsers(c *fiber.Ctx) error {
 }
 
 func (s *adminService) GetUserDetail(c *fiber.Ctx) error {
+	ctx := c.UserContext()
 	id, _ := uuid.Parse(c.Params("id"))
-	user, err := s.adminRepo.FindUserByID(id)
+	user, err := s.adminRepo.FindUserByID(ctx, id)
 	if err != nil {
 		return c.Status(404).JSON(helper.APIResponse("error", "User not found", nil))
 	}
@@ -134,6 +286,7 @@ func (s *adminService) GetUserDetail(c *fiber.Ctx) error {
 }
 
 func (s *adminService) UpdateUser(c *fiber.Ctx) error {
+	ctx := c.UserContext()
 	id, _ := uuid.Parse(c.Params("id"))
 	var input struct {
 		FullName string `json:"fullName"`
@@ -141,23 +294,86 @@ func (s *adminService) UpdateUser(c *fiber.Ctx) error {
 	}
 	c.BodyParser(&input)
 
-	user, err := s.adminRepo.FindUserByID(id)
+	user, err := s.adminRepo.FindUserByID(ctx, id)
 	if err != nil {
 		return c.Status(404).JSON(helper.APIResponse("error", "User not found", nil))
 	}
 	user.FullName = input.FullName
 	user.Email = input.Email
 
-	if err := s.adminRepo.UpdateUser(*user); err != nil {
+	if err := s.adminRepo.UpdateUser(ctx, *user); err != nil {
 		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
 	return c.Status(200).JSON(helper.APIResponse("success", "User updated", nil))
 }
 
 func (s *adminService) DeleteUser(c *fiber.Ctx) error {
+	ctx := c.UserContext()
 	id, _ := uuid.Parse(c.Params("id"))
-	if err := s.adminRepo.DeleteUser(id); err != nil {
-		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	user, err := s.adminRepo.FindUserByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "User not found", nil))
+	}
+	var lost []string
+	if user.IsActive {
+		for _, p := range user.Role.Permissions {
+			lost = append(lost, p.Name)
+		}
+	}
+
+	err = s.uow.Do(ctx, func(repos repository.TxRepositories) error {
+		if err := ensurePermissionHolders(ctx, repos.Role, lost, uuid.Nil, user.ID); err != nil {
+			return err
+		}
+		return repos.Admin.DeleteUser(ctx, id)
+	})
+	if err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
 	return c.Status(200).JSON(helper.APIResponse("success", "User deleted", nil))
-}
\ No newline at end of file
+}
+
+func (s *adminService) GetUserScopes(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	scopes, err := s.adminRepo.FindUserScopes(ctx, id)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "User scopes", scopes))
+}
+
+// SetUserScopes mengganti daftar unit organisasi user. Body: {"scopes": [{"unit": "program_study", "unitId": "..."}]}
+// Daftar kosong menghapus pembatasan (akses kembali global sesuai permission).
+func (s *adminService) SetUserScopes(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	var input struct {
+		Scopes []struct {
+			Unit   string    `json:"unit"`
+			UnitID uuid.UUID `json:"unitId"`
+		} `json:"scopes"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	if _, err := s.adminRepo.FindUserByID(ctx, id); err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "User not found", nil))
+	}
+
+	scopes := make([]models.UserScope, 0, len(input.Scopes))
+	for _, in := range input.Scopes {
+		if !models.IsValidUnit(in.Unit) {
+			return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: unit must be faculty, department or program_study", nil))
+		}
+		if ok, err := s.orgRepo.UnitExists(ctx, in.Unit, in.UnitID); err != nil || !ok {
+			return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: "+in.Unit+" "+in.UnitID.String()+" not found", nil))
+		}
+		scopes = append(scopes, models.UserScope{UserID: id, Unit: in.Unit, UnitID: in.UnitID})
+	}
+
+	if err := s.adminRepo.ReplaceUserScopes(ctx, id, scopes); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "User scopes updated", scopes))
+}
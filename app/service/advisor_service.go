@@ -0,0 +1,366 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gouas/app/models"
+	"gouas/app/repository"
+	"gouas/helper"
+	"gouas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// AdvisorService mengatur penugasan dosen wali: manual, pemindahan massal & pembagian otomatis
+type AdvisorService interface {
+	Assign(c *fiber.Ctx) error
+	GetHistory(c *fiber.Ctx) error
+	ReassignAdvisees(c *fiber.Ctx) error
+	Balance(c *fiber.Ctx) error
+}
+
+type advisorService struct {
+	repo         repository.AdvisorRepository
+	studentRepo  repository.StudentRepository
+	lecturerRepo repository.LecturerRepository
+}
+
+func NewAdvisorService(repo repository.AdvisorRepository, studentRepo repository.StudentRepository, lecturerRepo repository.LecturerRepository) AdvisorService {
+	return &advisorService{repo, studentRepo, lecturerRepo}
+}
+
+// advisorPlanItem adalah satu perpindahan mahasiswa ke dosen wali baru
+type advisorPlanItem struct {
+	StudentID  uuid.UUID `json:"studentId"`
+	NIM        string    `json:"nim"`
+	LecturerID uuid.UUID `json:"lecturerId"`
+	NIP        string    `json:"nip"`
+}
+
+// activeLecturer memastikan dosen ada dan akun user-nya masih aktif
+func (s *advisorService) activeLecturer(ctx context.Context, id uuid.UUID) (*models.Lecturer, error) {
+	lecturer, err := s.lecturerRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("advisor (lecturer) not found")
+	}
+	if !lecturer.User.IsActive {
+		return nil, fmt.Errorf("advisor %s is not active", lecturer.NIP)
+	}
+	return lecturer, nil
+}
+
+// distributeByLoad membagi mahasiswa ke dosen dengan beban bimbingan paling sedikit.
+// Beban sama diurutkan berdasarkan NIP agar hasil dry-run dan eksekusi konsisten.
+func distributeByLoad(students []models.Student, lecturers []models.Lecturer, loads map[uuid.UUID]int64) []advisorPlanItem {
+	sorted := append([]models.Lecturer(nil), lecturers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].NIP < sorted[j].NIP })
+
+	current := map[uuid.UUID]int64{}
+	for _, l := range sorted {
+		current[l.ID] = loads[l.ID]
+	}
+
+	plan := make([]advisorPlanItem, 0, len(students))
+	for _, st := range students {
+		best := sorted[0]
+		for _, l := range sorted[1:] {
+			if current[l.ID] < current[best.ID] {
+				best = l
+			}
+		}
+		current[best.ID]++
+		plan = append(plan, advisorPlanItem{StudentID: st.ID, NIM: st.NIM, LecturerID: best.ID, NIP: best.NIP})
+	}
+	return plan
+}
+
+// apply mencatat plan sebagai riwayat penugasan. Prestasi submitted ikut pindah karena
+// verifikasi mengikuti students.advisor_id; dosen baru diberi notifikasi lewat outbox.
+// Mahasiswa yang dosen walinya sudah berubah sejak plan dibuat dilewati dan dikembalikan sebagai skipped.
+func (s *advisorService) apply(ctx context.Context, plan []advisorPlanItem, previous map[uuid.UUID]*uuid.UUID, reason, note string, actorID uuid.UUID) (applied, skipped []advisorPlanItem, err error) {
+	now := time.Now()
+	assignments := make([]models.AdvisorAssignment, 0, len(plan))
+	for _, p := range plan {
+		assignments = append(assignments, models.AdvisorAssignment{
+			StudentID:          p.StudentID,
+			LecturerID:         p.LecturerID,
+			PreviousLecturerID: previous[p.StudentID],
+			Reason:             reason,
+			Note:               note,
+			AssignedBy:         &actorID,
+			EffectiveFrom:      now,
+		})
+	}
+
+	done, err := s.repo.ApplyAssignments(ctx, assignments, func(done []models.AdvisorAssignment) ([]models.OutboxEvent, error) {
+		return s.notifyTransferred(ctx, done)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	assigned := make(map[uuid.UUID]bool, len(done))
+	for _, a := range done {
+		assigned[a.StudentID] = true
+	}
+	applied, skipped = []advisorPlanItem{}, []advisorPlanItem{}
+	for _, p := range plan {
+		if assigned[p.StudentID] {
+			applied = append(applied, p)
+		} else {
+			skipped = append(skipped, p)
+		}
+	}
+	return applied, skipped, nil
+}
+
+// notifyTransferred menyiapkan notifikasi untuk dosen yang menerima prestasi submitted
+func (s *advisorService) notifyTransferred(ctx context.Context, assignments []models.AdvisorAssignment) ([]models.OutboxEvent, error) {
+	if len(assignments) == 0 {
+		return nil, nil
+	}
+	studentIDs := make([]uuid.UUID, 0, len(assignments))
+	for _, a := range assignments {
+		studentIDs = append(studentIDs, a.StudentID)
+	}
+	pending, err := s.repo.CountPendingSubmissions(ctx, studentIDs)
+	if err != nil {
+		return nil, err
+	}
+	transferred := map[uuid.UUID]int64{}
+	for _, a := range assignments {
+		transferred[a.LecturerID] += pending[a.StudentID]
+	}
+
+	var outbox []models.OutboxEvent
+	lecturerIDs := make([]uuid.UUID, 0, len(transferred))
+	for id := range transferred {
+		lecturerIDs = append(lecturerIDs, id)
+	}
+	sort.Slice(lecturerIDs, func(i, j int) bool { return bytes.Compare(lecturerIDs[i][:], lecturerIDs[j][:]) < 0 })
+	for _, id := range lecturerIDs {
+		if transferred[id] == 0 {
+			continue
+		}
+		lecturer, err := s.lecturerRepo.FindByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		event, err := models.NewOutboxEvent(models.OutboxNotify, id, models.NotifyPayload{
+			UserID:  lecturer.UserID,
+			Subject: "New advisees assigned",
+			Message: fmt.Sprintf("%d submitted achievements from your new advisees are waiting for verification", transferred[id]),
+		})
+		if err != nil {
+			return nil, err
+		}
+		outbox = append(outbox, event)
+	}
+	return outbox, nil
+}
+
+// assignedMessage menyebut mahasiswa yang dilewati karena dosen walinya diubah request lain
+func assignedMessage(format string, applied, skipped []advisorPlanItem) string {
+	msg := fmt.Sprintf(format, len(applied))
+	if len(skipped) > 0 {
+		msg += fmt.Sprintf("; %d skipped because their advisor changed concurrently", len(skipped))
+	}
+	return msg
+}
+
+// Assign menetapkan dosen wali satu mahasiswa. Body: {"advisorId": "...", "note": "..."}
+func (s *advisorService) Assign(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid student ID", nil))
+	}
+	var input struct {
+		AdvisorID string `json:"advisorId"`
+		Note      string `json:"note"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	advisorID, err := uuid.Parse(input.AdvisorID)
+	if err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: advisorId must be a valid UUID", nil))
+	}
+
+	student, err := s.studentRepo.FindByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Student not found", nil))
+	}
+	lecturer, err := s.activeLecturer(ctx, advisorID)
+	if err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: "+err.Error(), nil))
+	}
+	if student.AdvisorID != nil && *student.AdvisorID == lecturer.ID {
+		return c.Status(200).JSON(helper.APIResponse("success", "Advisor already assigned", nil))
+	}
+
+	plan := []advisorPlanItem{{StudentID: student.ID, NIM: student.NIM, LecturerID: lecturer.ID, NIP: lecturer.NIP}}
+	previous := map[uuid.UUID]*uuid.UUID{student.ID: student.AdvisorID}
+	applied, _, err := s.apply(ctx, plan, previous, models.AssignManual, input.Note, uuid.MustParse(authData.UserID))
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if len(applied) == 0 {
+		return c.Status(409).JSON(helper.APIResponse("error", "Advisor was changed by another request; reload and try again", nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Advisor assigned successfully", applied[0]))
+}
+
+// GetHistory menampilkan riwayat dosen wali (mengikuti scope data mahasiswa)
+func (s *advisorService) GetHistory(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
+	id, _ := uuid.Parse(c.Params("id"))
+
+	student, err := s.studentRepo.FindByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Student not found", nil))
+	}
+	if !authData.Can(models.PermAdvisorAssign) && !canAccessStudent(ctx, s.studentRepo, s.lecturerRepo, authData, student) {
+		return c.Status(403).JSON(helper.APIResponse("error", "Forbidden", nil))
+	}
+
+	history, err := s.repo.FindHistory(ctx, student.ID)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Advisor history", history))
+}
+
+// ReassignAdvisees memindahkan semua bimbingan dosen (mis. pensiun/mutasi).
+// Body: {"targetLecturerId": "...", "note": "..."}; tanpa target, bimbingan dibagi ke
+// dosen aktif lain di jurusan yang sama berdasarkan beban.
+func (s *advisorService) ReassignAdvisees(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
+	id, _ := uuid.Parse(c.Params("id"))
+	var input struct {
+		TargetLecturerID string `json:"targetLecturerId"`
+		Note             string `json:"note"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+
+	leaving, err := s.lecturerRepo.FindByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Lecturer not found", nil))
+	}
+	advisees, err := s.lecturerRepo.FindAdvisees(ctx, leaving.ID)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if len(advisees) == 0 {
+		return c.Status(200).JSON(helper.APIResponse("success", "Lecturer has no advisees", []advisorPlanItem{}))
+	}
+
+	var targets []models.Lecturer
+	if input.TargetLecturerID != "" {
+		targetID, err := uuid.Parse(input.TargetLecturerID)
+		if err != nil {
+			return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: targetLecturerId must be a valid UUID", nil))
+		}
+		target, err := s.activeLecturer(ctx, targetID)
+		if err != nil {
+			return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: "+err.Error(), nil))
+		}
+		targets = []models.Lecturer{*target}
+	} else if leaving.DepartmentID != nil {
+		targets, err = s.repo.FindActiveLecturers(ctx, *leaving.DepartmentID)
+		if err != nil {
+			return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+		}
+	}
+	// Dosen yang keluar tidak boleh menerima bimbingannya sendiri
+	filtered := targets[:0]
+	for _, l := range targets {
+		if l.ID != leaving.ID {
+			filtered = append(filtered, l)
+		}
+	}
+	if len(filtered) == 0 {
+		return c.Status(400).JSON(helper.APIResponse("error", "No other active lecturer in the department; specify targetLecturerId", nil))
+	}
+
+	plan, err := s.planByLoad(ctx, advisees, filtered)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	previous := map[uuid.UUID]*uuid.UUID{}
+	for _, st := range advisees {
+		previous[st.ID] = &leaving.ID
+	}
+	applied, skipped, err := s.apply(ctx, plan, previous, models.AssignReassign, input.Note, uuid.MustParse(authData.UserID))
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", assignedMessage("%d advisees reassigned", applied, skipped), applied))
+}
+
+// Balance membagi mahasiswa tanpa dosen wali di satu jurusan ke dosen aktif jurusan itu.
+// Body: {"departmentId": "..."}; ?dryRun=true hanya menampilkan rencana pembagian.
+func (s *advisorService) Balance(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
+	var input struct {
+		DepartmentID string `json:"departmentId"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	deptID, err := uuid.Parse(input.DepartmentID)
+	if err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: departmentId must be a valid UUID", nil))
+	}
+
+	students, err := s.repo.FindUnassignedStudents(ctx, deptID)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if len(students) == 0 {
+		return c.Status(200).JSON(helper.APIResponse("success", "No unassigned students", []advisorPlanItem{}))
+	}
+	lecturers, err := s.repo.FindActiveLecturers(ctx, deptID)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if len(lecturers) == 0 {
+		return c.Status(400).JSON(helper.APIResponse("error", "No active lecturer in the department", nil))
+	}
+
+	plan, err := s.planByLoad(ctx, students, lecturers)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if c.QueryBool("dryRun") {
+		return c.Status(200).JSON(helper.APIResponse("success", "Dry run: advisor distribution plan", plan))
+	}
+
+	applied, skipped, err := s.apply(ctx, plan, map[uuid.UUID]*uuid.UUID{}, models.AssignBalance, "", uuid.MustParse(authData.UserID))
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", assignedMessage("%d students assigned", applied, skipped), applied))
+}
+
+func (s *advisorService) planByLoad(ctx context.Context, students []models.Student, lecturers []models.Lecturer) ([]advisorPlanItem, error) {
+	ids := make([]uuid.UUID, 0, len(lecturers))
+	for _, l := range lecturers {
+		ids = append(ids, l.ID)
+	}
+	loads, err := s.repo.CountAdvisees(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return distributeByLoad(students, lecturers, loads), nil
+}
@@ -1,9 +1,14 @@
 package service
 
 import (
+	"gouas/app/models"
 	"gouas/app/repository"
 	"gouas/helper"
 	"gouas/middleware"
+	"log"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -15,63 +20,133 @@ type AuthService interface {
 	Refresh(c *fiber.Ctx) error
 	Logout(c *fiber.Ctx) error
 	GetProfile(c *fiber.Ctx) error
+	ListSessions(c *fiber.Ctx) error
+	RevokeSession(c *fiber.Ctx) error
+	LogoutAll(c *fiber.Ctx) error
+	JWKS(c *fiber.Ctx) error
+	UnlockUser(c *fiber.Ctx) error
+	GetLoginAttempts(c *fiber.Ctx) error
 }
 
 type authService struct {
-	authRepo repository.AuthRepository
+	authRepo    repository.AuthRepository
+	attemptRepo repository.LoginAttemptRepository
+	guard       loginGuard
 }
 
-func NewAuthService(authRepo repository.AuthRepository) AuthService {
-	return &authService{authRepo}
+func NewAuthService(authRepo repository.AuthRepository, attemptRepo repository.LoginAttemptRepository, policy LoginPolicy) AuthService {
+	return &authService{authRepo, attemptRepo, loginGuard{attemptRepo, policy}}
+}
+
+// dummyHash dibandingkan saat username tidak ada agar waktu respons sama dengan password salah
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+func checkPasswordConstantTime(password string, user *models.User) bool {
+	if user == nil {
+		dummyHashOnce.Do(func() { dummyHash, _ = helper.HashPassword("gouas-dummy-password") })
+		helper.CheckPasswordHash(password, dummyHash)
+		return false
+	}
+	return helper.CheckPasswordHash(password, user.PasswordHash)
 }
 
 func (s *authService) Login(c *fiber.Ctx) error {
+	ctx := c.UserContext()
 	var input struct {
 		Username string `json:"username"`
 		Password string `json:"password"`
+		Device   string `json:"device"` // opsional, mis. "Laptop Kantor"
 	}
 	if err := c.BodyParser(&input); err != nil {
 		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
 	}
 
-	// 1. Cari user
-	user, err := s.authRepo.FindByUsername(input.Username)
+	attempt := models.LoginAttempt{
+		Username:  strings.ToLower(strings.TrimSpace(input.Username)),
+		IPAddress: c.IP(),
+		UserAgent: c.Get("User-Agent"),
+	}
+	attempt.Username = truncateRunes(attempt.Username, 50)
+
+	// 1. Brute-force guard: backoff per IP & per username, lalu lockout username.
+	// Percobaan dicatat (pending) sebelum bcrypt agar request paralel ikut terhitung.
+	attempt, status, wait, err := s.guard.reserve(ctx, attempt)
 	if err != nil {
-		return c.Status(401).JSON(helper.APIResponse("error", "Invalid credentials", nil))
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if status != 0 {
+		return rejection(c, status, wait)
 	}
 
-	// 2. Cek Password
-	if !helper.CheckPasswordHash(input.Password, user.PasswordHash) {
+	// 2. Cari user & cek password. User tidak ada / password salah / nonaktif mendapat
+	// pesan yang sama agar status akun tidak bocor.
+	user, err := s.authRepo.FindByUsername(ctx, input.Username)
+	if err != nil {
+		user = nil
+	}
+	if user != nil {
+		attempt.UserID = &user.ID
+	}
+	if !checkPasswordConstantTime(input.Password, user) {
+		s.guard.complete(ctx, attempt, models.LoginInvalid)
 		return c.Status(401).JSON(helper.APIResponse("error", "Invalid credentials", nil))
 	}
 
 	// 3. Cek Active
 	if !user.IsActive {
-		return c.Status(401).JSON(helper.APIResponse("error", "User is inactive", nil))
+		s.guard.complete(ctx, attempt, models.LoginInactive)
+		return c.Status(401).JSON(helper.APIResponse("error", "Invalid credentials", nil))
 	}
+	s.guard.complete(ctx, attempt, models.LoginSucceeded)
 
-	// 4. Update Token ID di DB (Stateful)
+	// 4. Buat session baru (family refresh token); session di perangkat lain tetap aktif
 	newAccessID := uuid.New()
-	newRefreshID := uuid.New()
-	if err := s.authRepo.UpdateTokenIDs(user.ID, &newAccessID, &newRefreshID); err != nil {
+	sessionID := uuid.New()
+	refreshID := uuid.New()
+	refreshToken, _ := helper.GenerateRefreshToken(user.ID, refreshID, sessionID)
+	device := strings.TrimSpace(input.Device)
+	if device == "" {
+		device = deviceFromUserAgent(c.Get("User-Agent"))
+	} else {
+		device = truncateRunes(device, 100)
+	}
+	now := time.Now()
+	if _, err := s.authRepo.CreateSession(ctx, models.UserSession{
+		ID:            sessionID,
+		UserID:        user.ID,
+		Device:        device,
+		IPAddress:     c.IP(),
+		UserAgent:     c.Get("User-Agent"),
+		AccessTokenID: newAccessID,
+		LastSeenAt:    now,
+		ExpiresAt:     now.Add(helper.RefreshTokenTTL),
+	}, models.RefreshToken{
+		ID:        refreshID,
+		TokenHash: helper.HashToken(refreshToken),
+		ExpiresAt: now.Add(helper.RefreshTokenTTL),
+	}); err != nil {
 		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
 
-	// 5. Generate Tokens
+	// 5. Generate Access Token
 	permissions := []string{}
 	for _, p := range user.Role.Permissions {
 		permissions = append(permissions, p.Name)
 	}
-	accessToken, _ := helper.GenerateAccessToken(user.ID, user.Role.Name, permissions, newAccessID)
-	refreshToken, _ := helper.GenerateRefreshToken(user.ID, newRefreshID)
+	accessToken, _ := helper.GenerateAccessToken(user.ID, user.Role.Name, permissions, newAccessID, sessionID)
 
 	return c.Status(200).JSON(helper.APIResponse("success", "Login successful", fiber.Map{
-		"accessToken":  accessToken,
-		"refreshToken": refreshToken,
+		"accessToken":        accessToken,
+		"refreshToken":       refreshToken,
+		"mustChangePassword": user.MustChangePassword,
 	}))
 }
 
 func (s *authService) Refresh(c *fiber.Ctx) error {
+	ctx := c.UserContext()
 	// 1. Ambil token dari header Authorization
 	authHeader := c.Get("Authorization")
 	if authHeader == "" {
@@ -84,55 +159,210 @@ func (s *authService) Refresh(c *fiber.Ctx) error {
 		return c.Status(401).JSON(helper.APIResponse("error", "Invalid token format", nil))
 	}
 
-	// 3. Validasi Token (Stateless Check)
-	claims, err := helper.ValidateJWT(tokenString)
+	// 3. Validasi Token (Stateless Check), harus berjenis refresh
+	claims, err := helper.ValidateJWT(tokenString, helper.TokenRefresh)
 	if err != nil {
 		return c.Status(401).JSON(helper.APIResponse("error", "Invalid or expired refresh token", nil))
 	}
 
-	// 4. Cek DB: Pastikan Refresh Token ini yang terdaftar/aktif (Stateful Check)
-	user, err := s.authRepo.FindByID(claims.UserID)
-	if err != nil || user.CurrentRefreshTokenID == nil || *user.CurrentRefreshTokenID != claims.TokenID {
+	// 4. Cek DB: token harus terdaftar di family session-nya (Stateful Check)
+	stored, err := s.authRepo.FindRefreshToken(ctx, claims.TokenID)
+	if err != nil || stored.SessionID != claims.SessionID || stored.TokenHash != helper.HashToken(tokenString) {
+		return c.Status(401).JSON(helper.APIResponse("error", "Refresh token has been revoked", nil))
+	}
+	// Token yang sudah dirotasi dipakai lagi -> kemungkinan dicuri, cabut seluruh family
+	if stored.RotatedAt != nil {
+		return s.revokeReusedFamily(c, claims)
+	}
+	session, err := s.authRepo.FindActiveSession(ctx, stored.SessionID)
+	if err != nil || session.UserID != claims.UserID {
+		return c.Status(401).JSON(helper.APIResponse("error", "Refresh token has been revoked", nil))
+	}
+	user, err := s.authRepo.FindByID(ctx, claims.UserID)
+	if err != nil || !user.IsActive {
 		return c.Status(401).JSON(helper.APIResponse("error", "Refresh token has been revoked", nil))
 	}
 
-	// 5. Rotasi Access Token ID (Bikin Access Token lama mati)
+	// 5. Rotasi: refresh token lama ditukar token baru, access token lama di perangkat ini mati
 	newAccessID := uuid.New()
-	// Gunakan CurrentRefreshTokenID agar refresh token lama tetap valid sampai 24 jam
-	if err := s.authRepo.UpdateTokenIDs(user.ID, &newAccessID, user.CurrentRefreshTokenID); err != nil {
+	newRefreshID := uuid.New()
+	newRefresh, _ := helper.GenerateRefreshToken(user.ID, newRefreshID, session.ID)
+	rotated, err := s.authRepo.RotateRefreshToken(ctx, stored.ID, models.RefreshToken{
+		ID:        newRefreshID,
+		SessionID: session.ID,
+		TokenHash: helper.HashToken(newRefresh),
+		ExpiresAt: time.Now().Add(helper.RefreshTokenTTL),
+	}, newAccessID)
+	if err != nil {
 		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
+	if !rotated {
+		return s.revokeReusedFamily(c, claims)
+	}
 
 	// 6. Generate Access Token Baru
 	var permissions []string
 	for _, p := range user.Role.Permissions {
 		permissions = append(permissions, p.Name)
 	}
-	newAccess, _ := helper.GenerateAccessToken(user.ID, user.Role.Name, permissions, newAccessID)
+	newAccess, _ := helper.GenerateAccessToken(user.ID, user.Role.Name, permissions, newAccessID, session.ID)
 
 	return c.Status(200).JSON(helper.APIResponse("success", "Token refreshed", fiber.Map{
 		"accessToken":  newAccess,
-		"refreshToken": tokenString, // Kembalikan token yang sama karena ID-nya belum berubah
+		"refreshToken": newRefresh,
 	}))
 }
 
+// revokeReusedFamily mencabut session pemilik refresh token yang dipakai ulang
+func (s *authService) revokeReusedFamily(c *fiber.Ctx, claims *helper.JWTClaims) error {
+	if _, err := s.authRepo.RevokeSession(c.UserContext(), claims.UserID, claims.SessionID); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	log.Printf("[AUTH] refresh token reuse detected: user=%s session=%s ip=%s", claims.UserID, claims.SessionID, c.IP())
+	return c.Status(401).JSON(helper.APIResponse("error", "Refresh token reuse detected; session has been revoked", nil))
+}
+
 func (s *authService) Logout(c *fiber.Ctx) error {
-	authData, err := middleware.CheckAuth(c.Get("Authorization"))
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
+	userID, _ := uuid.Parse(authData.UserID)
+	sessionID, _ := uuid.Parse(authData.SessionID)
+
+	if _, err := s.authRepo.RevokeSession(ctx, userID, sessionID); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Logged out successfully", nil))
+}
+
+// LogoutAll mencabut semua session user, termasuk session saat ini
+func (s *authService) LogoutAll(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
+	userID, _ := uuid.Parse(authData.UserID)
+
+	if err := s.authRepo.RevokeAllSessions(ctx, userID, uuid.Nil); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Logged out from all devices", nil))
+}
+
+func (s *authService) ListSessions(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
+	userID, _ := uuid.Parse(authData.UserID)
+
+	sessions, err := s.authRepo.FindActiveSessions(ctx, userID)
 	if err != nil {
-		return c.Status(401).JSON(helper.APIResponse("error", "Unauthorized", nil))
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID.String() == authData.SessionID
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Active sessions", sessions))
+}
+
+func (s *authService) RevokeSession(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
 	userID, _ := uuid.Parse(authData.UserID)
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid session ID", nil))
+	}
 
-	if err := s.authRepo.UpdateTokenIDs(userID, nil, nil); err != nil {
+	revoked, err := s.authRepo.RevokeSession(ctx, userID, sessionID)
+	if err != nil {
 		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
-	return c.Status(200).JSON(helper.APIResponse("success", "Logged out successfully", nil))
+	if !revoked {
+		return c.Status(404).JSON(helper.APIResponse("error", "Session not found", nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Session revoked", nil))
 }
 
 func (s *authService) GetProfile(c *fiber.Ctx) error {
-	authData, err := middleware.CheckAuth(c.Get("Authorization"))
+	authData := middleware.CurrentAuth(c)
+	return c.Status(200).JSON(helper.APIResponse("success", "User Profile", authData))
+}
+
+// UnlockUser (Admin) membuka kunci login user dengan me-reset counter gagal username-nya
+func (s *authService) UnlockUser(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
+	id, err := uuid.Parse(c.Params("id"))
 	if err != nil {
-		return c.Status(401).JSON(helper.APIResponse("error", "Unauthorized", nil))
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid user ID", nil))
 	}
-	return c.Status(200).JSON(helper.APIResponse("success", "User Profile", authData))
-}
\ No newline at end of file
+	user, err := s.authRepo.FindByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "User not found", nil))
+	}
+
+	adminID, _ := uuid.Parse(authData.UserID)
+	if err := s.attemptRepo.Record(ctx, models.LoginAttempt{
+		Username:  strings.ToLower(user.Username),
+		UserID:    &adminID, // admin yang membuka kunci
+		IPAddress: c.IP(),
+		UserAgent: c.Get("User-Agent"),
+		Result:    models.LoginAdminUnlock,
+	}); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "User unlocked", nil))
+}
+
+// GetLoginAttempts (Admin) menampilkan audit login terbaru untuk user
+func (s *authService) GetLoginAttempts(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid user ID", nil))
+	}
+	user, err := s.authRepo.FindByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "User not found", nil))
+	}
+
+	attempts, err := s.attemptRepo.FindByUsername(ctx, strings.ToLower(user.Username), 100)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Login attempts", attempts))
+}
+
+// JWKS mempublikasikan public key verifikasi token (tanpa envelope APIResponse, mengikuti RFC 7517)
+func (s *authService) JWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(200).JSON(helper.JWKS())
+}
+
+// truncateRunes memotong s menjadi maksimal n karakter. Dipotong per rune, bukan per byte,
+// agar karakter UTF-8 tidak terbelah (varchar(n) Postgres juga menghitung karakter)
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// deviceFromUserAgent membuat label perangkat sederhana dari User-Agent
+func deviceFromUserAgent(ua string) string {
+	platform := "Unknown device"
+	for _, p := range []struct{ key, name string }{
+		{"Android", "Android"}, {"iPhone", "iPhone"}, {"iPad", "iPad"},
+		{"Windows", "Windows"}, {"Macintosh", "macOS"}, {"Linux", "Linux"},
+	} {
+		if strings.Contains(ua, p.key) {
+			platform = p.name
+			break
+		}
+	}
+	for _, b := range []struct{ key, name string }{
+		{"Edg/", "Edge"}, {"OPR/", "Opera"}, {"Chrome/", "Chrome"}, {"Firefox/", "Firefox"}, {"Safari/", "Safari"},
+	} {
+		if strings.Contains(ua, b.key) {
+			return b.name + " on " + platform
+		}
+	}
+	return platform
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"gouas/app/models"
+	"gouas/app/repository"
+	"gouas/middleware"
+
+	"github.com/google/uuid"
+)
+
+// dataScope adalah cakupan data mahasiswa/prestasi yang boleh dilihat user
+type dataScope int
+
+const (
+	scopeOwn      dataScope = iota // hanya data miliknya sendiri
+	scopeAdvisees                  // mahasiswa bimbingan
+	scopeUnits                     // mahasiswa di unit organisasi UserScope (fakultas/jurusan/prodi)
+	scopeAll                       // semua mahasiswa
+)
+
+// scopeOf menentukan cakupan dari permission, bukan dari nama role
+func scopeOf(authData *middleware.AuthResult) dataScope {
+	switch {
+	case authData.Can(models.PermStudentReadAll) && len(authData.Scopes) > 0:
+		return scopeUnits
+	case authData.Can(models.PermStudentReadAll):
+		return scopeAll
+	case authData.Can(models.PermAchievementVerify):
+		return scopeAdvisees
+	}
+	return scopeOwn
+}
+
+// visibleStudentIDs mengembalikan ID mahasiswa dalam scope; nil berarti tanpa batas (scopeAll)
+func visibleStudentIDs(ctx context.Context, studentRepo repository.StudentRepository, lecturerRepo repository.LecturerRepository, authData *middleware.AuthResult) []uuid.UUID {
+	userID, _ := uuid.Parse(authData.UserID)
+	ids := []uuid.UUID{}
+
+	switch scopeOf(authData) {
+	case scopeAll:
+		return nil
+	case scopeUnits:
+		students, _ := studentRepo.FindByUnits(ctx, authData.Scopes)
+		for _, st := range students {
+			ids = append(ids, st.ID)
+		}
+	case scopeAdvisees:
+		if lecturer, err := lecturerRepo.FindByUserID(ctx, userID); err == nil {
+			advisees, _ := lecturerRepo.FindAdvisees(ctx, lecturer.ID)
+			for _, st := range advisees {
+				ids = append(ids, st.ID)
+			}
+		}
+	case scopeOwn:
+		if student, err := studentRepo.FindByUserID(ctx, userID); err == nil {
+			ids = append(ids, student.ID)
+		}
+	}
+	return ids
+}
+
+// canAccessStudent: scope semua, unit organisasinya, profilnya sendiri, atau mahasiswa bimbingannya
+func canAccessStudent(ctx context.Context, studentRepo repository.StudentRepository, lecturerRepo repository.LecturerRepository, authData *middleware.AuthResult, student *models.Student) bool {
+	switch scopeOf(authData) {
+	case scopeAll:
+		return true
+	case scopeUnits:
+		ok, err := studentRepo.InUnits(ctx, student.ID, authData.Scopes)
+		return err == nil && ok
+	case scopeOwn:
+		return student.UserID.String() == authData.UserID
+	case scopeAdvisees:
+		lecturer, err := lecturerRepo.FindByUserID(ctx, uuid.MustParse(authData.UserID))
+		return err == nil && student.AdvisorID != nil && *student.AdvisorID == lecturer.ID
+	}
+	return false
+}
@@ -22,7 +22,8 @@ func NewLecturerService(repo repository.LecturerRepository) LecturerService {
 }
 
 func (s *lecturerService) GetAll(c *fiber.Ctx) error {
-	lecturers, err := s.repo.FindAll()
+	ctx := c.UserContext()
+	lecturers, err := s.repo.FindAll(ctx)
 	if err != nil {
 		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
@@ -30,8 +31,9 @@ func (s *lecturerService) GetAll(c *fiber.Ctx) error {
 }
 
 func (s *lecturerService) GetAdvisees(c *fiber.Ctx) error {
+	ctx := c.UserContext()
 	id, _ := uuid.Parse(c.Params("id"))
-	students, err := s.repo.FindAdvisees(id)
+	students, err := s.repo.FindAdvisees(ctx, id)
 	if err != nil {
 		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"gouas/app/models"
+	"gouas/app/repository"
+	"gouas/helper"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LoginPolicy mengatur perlindungan brute-force pada login.
+// Setelah BackoffAfter kali gagal, percobaan berikutnya harus menunggu BackoffBase yang
+// berlipat dua tiap kegagalan (maks BackoffMax). Setelah LockThreshold kali gagal,
+// username dikunci selama LockDuration (bisa dibuka admin).
+type LoginPolicy struct {
+	Window         time.Duration // rentang waktu penghitungan gagal
+	BackoffAfter   int           // per username
+	IPBackoffAfter int           // per IP (lebih longgar: satu IP bisa dipakai banyak mahasiswa di lab)
+	BackoffBase    time.Duration
+	BackoffMax     time.Duration
+	LockThreshold  int
+	LockDuration   time.Duration
+}
+
+func DefaultLoginPolicy() LoginPolicy {
+	return LoginPolicy{
+		Window:         time.Hour,
+		BackoffAfter:   3,
+		IPBackoffAfter: 20,
+		BackoffBase:    time.Second,
+		BackoffMax:     5 * time.Minute,
+		LockThreshold:  10,
+		LockDuration:   15 * time.Minute,
+	}
+}
+
+// backoff menghitung jeda wajib setelah `failures` kali gagal
+func (p LoginPolicy) backoff(failures int64, after int) time.Duration {
+	if failures < int64(after) {
+		return 0
+	}
+	delay := p.BackoffBase
+	for i := int64(after); i < failures && delay < p.BackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > p.BackoffMax {
+		delay = p.BackoffMax
+	}
+	return delay
+}
+
+// decide menentukan apakah percobaan ditolak: 423 (lockout username) atau 429 (backoff per
+// IP/username), beserta sisa waktu tunggu. 0 berarti password boleh dicek.
+func (p LoginPolicy) decide(user, ip repository.FailureStats, now time.Time) (int, time.Duration) {
+	if user.Count >= int64(p.LockThreshold) && user.LastFail != nil {
+		if wait := user.LastFail.Add(p.LockDuration).Sub(now); wait > 0 {
+			return fiber.StatusLocked, wait
+		}
+	}
+	for _, check := range []struct {
+		stats repository.FailureStats
+		after int
+	}{{ip, p.IPBackoffAfter}, {user, p.BackoffAfter}} {
+		if check.stats.LastFail == nil {
+			continue
+		}
+		if wait := check.stats.LastFail.Add(p.backoff(check.stats.Count, check.after)).Sub(now); wait > 0 {
+			return fiber.StatusTooManyRequests, wait
+		}
+	}
+	return 0, 0
+}
+
+// loginGuard membatasi tebakan password (login & ganti password) lewat counter login_attempts
+type loginGuard struct {
+	repo   repository.LoginAttemptRepository
+	policy LoginPolicy
+}
+
+// reserve mencatat percobaan sebelum password dicek. Jika status != 0 percobaan ditolak dan
+// password tidak boleh dicek; selain itu panggil complete dengan hasil pengecekan.
+func (g loginGuard) reserve(ctx context.Context, attempt models.LoginAttempt) (models.LoginAttempt, int, time.Duration, error) {
+	var status int
+	var wait time.Duration
+	now := time.Now()
+	reserved, err := g.repo.Reserve(ctx, attempt, now.Add(-g.policy.Window), func(user, ip repository.FailureStats) string {
+		status, wait = g.policy.decide(user, ip, now)
+		switch status {
+		case fiber.StatusLocked:
+			return models.LoginLocked
+		case fiber.StatusTooManyRequests:
+			return models.LoginThrottled
+		}
+		return ""
+	})
+	if err != nil {
+		return reserved, 0, 0, err
+	}
+	return reserved, status, wait, nil
+}
+
+// complete menulis hasil pengecekan password; kegagalan audit tidak menggagalkan request
+func (g loginGuard) complete(ctx context.Context, attempt models.LoginAttempt, result string) {
+	if err := g.repo.Complete(ctx, attempt.ID, result, attempt.UserID); err != nil {
+		log.Printf("[AUTH] failed to record login attempt for %q: %v", attempt.Username, err)
+	}
+	if result == models.LoginInvalid || result == models.LoginInactive {
+		log.Printf("[AUTH] failed password check: username=%q ip=%s", attempt.Username, attempt.IPAddress)
+	}
+}
+
+// checkPassword mengecek password user yang sudah login (ganti password/email) lewat counter
+// yang sama dengan login, agar token curian tidak bisa dipakai menebak password tanpa batas.
+// Jika ok false, respons 400/423/429/500 sudah ditulis dan err adalah hasil c.JSON.
+func (g loginGuard) checkPassword(c *fiber.Ctx, user *models.User, password string) (ok bool, err error) {
+	ctx := c.UserContext()
+	attempt, status, wait, err := g.reserve(ctx, models.LoginAttempt{
+		Username:  strings.ToLower(user.Username),
+		UserID:    &user.ID,
+		IPAddress: c.IP(),
+		UserAgent: c.Get("User-Agent"),
+	})
+	if err != nil {
+		return false, c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if status != 0 {
+		return false, rejection(c, status, wait)
+	}
+	if !helper.CheckPasswordHash(password, user.PasswordHash) {
+		g.complete(ctx, attempt, models.LoginInvalid)
+		return false, c.Status(400).JSON(helper.APIResponse("error", "Current password is incorrect", nil))
+	}
+	g.complete(ctx, attempt, models.LoginSucceeded)
+	return true, nil
+}
+
+// rejection membentuk respons 423/429 dengan header Retry-After
+func rejection(c *fiber.Ctx, status int, wait time.Duration) error {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	if status == fiber.StatusLocked {
+		return c.Status(status).JSON(helper.APIResponse("error", fmt.Sprintf("Account temporarily locked; try again in %s", wait.Round(time.Second)), nil))
+	}
+	return c.Status(status).JSON(helper.APIResponse("error", fmt.Sprintf("Too many failed attempts; try again in %s", wait.Round(time.Second)), nil))
+}
@@ -0,0 +1,432 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gouas/app/models"
+	"gouas/app/repository"
+	"gouas/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// OrganizationService mengelola master data fakultas, jurusan & program studi
+type OrganizationService interface {
+	GetAllFaculties(c *fiber.Ctx) error
+	CreateFaculty(c *fiber.Ctx) error
+	UpdateFaculty(c *fiber.Ctx) error
+	DeleteFaculty(c *fiber.Ctx) error
+
+	GetAllDepartments(c *fiber.Ctx) error
+	CreateDepartment(c *fiber.Ctx) error
+	UpdateDepartment(c *fiber.Ctx) error
+	DeleteDepartment(c *fiber.Ctx) error
+
+	GetAllStudyPrograms(c *fiber.Ctx) error
+	CreateStudyProgram(c *fiber.Ctx) error
+	UpdateStudyProgram(c *fiber.Ctx) error
+	DeleteStudyProgram(c *fiber.Ctx) error
+
+	GetDuplicates(c *fiber.Ctx) error
+	MergeUnits(c *fiber.Ctx) error
+}
+
+type organizationService struct {
+	repo repository.OrganizationRepository
+}
+
+func NewOrganizationService(repo repository.OrganizationRepository) OrganizationService {
+	return &organizationService{repo: repo}
+}
+
+type orgUnitInput struct {
+	Code         string     `json:"code"`
+	Name         string     `json:"name"`
+	Degree       string     `json:"degree"`       // program studi saja
+	FacultyID    *uuid.UUID `json:"facultyId"`    // jurusan saja
+	DepartmentID *uuid.UUID `json:"departmentId"` // program studi saja
+}
+
+// normalizeName merapikan spasi agar "Teknik  Informatika " tidak jadi data baru
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// validate mengecek nama wajib & unik (case-insensitive, dalam parent yang sama) serta parent unit wajib ada
+func (s *organizationService) validate(ctx context.Context, unit string, in *orgUnitInput, excludeID uuid.UUID) (int, error) {
+	in.Name = normalizeName(in.Name)
+	in.Code = strings.ToUpper(strings.TrimSpace(in.Code))
+	if in.Name == "" {
+		return 400, fmt.Errorf("Validation Failed: name is required")
+	}
+
+	var parentUnit string
+	var parentID *uuid.UUID
+	switch unit {
+	case models.UnitDepartment:
+		parentUnit, parentID = models.UnitFaculty, in.FacultyID
+	case models.UnitProgramStudy:
+		parentUnit, parentID = models.UnitDepartment, in.DepartmentID
+	}
+	if parentUnit != "" {
+		if parentID == nil {
+			return 400, fmt.Errorf("Validation Failed: %s is required", parentUnit)
+		}
+		if ok, err := s.repo.UnitExists(ctx, parentUnit, *parentID); err != nil || !ok {
+			return 400, fmt.Errorf("Validation Failed: %s not found", parentUnit)
+		}
+	}
+
+	taken, err := s.repo.NameTaken(ctx, unit, parentID, in.Name, excludeID)
+	if err != nil {
+		return 500, err
+	}
+	if taken {
+		return 409, fmt.Errorf("%s %q already exists", unit, in.Name)
+	}
+	return 0, nil
+}
+
+// ensureUnused menolak penghapusan unit yang masih dipakai
+func (s *organizationService) ensureUnused(ctx context.Context, unit string, id uuid.UUID) (int, error) {
+	count, err := s.repo.CountDependents(ctx, unit, id)
+	if err != nil {
+		return 500, err
+	}
+	if count > 0 {
+		return 409, fmt.Errorf("%s is still referenced by %d records", unit, count)
+	}
+	return 0, nil
+}
+
+// parseOptionalID membaca query parameter UUID opsional (filter list)
+func parseOptionalID(c *fiber.Ctx, key string) (*uuid.UUID, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", key)
+	}
+	return &id, nil
+}
+
+// --- Faculty ---
+
+func (s *organizationService) GetAllFaculties(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	faculties, err := s.repo.FindAllFaculties(ctx)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Faculty list", faculties))
+}
+
+func (s *organizationService) CreateFaculty(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	var input orgUnitInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	if status, err := s.validate(ctx, models.UnitFaculty, &input, uuid.Nil); err != nil {
+		return c.Status(status).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+
+	created, err := s.repo.CreateFaculty(ctx, models.Faculty{Code: input.Code, Name: input.Name})
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(201).JSON(helper.APIResponse("success", "Faculty created", created))
+}
+
+func (s *organizationService) UpdateFaculty(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	faculty, err := s.repo.FindFacultyByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Faculty not found", nil))
+	}
+	var input orgUnitInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	if status, err := s.validate(ctx, models.UnitFaculty, &input, faculty.ID); err != nil {
+		return c.Status(status).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+
+	faculty.Code, faculty.Name = input.Code, input.Name
+	if err := s.repo.UpdateFaculty(ctx, *faculty); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Faculty updated", faculty))
+}
+
+func (s *organizationService) DeleteFaculty(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	if _, err := s.repo.FindFacultyByID(ctx, id); err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Faculty not found", nil))
+	}
+	if status, err := s.ensureUnused(ctx, models.UnitFaculty, id); err != nil {
+		return c.Status(status).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if err := s.repo.DeleteFaculty(ctx, id); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Faculty deleted", nil))
+}
+
+// --- Department ---
+
+func (s *organizationService) GetAllDepartments(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	facultyID, err := parseOptionalID(c, "facultyId")
+	if err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	depts, err := s.repo.FindAllDepartments(ctx, facultyID)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Department list", depts))
+}
+
+func (s *organizationService) CreateDepartment(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	var input orgUnitInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	if status, err := s.validate(ctx, models.UnitDepartment, &input, uuid.Nil); err != nil {
+		return c.Status(status).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+
+	created, err := s.repo.CreateDepartment(ctx, models.Department{Code: input.Code, Name: input.Name, FacultyID: input.FacultyID})
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(201).JSON(helper.APIResponse("success", "Department created", created))
+}
+
+func (s *organizationService) UpdateDepartment(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	dept, err := s.repo.FindDepartmentByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Department not found", nil))
+	}
+	var input orgUnitInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	if status, err := s.validate(ctx, models.UnitDepartment, &input, dept.ID); err != nil {
+		return c.Status(status).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+
+	dept.Code, dept.Name, dept.FacultyID, dept.Faculty = input.Code, input.Name, input.FacultyID, nil
+	if err := s.repo.UpdateDepartment(ctx, *dept); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Department updated", dept))
+}
+
+func (s *organizationService) DeleteDepartment(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	if _, err := s.repo.FindDepartmentByID(ctx, id); err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Department not found", nil))
+	}
+	if status, err := s.ensureUnused(ctx, models.UnitDepartment, id); err != nil {
+		return c.Status(status).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if err := s.repo.DeleteDepartment(ctx, id); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Department deleted", nil))
+}
+
+// --- Study Program ---
+
+func (s *organizationService) GetAllStudyPrograms(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	departmentID, err := parseOptionalID(c, "departmentId")
+	if err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	programs, err := s.repo.FindAllStudyPrograms(ctx, departmentID)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Study program list", programs))
+}
+
+func (s *organizationService) CreateStudyProgram(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	var input orgUnitInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	if status, err := s.validate(ctx, models.UnitProgramStudy, &input, uuid.Nil); err != nil {
+		return c.Status(status).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+
+	created, err := s.repo.CreateStudyProgram(ctx, models.StudyProgram{
+		Code:         input.Code,
+		Name:         input.Name,
+		Degree:       strings.ToUpper(strings.TrimSpace(input.Degree)),
+		DepartmentID: input.DepartmentID,
+	})
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(201).JSON(helper.APIResponse("success", "Study program created", created))
+}
+
+func (s *organizationService) UpdateStudyProgram(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	program, err := s.repo.FindStudyProgramByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Study program not found", nil))
+	}
+	var input orgUnitInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	if status, err := s.validate(ctx, models.UnitProgramStudy, &input, program.ID); err != nil {
+		return c.Status(status).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+
+	program.Code, program.Name = input.Code, input.Name
+	program.Degree = strings.ToUpper(strings.TrimSpace(input.Degree))
+	program.DepartmentID, program.Department = input.DepartmentID, nil
+	if err := s.repo.UpdateStudyProgram(ctx, *program); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Study program updated", program))
+}
+
+func (s *organizationService) DeleteStudyProgram(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	if _, err := s.repo.FindStudyProgramByID(ctx, id); err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Study program not found", nil))
+	}
+	if status, err := s.ensureUnused(ctx, models.UnitProgramStudy, id); err != nil {
+		return c.Status(status).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if err := s.repo.DeleteStudyProgram(ctx, id); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Study program deleted", nil))
+}
+
+// --- Duplikat & Merge ---
+
+// duplicateUnit adalah satu unit dalam kandidat duplikat beserta jumlah rujukannya
+type duplicateUnit struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	References int64     `json:"references"`
+}
+
+// duplicateCandidate: source (rujukan lebih sedikit) disarankan digabung ke target
+type duplicateCandidate struct {
+	Unit     string        `json:"unit"`
+	Source   duplicateUnit `json:"source"`
+	Target   duplicateUnit `json:"target"`
+	Distance int           `json:"distance"`
+}
+
+// GetDuplicates (Admin) menampilkan laporan unit dengan nama mirip untuk direview sebelum di-merge.
+// ?unit=faculty|department|program_study membatasi jenis unit.
+func (s *organizationService) GetDuplicates(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	units := []string{models.UnitFaculty, models.UnitDepartment, models.UnitProgramStudy}
+	if unit := c.Query("unit"); unit != "" {
+		if !models.IsValidUnit(unit) {
+			return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: unit must be faculty, department or program_study", nil))
+		}
+		units = []string{unit}
+	}
+
+	candidates := []duplicateCandidate{}
+	for _, unit := range units {
+		names, err := s.repo.FindUnitNames(ctx, unit)
+		if err != nil {
+			return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+		}
+		refs := map[uuid.UUID]int64{}
+		countRefs := func(id uuid.UUID) (int64, error) {
+			if n, ok := refs[id]; ok {
+				return n, nil
+			}
+			n, err := s.repo.CountDependents(ctx, unit, id)
+			refs[id] = n
+			return n, err
+		}
+		labels := make([]string, len(names))
+		for i, n := range names {
+			labels[i] = n.Name
+		}
+		for _, pair := range helper.SimilarNames(labels) {
+			a, b := names[pair[0]], names[pair[1]]
+			refA, err := countRefs(a.ID)
+			if err != nil {
+				return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+			}
+			refB, err := countRefs(b.ID)
+			if err != nil {
+				return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+			}
+			source, target := duplicateUnit{a.ID, a.Name, refA}, duplicateUnit{b.ID, b.Name, refB}
+			if refA > refB {
+				source, target = target, source
+			}
+			candidates = append(candidates, duplicateCandidate{
+				Unit:     unit,
+				Source:   source,
+				Target:   target,
+				Distance: helper.EditDistance(helper.NameKey(a.Name), helper.NameKey(b.Name)),
+			})
+		}
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Possible duplicate units", candidates))
+}
+
+// MergeUnits (Admin) menggabungkan unit salah ketik ke unit yang benar.
+// Body: {"unit": "program_study", "sourceId": "...", "targetId": "..."}; source dihapus.
+func (s *organizationService) MergeUnits(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	var input struct {
+		Unit     string    `json:"unit"`
+		SourceID uuid.UUID `json:"sourceId"`
+		TargetID uuid.UUID `json:"targetId"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	if !models.IsValidUnit(input.Unit) {
+		return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: unit must be faculty, department or program_study", nil))
+	}
+	if input.SourceID == input.TargetID {
+		return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: sourceId and targetId must differ", nil))
+	}
+	for _, id := range []uuid.UUID{input.SourceID, input.TargetID} {
+		if ok, err := s.repo.UnitExists(ctx, input.Unit, id); err != nil {
+			return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+		} else if !ok {
+			return c.Status(404).JSON(helper.APIResponse("error", fmt.Sprintf("%s %s not found", input.Unit, id), nil))
+		}
+	}
+
+	moved, err := s.repo.MergeUnits(ctx, input.Unit, input.SourceID, input.TargetID)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", fmt.Sprintf("%s merged; %d references moved", input.Unit, moved), nil))
+}
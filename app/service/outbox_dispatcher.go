@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"gouas/app/models"
+	"gouas/app/repository"
+	"gouas/helper"
+)
+
+const (
+	outboxBatchSize   = 50
+	outboxLease       = 2 * time.Minute
+	outboxMaxAttempts = 10
+	outboxBaseBackoff = 10 * time.Second
+	outboxMaxBackoff  = time.Hour
+)
+
+// OutboxDispatcher memproses event outbox. Setiap handler harus idempotent karena
+// event bisa dijalankan ulang (retry atau worker mati setelah handler sukses).
+type OutboxDispatcher interface {
+	// DispatchOnce memproses satu batch dan mengembalikan jumlah event yang berhasil
+	DispatchOnce(ctx context.Context) (int, error)
+	// Start menjalankan DispatchOnce berkala di goroutine background
+	Start(interval time.Duration)
+}
+
+type outboxDispatcher struct {
+	repo      repository.OutboxRepository
+	pointRepo repository.PointRepository
+	achRepo   repository.AchievementRepository
+	notifier  helper.Notifier
+	now       func() time.Time
+}
+
+func NewOutboxDispatcher(repo repository.OutboxRepository, pointRepo repository.PointRepository, achRepo repository.AchievementRepository, notifier helper.Notifier) OutboxDispatcher {
+	return &outboxDispatcher{repo: repo, pointRepo: pointRepo, achRepo: achRepo, notifier: notifier, now: time.Now}
+}
+
+func (d *outboxDispatcher) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			// Kosongkan antrean sebelum menunggu tick berikutnya
+			for {
+				// Batch harus selesai sebelum lease habis, agar tidak diproses ganda oleh worker lain
+				ctx, cancel := context.WithTimeout(context.Background(), outboxLease)
+				n, err := d.DispatchOnce(ctx)
+				cancel()
+				if err != nil {
+					log.Println("[OUTBOX] dispatch failed:", err)
+					break
+				}
+				if n < outboxBatchSize {
+					break
+				}
+			}
+		}
+	}()
+	log.Printf("[OUTBOX] dispatcher started (interval %s)", interval)
+}
+
+func (d *outboxDispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	events, err := d.repo.Claim(ctx, outboxBatchSize, outboxLease)
+	if err != nil {
+		return 0, err
+	}
+
+	done := 0
+	for _, event := range events {
+		if err := d.handle(ctx, event); err != nil {
+			next := d.nextAttempt(event.Attempts)
+			if next == nil {
+				// Perlu tindakan admin: GET /outbox/failed lalu POST /outbox/:id/retry
+				log.Printf("[OUTBOX][ALERT] event %s (%s) gave up after %d attempts: %v", event.ID, event.Kind, event.Attempts, err)
+			}
+			if mErr := d.repo.MarkRetry(ctx, event.ID, err.Error(), next); mErr != nil {
+				return done, mErr
+			}
+			continue
+		}
+		if err := d.repo.MarkDone(ctx, event.ID); err != nil {
+			return done, err
+		}
+		done++
+	}
+	return done, nil
+}
+
+// nextAttempt menghitung backoff eksponensial; nil jika batas percobaan habis
+func (d *outboxDispatcher) nextAttempt(attempts int) *time.Time {
+	if attempts >= outboxMaxAttempts {
+		return nil
+	}
+	backoff := outboxBaseBackoff
+	for i := 1; i < attempts && backoff < outboxMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > outboxMaxBackoff {
+		backoff = outboxMaxBackoff
+	}
+	next := d.now().Add(backoff)
+	return &next
+}
+
+func (d *outboxDispatcher) handle(ctx context.Context, event models.OutboxEvent) error {
+	switch event.Kind {
+	case models.OutboxAwardPoints:
+		var p models.AwardPointsPayload
+		if err := json.Unmarshal(event.Payload, &p); err != nil {
+			return err
+		}
+		// Idempotent: ledger menolak award kedua untuk achievement yang sama
+		return d.pointRepo.Award(ctx, p.StudentID, p.AchievementRefID, p.Amount, models.StatusActor{UserID: p.ActorID, Role: p.ActorRole})
+	case models.OutboxNotify:
+		var p models.NotifyPayload
+		if err := json.Unmarshal(event.Payload, &p); err != nil {
+			return err
+		}
+		return d.notifier.Notify(p.UserID, p.Subject, p.Message)
+	case models.OutboxSyncMongo:
+		var p models.SyncMongoPayload
+		if err := json.Unmarshal(event.Payload, &p); err != nil {
+			return err
+		}
+		return d.achRepo.SyncMongoStatus(ctx, p.MongoID, p.Status, p.PointsAwarded)
+	default:
+		return fmt.Errorf("unknown outbox event kind %q", event.Kind)
+	}
+}
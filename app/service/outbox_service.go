@@ -0,0 +1,49 @@
+package service
+
+import (
+	"gouas/app/repository"
+	"gouas/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// OutboxService (Admin) menampilkan event outbox yang gagal dan mengantrekan ulang
+type OutboxService interface {
+	GetFailed(c *fiber.Ctx) error
+	Retry(c *fiber.Ctx) error
+}
+
+type outboxService struct {
+	repo repository.OutboxRepository
+}
+
+func NewOutboxService(repo repository.OutboxRepository) OutboxService {
+	return &outboxService{repo}
+}
+
+func (s *outboxService) GetFailed(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	events, err := s.repo.FindFailed(ctx, 100)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Failed outbox events", events))
+}
+
+// Retry mengembalikan event failed ke antrean; handler dispatcher idempotent sehingga aman dijalankan ulang
+func (s *outboxService) Retry(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid event ID", nil))
+	}
+	requeued, err := s.repo.Requeue(ctx, id)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if !requeued {
+		return c.Status(404).JSON(helper.APIResponse("error", "Failed event not found", nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Event requeued", nil))
+}
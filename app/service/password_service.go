@@ -0,0 +1,166 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"time"
+
+	"gouas/app/models"
+	"gouas/app/repository"
+	"gouas/helper"
+	"gouas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// PasswordService: ganti password, lupa password & reset lewat token email
+type PasswordService interface {
+	ChangePassword(c *fiber.Ctx) error
+	ForgotPassword(c *fiber.Ctx) error
+	ResetPassword(c *fiber.Ctx) error
+}
+
+type passwordService struct {
+	authRepo repository.AuthRepository
+	guard    loginGuard
+	mailer   helper.Mailer
+	resetTTL time.Duration
+	resetURL string // link di email = resetURL + token
+}
+
+// Batas permintaan reset per user agar inbox tidak dibanjiri
+const (
+	resetRequestWindow = 15 * time.Minute
+	resetRequestLimit  = 3
+)
+
+func NewPasswordService(authRepo repository.AuthRepository, attemptRepo repository.LoginAttemptRepository, policy LoginPolicy, mailer helper.Mailer, resetTTL time.Duration, resetURL string) PasswordService {
+	return &passwordService{authRepo, loginGuard{attemptRepo, policy}, mailer, resetTTL, resetURL}
+}
+
+// ChangePassword mengganti password user yang login. Session lain ikut dicabut.
+func (s *passwordService) ChangePassword(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
+	userID, _ := uuid.Parse(authData.UserID)
+	sessionID, _ := uuid.Parse(authData.SessionID)
+	var input struct {
+		CurrentPassword string `json:"currentPassword"`
+		NewPassword     string `json:"newPassword"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+
+	user, err := s.authRepo.FindByID(ctx, userID)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "User not found", nil))
+	}
+	if ok, err := s.guard.checkPassword(c, user, input.CurrentPassword); !ok {
+		return err
+	}
+	if input.NewPassword == input.CurrentPassword {
+		return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: new password must differ from the current password", nil))
+	}
+	if err := helper.ValidatePassword(input.NewPassword, user.Username); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: "+err.Error(), nil))
+	}
+
+	hash, err := helper.HashPassword(input.NewPassword)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if err := s.authRepo.UpdatePassword(ctx, user.ID, hash, sessionID); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Password changed; other sessions have been logged out", nil))
+}
+
+// ForgotPassword mengirim token reset ke email user. Respons selalu sama agar tidak
+// membocorkan email mana yang terdaftar.
+func (s *passwordService) ForgotPassword(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	var input struct {
+		Email string `json:"email"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	done := func() error {
+		return c.Status(200).JSON(helper.APIResponse("success", "If the email is registered, a reset link has been sent", nil))
+	}
+
+	user, err := s.authRepo.FindByEmail(ctx, input.Email)
+	if err != nil || !user.IsActive {
+		return done()
+	}
+	recent, err := s.authRepo.CountResetTokensSince(ctx, user.ID, time.Now().Add(-resetRequestWindow))
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if recent >= resetRequestLimit {
+		return done()
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	if err := s.authRepo.CreateResetToken(ctx, models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: helper.HashToken(token),
+		RequestIP: c.IP(),
+		ExpiresAt: time.Now().Add(s.resetTTL),
+	}); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+
+	// Email dikirim di background (bukan lewat outbox) agar token mentah tidak tersimpan di DB
+	// dan respons tidak menunggu SMTP
+	go func(email, name string) {
+		body := fmt.Sprintf("Halo %s,\n\nGunakan link berikut untuk mengatur ulang password Anda (berlaku %s, sekali pakai):\n%s%s\n\nAbaikan email ini jika Anda tidak meminta reset password.",
+			name, s.resetTTL, s.resetURL, token)
+		if err := s.mailer.Send(email, "Reset password", body); err != nil {
+			log.Printf("[AUTH] failed to send reset email to user %s: %v", user.ID, err)
+		}
+	}(user.Email, user.FullName)
+
+	return done()
+}
+
+// ResetPassword mengganti password memakai token dari email. Semua session dicabut.
+func (s *passwordService) ResetPassword(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	var input struct {
+		Token       string `json:"token"`
+		NewPassword string `json:"newPassword"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+
+	token, err := s.authRepo.FindResetToken(ctx, helper.HashToken(input.Token))
+	if err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid or expired reset token", nil))
+	}
+	if err := helper.ValidatePassword(input.NewPassword, token.User.Username); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: "+err.Error(), nil))
+	}
+
+	hash, err := helper.HashPassword(input.NewPassword)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	consumed, err := s.authRepo.ResetPassword(ctx, token.ID, hash)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if !consumed {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid or expired reset token", nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Password has been reset; please log in again", nil))
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"gouas/app/models"
+)
+
+// ResolvePoints memilih rule aktif yang cocok dengan prestasi: Priority tertinggi menang,
+// jika Priority sama dipilih yang paling spesifik (kriteria terbanyak). Rule default
+// Priority 0, jadi tanpa pengaturan admin yang berlaku adalah rule paling spesifik.
+// Jika tidak ada rule yang cocok, poin yang diajukan mahasiswa yang dipakai.
+func ResolvePoints(rules []models.PointRule, achievement models.Achievement, at time.Time) (int, *models.PointRule) {
+	var best *models.PointRule
+	bestScore := -1
+
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.IsActive || !ruleValidAt(rule, at) {
+			continue
+		}
+		score, ok := matchRule(rule, achievement)
+		if !ok {
+			continue
+		}
+		if best == nil || rule.Priority > best.Priority || (rule.Priority == best.Priority && score > bestScore) {
+			best = rule
+			bestScore = score
+		}
+	}
+
+	if best == nil {
+		return achievement.Points, nil
+	}
+	return best.Points, best
+}
+
+// matchRule mengembalikan jumlah kriteria yang cocok (spesifisitas)
+func matchRule(rule *models.PointRule, achievement models.Achievement) (int, bool) {
+	d := achievement.Details
+	score := 0
+
+	criteria := []struct {
+		want string
+		got  string
+	}{
+		{rule.AchievementType, achievement.AchievementType},
+		{rule.CompetitionLevel, d.CompetitionLevel},
+		{rule.MedalType, d.MedalType},
+		{rule.PublicationType, d.PublicationType},
+		{rule.Position, d.Position},
+	}
+	for _, c := range criteria {
+		if c.want == "" {
+			continue
+		}
+		if !strings.EqualFold(c.want, c.got) {
+			return 0, false
+		}
+		score++
+	}
+
+	if rule.Rank != 0 {
+		if rule.Rank != d.Rank {
+			return 0, false
+		}
+		score++
+	}
+	return score, true
+}
+
+func ruleValidAt(rule *models.PointRule, at time.Time) bool {
+	if rule.ValidFrom != nil && at.Before(*rule.ValidFrom) {
+		return false
+	}
+	if rule.ValidUntil != nil && at.After(*rule.ValidUntil) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"gouas/app/models"
+	"gouas/app/repository"
+	"gouas/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PointRuleService interface {
+	GetAll(c *fiber.Ctx) error
+	Create(c *fiber.Ctx) error
+	Update(c *fiber.Ctx) error
+	DryRun(c *fiber.Ctx) error
+}
+
+type pointRuleService struct {
+	repo    repository.PointRuleRepository
+	achRepo repository.AchievementRepository
+}
+
+func NewPointRuleService(repo repository.PointRuleRepository, achRepo repository.AchievementRepository) PointRuleService {
+	return &pointRuleService{repo: repo, achRepo: achRepo}
+}
+
+type pointRuleInput struct {
+	Name             string     `json:"name"`
+	AchievementType  string     `json:"achievementType"`
+	CompetitionLevel string     `json:"competitionLevel"`
+	Rank             int        `json:"rank"`
+	MedalType        string     `json:"medalType"`
+	PublicationType  string     `json:"publicationType"`
+	Position         string     `json:"position"`
+	Points           int        `json:"points"`
+	Priority         int        `json:"priority"`
+	IsActive         *bool      `json:"isActive"`
+	ValidFrom        *time.Time `json:"validFrom"`
+	ValidUntil       *time.Time `json:"validUntil"`
+}
+
+func (in pointRuleInput) validate() error {
+	if in.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if in.Points < 0 {
+		return fmt.Errorf("points must not be negative")
+	}
+	if in.Rank < 0 {
+		return fmt.Errorf("rank must not be negative")
+	}
+	if in.ValidFrom != nil && in.ValidUntil != nil && in.ValidUntil.Before(*in.ValidFrom) {
+		return fmt.Errorf("validUntil must be after validFrom")
+	}
+	return nil
+}
+
+func (in pointRuleInput) apply(rule *models.PointRule) {
+	rule.Name = in.Name
+	rule.AchievementType = in.AchievementType
+	rule.CompetitionLevel = in.CompetitionLevel
+	rule.Rank = in.Rank
+	rule.MedalType = in.MedalType
+	rule.PublicationType = in.PublicationType
+	rule.Position = in.Position
+	rule.Points = in.Points
+	rule.Priority = in.Priority
+	rule.ValidFrom = in.ValidFrom
+	rule.ValidUntil = in.ValidUntil
+	if in.IsActive != nil {
+		rule.IsActive = *in.IsActive
+	}
+}
+
+func (s *pointRuleService) GetAll(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	rules, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Point rules retrieved", rules))
+}
+
+func (s *pointRuleService) Create(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	var input pointRuleInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	if err := input.validate(); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: "+err.Error(), nil))
+	}
+
+	rule := models.PointRule{IsActive: true}
+	input.apply(&rule)
+
+	created, err := s.repo.Create(ctx, rule)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(201).JSON(helper.APIResponse("success", "Point rule created", created))
+}
+
+func (s *pointRuleService) Update(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	rule, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Point rule not found", nil))
+	}
+
+	var input pointRuleInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	if err := input.validate(); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: "+err.Error(), nil))
+	}
+	input.apply(rule)
+
+	if err := s.repo.Update(ctx, *rule); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Point rule updated", rule))
+}
+
+// DryRun menghitung poin tanpa menyimpan apapun.
+// Body: {"achievementId": "..."} untuk prestasi yang sudah ada, atau data prestasi langsung.
+func (s *pointRuleService) DryRun(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	var input struct {
+		AchievementID string `json:"achievementId"`
+		models.Achievement
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+
+	achievement := input.Achievement
+	if input.AchievementID != "" {
+		id, err := uuid.Parse(input.AchievementID)
+		if err != nil {
+			return c.Status(400).JSON(helper.APIResponse("error", "Invalid achievementId", nil))
+		}
+		ref, err := s.achRepo.FindReferenceByID(ctx, id)
+		if err != nil {
+			return c.Status(404).JSON(helper.APIResponse("error", "Achievement not found", nil))
+		}
+		detail, err := s.achRepo.GetMongoDetail(ctx, ref.MongoAchievementID)
+		if err != nil {
+			return c.Status(500).JSON(helper.APIResponse("error", "could not fetch achievement details from mongo", nil))
+		}
+		achievement = *detail
+	}
+
+	rules, err := s.repo.FindActive(ctx)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+
+	points, rule := ResolvePoints(rules, achievement, time.Now())
+	source := "rule"
+	if rule == nil {
+		source = "claimed"
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Dry run result", fiber.Map{
+		"points":       points,
+		"source":       source,
+		"matched_rule": rule,
+	}))
+}
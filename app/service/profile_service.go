@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gouas/app/models"
+	"gouas/app/repository"
+	"gouas/helper"
+	"gouas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProfileService mengelola profile Mahasiswa/Dosen Wali (admin) & kontak user (self-service)
+type ProfileService interface {
+	CreateStudent(c *fiber.Ctx) error
+	UpdateStudent(c *fiber.Ctx) error
+	DeleteStudent(c *fiber.Ctx) error
+
+	CreateLecturer(c *fiber.Ctx) error
+	UpdateLecturer(c *fiber.Ctx) error
+	DeleteLecturer(c *fiber.Ctx) error
+
+	UpdateContact(c *fiber.Ctx) error
+}
+
+type profileService struct {
+	profileRepo  repository.ProfileRepository
+	studentRepo  repository.StudentRepository
+	lecturerRepo repository.LecturerRepository
+	adminRepo    repository.AdminRepository
+	orgRepo      repository.OrganizationRepository
+	guard        loginGuard // cek password saat ganti email
+}
+
+func NewProfileService(profileRepo repository.ProfileRepository, studentRepo repository.StudentRepository, lecturerRepo repository.LecturerRepository, adminRepo repository.AdminRepository, orgRepo repository.OrganizationRepository, attemptRepo repository.LoginAttemptRepository, policy LoginPolicy) ProfileService {
+	return &profileService{profileRepo, studentRepo, lecturerRepo, adminRepo, orgRepo, loginGuard{attemptRepo, policy}}
+}
+
+type studentProfileInput struct {
+	UserID uuid.UUID `json:"userId"` // create saja
+	profileInput
+}
+
+type lecturerProfileInput struct {
+	UserID uuid.UUID `json:"userId"` // create saja
+	profileInput
+}
+
+// validateStudent: format NIM, unit organisasi & angkatan, lalu NIM unik
+func (s *profileService) validateStudent(ctx context.Context, in *studentProfileInput, excludeID uuid.UUID) (int, error) {
+	if strings.TrimSpace(in.NIM) == "" {
+		return 400, fmt.Errorf("Validation Failed: nim is required")
+	}
+	if err := in.profileInput.validate(ctx, s.orgRepo); err != nil {
+		return 400, fmt.Errorf("Validation Failed: %v", err)
+	}
+	taken, err := s.profileRepo.NIMTaken(ctx, in.NIM, excludeID)
+	if err != nil {
+		return 500, err
+	}
+	if taken {
+		return 409, fmt.Errorf("NIM %s is already used by another student", in.NIM)
+	}
+	return 0, nil
+}
+
+// validateLecturer: format NIP, jurusan, lalu NIP unik
+func (s *profileService) validateLecturer(ctx context.Context, in *lecturerProfileInput, excludeID uuid.UUID) (int, error) {
+	if strings.TrimSpace(in.NIP) == "" {
+		return 400, fmt.Errorf("Validation Failed: nip is required")
+	}
+	if err := in.profileInput.validate(ctx, s.orgRepo); err != nil {
+		return 400, fmt.Errorf("Validation Failed: %v", err)
+	}
+	taken, err := s.profileRepo.NIPTaken(ctx, in.NIP, excludeID)
+	if err != nil {
+		return 500, err
+	}
+	if taken {
+		return 409, fmt.Errorf("NIP %s is already used by another lecturer", in.NIP)
+	}
+	return 0, nil
+}
+
+// ensureProfileOwner memastikan user ada, ber-role sesuai & belum punya profile
+func (s *profileService) ensureProfileOwner(ctx context.Context, userID uuid.UUID, roleName string) (int, error) {
+	user, err := s.adminRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return 404, fmt.Errorf("User not found")
+	}
+	if user.Role.Name != roleName {
+		return 400, fmt.Errorf("Validation Failed: user role must be %s", roleName)
+	}
+
+	switch roleName {
+	case "Mahasiswa":
+		_, err = s.studentRepo.FindByUserID(ctx, userID)
+	case "Dosen Wali":
+		_, err = s.lecturerRepo.FindByUserID(ctx, userID)
+	}
+	if err == nil {
+		return 409, fmt.Errorf("User already has a %s profile", roleName)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 500, err
+	}
+	return 0, nil
+}
+
+// --- Student ---
+
+func (s *profileService) CreateStudent(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	var input studentProfileInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	if status, err := s.ensureProfileOwner(ctx, input.UserID, "Mahasiswa"); err != nil {
+		return c.Status(status).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if status, err := s.validateStudent(ctx, &input, uuid.Nil); err != nil {
+		return c.Status(status).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+
+	created, err := s.profileRepo.CreateStudent(ctx, models.Student{
+		UserID:         input.UserID,
+		NIM:            input.NIM,
+		StudyProgramID: input.StudyProgramID,
+		AcademicYear:   input.AcademicYear,
+	})
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(201).JSON(helper.APIResponse("success", "Student profile created", created))
+}
+
+// UpdateStudent: field kosong dibiarkan seperti semula (NIM placeholder tetap bisa dipertahankan sementara)
+func (s *profileService) UpdateStudent(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	var input studentProfileInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+
+	student, err := s.studentRepo.FindByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Student not found", nil))
+	}
+	if input.AcademicYear == "" {
+		input.AcademicYear = student.AcademicYear
+	}
+	if input.StudyProgramID == nil {
+		input.StudyProgramID = student.StudyProgramID
+	}
+
+	if input.NIM == "" {
+		if err := input.profileInput.validate(ctx, s.orgRepo); err != nil {
+			return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: "+err.Error(), nil))
+		}
+		input.NIM = student.NIM
+	} else if status, err := s.validateStudent(ctx, &input, student.ID); err != nil {
+		return c.Status(status).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+
+	student.NIM = input.NIM
+	student.StudyProgramID = input.StudyProgramID
+	student.AcademicYear = input.AcademicYear
+	if err := s.profileRepo.UpdateStudent(ctx, *student); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Student profile updated", nil))
+}
+
+// DeleteStudent menolak profile yang sudah punya prestasi/poin agar histori tidak hilang
+func (s *profileService) DeleteStudent(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	student, err := s.studentRepo.FindByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Student not found", nil))
+	}
+
+	count, err := s.profileRepo.CountStudentRecords(ctx, student.ID)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if count > 0 {
+		return c.Status(409).JSON(helper.APIResponse("error", fmt.Sprintf("Student still has %d achievement/point records", count), nil))
+	}
+
+	if err := s.profileRepo.DeleteStudent(ctx, student.ID); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Student profile deleted", nil))
+}
+
+// --- Lecturer ---
+
+func (s *profileService) CreateLecturer(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	var input lecturerProfileInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	if status, err := s.ensureProfileOwner(ctx, input.UserID, "Dosen Wali"); err != nil {
+		return c.Status(status).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if status, err := s.validateLecturer(ctx, &input, uuid.Nil); err != nil {
+		return c.Status(status).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+
+	created, err := s.profileRepo.CreateLecturer(ctx, models.Lecturer{
+		UserID:       input.UserID,
+		NIP:          input.NIP,
+		DepartmentID: input.DepartmentID,
+	})
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(201).JSON(helper.APIResponse("success", "Lecturer profile created", created))
+}
+
+func (s *profileService) UpdateLecturer(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	var input lecturerProfileInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+
+	lecturer, err := s.lecturerRepo.FindByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Lecturer not found", nil))
+	}
+	if input.DepartmentID == nil {
+		input.DepartmentID = lecturer.DepartmentID
+	}
+
+	if input.NIP == "" {
+		if err := input.profileInput.validate(ctx, s.orgRepo); err != nil {
+			return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: "+err.Error(), nil))
+		}
+		input.NIP = lecturer.NIP
+	} else if status, err := s.validateLecturer(ctx, &input, lecturer.ID); err != nil {
+		return c.Status(status).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+
+	lecturer.NIP = input.NIP
+	lecturer.DepartmentID = input.DepartmentID
+	if err := s.profileRepo.UpdateLecturer(ctx, *lecturer); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Lecturer profile updated", nil))
+}
+
+// DeleteLecturer menolak dosen yang masih punya mahasiswa bimbingan
+func (s *profileService) DeleteLecturer(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	lecturer, err := s.lecturerRepo.FindByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Lecturer not found", nil))
+	}
+
+	advisees, err := s.lecturerRepo.FindAdvisees(ctx, lecturer.ID)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if len(advisees) > 0 {
+		return c.Status(409).JSON(helper.APIResponse("error", fmt.Sprintf("Lecturer still advises %d students", len(advisees)), nil))
+	}
+
+	if err := s.profileRepo.DeleteLecturer(ctx, lecturer.ID); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Lecturer profile deleted", nil))
+}
+
+// --- Self-service ---
+
+// UpdateContact mengubah email/no. HP user yang login. Field yang tidak dikirim tidak diubah,
+// phone "" menghapus nomor HP.
+func (s *profileService) UpdateContact(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
+	userID := uuid.MustParse(authData.UserID)
+
+	var input struct {
+		Email           *string `json:"email"`
+		Phone           *string `json:"phone"`
+		CurrentPassword string  `json:"currentPassword"` // wajib jika email diganti
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+
+	user, err := s.adminRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "User not found", nil))
+	}
+	email, phone := user.Email, user.Phone
+
+	v := &ValidationError{}
+	if input.Email != nil {
+		email = strings.TrimSpace(*input.Email)
+		if err := ValidateEmail(email); err != nil {
+			v.add("email", err.Error())
+		}
+	}
+	if input.Phone != nil {
+		phone = ""
+		if strings.TrimSpace(*input.Phone) != "" {
+			if phone, err = NormalizePhone(*input.Phone); err != nil {
+				v.add("phone", err.Error())
+			}
+		}
+	}
+	if len(v.Fields) > 0 {
+		return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed", v.Fields))
+	}
+
+	if !strings.EqualFold(email, user.Email) {
+		// Email dipakai untuk reset password: token yang dicuri saja tidak boleh cukup untuk menggantinya
+		if input.CurrentPassword == "" {
+			return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed", map[string]string{"currentPassword": "current password is required to change the email"}))
+		}
+		if ok, err := s.guard.checkPassword(c, user, input.CurrentPassword); !ok {
+			return err
+		}
+		taken, err := s.profileRepo.EmailTaken(ctx, email, user.ID)
+		if err != nil {
+			return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+		}
+		if taken {
+			return c.Status(409).JSON(helper.APIResponse("error", "Email is already used by another user", nil))
+		}
+	}
+
+	if err := s.profileRepo.UpdateContact(ctx, user.ID, email, phone); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Contact updated", fiber.Map{"email": email, "phone": phone}))
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	nimPattern   = regexp.MustCompile(`^\d{8,15}$`)
+	nipPattern   = regexp.MustCompile(`^\d{18}$`)
+	phonePattern = regexp.MustCompile(`^\+628\d{7,11}$`)
+)
+
+// ValidateNIM: NIM hanya berisi angka, 8-15 digit (format berbeda per kampus)
+func ValidateNIM(nim string) error {
+	if !nimPattern.MatchString(nim) {
+		return fmt.Errorf("must be 8-15 digits")
+	}
+	return nil
+}
+
+// ValidateNIP memvalidasi NIP 18 digit:
+// tanggal lahir (YYYYMMDD) + TMT pengangkatan (YYYYMM) + jenis kelamin (1/2) + nomor urut (3 digit)
+func ValidateNIP(nip string) error {
+	if !nipPattern.MatchString(nip) {
+		return fmt.Errorf("must be 18 digits")
+	}
+	birth, err := time.Parse("20060102", nip[0:8])
+	if err != nil {
+		return fmt.Errorf("digits 1-8 must be a valid birth date (YYYYMMDD)")
+	}
+	appointed, err := time.Parse("200601", nip[8:14])
+	if err != nil || !appointed.After(birth) {
+		return fmt.Errorf("digits 9-14 must be a valid appointment month (YYYYMM) after the birth date")
+	}
+	if nip[14] != '1' && nip[14] != '2' {
+		return fmt.Errorf("digit 15 must be 1 (male) or 2 (female)")
+	}
+	if nip[15:] == "000" {
+		return fmt.Errorf("digits 16-18 must be a sequence number starting at 001")
+	}
+	return nil
+}
+
+// NormalizePhone mengubah nomor HP Indonesia (08xx / 628xx / +628xx) ke format +628xx
+func NormalizePhone(phone string) (string, error) {
+	p := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(strings.TrimSpace(phone))
+	switch {
+	case strings.HasPrefix(p, "08"):
+		p = "+62" + p[1:]
+	case strings.HasPrefix(p, "62"):
+		p = "+" + p
+	}
+	if !phonePattern.MatchString(p) {
+		return "", fmt.Errorf("must be an Indonesian mobile number (08xx / +628xx)")
+	}
+	return p, nil
+}
+
+// ValidateEmail menerima alamat email polos (tanpa display name)
+func ValidateEmail(email string) error {
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return fmt.Errorf("must be a valid email address")
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gouas/app/models"
+	"gouas/app/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Jenis inkonsistensi antara Postgres dan Mongo
+const (
+	IssueOrphanDocument      = "orphan_document"    // dokumen Mongo tanpa reference
+	IssueDanglingReference   = "dangling_reference" // reference menunjuk dokumen Mongo yang tidak ada
+	IssueStudentMismatch     = "student_mismatch"   // studentId Mongo berbeda dengan reference
+	defaultOrphanGracePeriod = 15 * time.Minute
+)
+
+// ReconcileIssue adalah satu temuan beserta hasil perbaikannya (jika repair aktif)
+type ReconcileIssue struct {
+	Kind        string     `json:"kind"`
+	MongoID     string     `json:"mongo_id"`
+	ReferenceID *uuid.UUID `json:"reference_id,omitempty"`
+	Detail      string     `json:"detail"`
+	Repaired    bool       `json:"repaired"`
+	RepairError string     `json:"repair_error,omitempty"`
+}
+
+type ReconcileReport struct {
+	StartedAt      time.Time        `json:"started_at"`
+	FinishedAt     time.Time        `json:"finished_at"`
+	Repair         bool             `json:"repair"`
+	MongoDocuments int              `json:"mongo_documents"`
+	References     int              `json:"references"`
+	Issues         []ReconcileIssue `json:"issues"`
+}
+
+// Summary menghitung jumlah temuan per jenis
+func (r *ReconcileReport) Summary() map[string]int {
+	summary := map[string]int{}
+	for _, issue := range r.Issues {
+		summary[issue.Kind]++
+	}
+	return summary
+}
+
+type ReconcileService interface {
+	Run(ctx context.Context, repair bool) (*ReconcileReport, error)
+}
+
+type reconcileService struct {
+	repo repository.ReconcileRepository
+	// Dokumen Mongo yang lebih muda dari ini dilewati, karena Create mungkin belum selesai menulis reference
+	gracePeriod time.Duration
+	now         func() time.Time
+}
+
+func NewReconcileService(repo repository.ReconcileRepository, gracePeriod time.Duration) ReconcileService {
+	if gracePeriod <= 0 {
+		gracePeriod = defaultOrphanGracePeriod
+	}
+	return &reconcileService{repo: repo, gracePeriod: gracePeriod, now: time.Now}
+}
+
+func (s *reconcileService) Run(ctx context.Context, repair bool) (*ReconcileReport, error) {
+	report := &ReconcileReport{StartedAt: s.now(), Repair: repair, Issues: []ReconcileIssue{}}
+
+	// Reference dibaca lebih dulu: dokumen yang dibuat setelahnya tertahan oleh grace period
+	refs, err := s.repo.ListReferences(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list references: %w", err)
+	}
+	docs, err := s.repo.ListMongoAchievements(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list mongo achievements: %w", err)
+	}
+	report.References = len(refs)
+	report.MongoDocuments = len(docs)
+
+	docByID := make(map[string]repository.MongoAchievementStub, len(docs))
+	for _, doc := range docs {
+		docByID[doc.ID.Hex()] = doc
+	}
+	referenced := make(map[string]bool, len(refs))
+
+	for _, ref := range refs {
+		ref := ref
+		referenced[ref.MongoAchievementID] = true
+
+		doc, ok := docByID[ref.MongoAchievementID]
+		if !ok {
+			if ref.Status == models.StatusDeleted {
+				continue
+			}
+			issue := ReconcileIssue{
+				Kind:        IssueDanglingReference,
+				MongoID:     ref.MongoAchievementID,
+				ReferenceID: &ref.ID,
+				Detail:      fmt.Sprintf("reference with status %s has no MongoDB document", ref.Status),
+			}
+			// Prestasi verified sudah memberi poin, perbaikannya perlu keputusan manual (revoke)
+			if repair && ref.Status != models.StatusVerified {
+				s.repairWith(&issue, func() error {
+					return s.repo.MarkReferenceDeleted(ctx, ref.ID, "MongoDB document missing (reconciliation)")
+				})
+			}
+			report.Issues = append(report.Issues, issue)
+			continue
+		}
+
+		if doc.StudentID != ref.StudentID.String() {
+			issue := ReconcileIssue{
+				Kind:        IssueStudentMismatch,
+				MongoID:     ref.MongoAchievementID,
+				ReferenceID: &ref.ID,
+				Detail:      fmt.Sprintf("mongo studentId %q, reference student_id %s", doc.StudentID, ref.StudentID),
+			}
+			// Postgres adalah sumber kebenaran untuk kepemilikan
+			if repair {
+				s.repairWith(&issue, func() error {
+					return s.repo.SetMongoStudentID(ctx, ref.MongoAchievementID, ref.StudentID)
+				})
+			}
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+
+	cutoff := s.now().Add(-s.gracePeriod)
+	for _, doc := range docs {
+		mongoID := doc.ID.Hex()
+		if referenced[mongoID] || doc.CreatedAt.After(cutoff) {
+			continue
+		}
+		issue := ReconcileIssue{
+			Kind:    IssueOrphanDocument,
+			MongoID: mongoID,
+			Detail:  fmt.Sprintf("document for student %q created at %s has no reference", doc.StudentID, doc.CreatedAt.Format(time.RFC3339)),
+		}
+		if repair {
+			s.repairWith(&issue, func() error {
+				return s.repo.DeleteMongoAchievement(ctx, mongoID)
+			})
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+
+	report.FinishedAt = s.now()
+	return report, nil
+}
+
+func (s *reconcileService) repairWith(issue *ReconcileIssue, fix func() error) {
+	if err := fix(); err != nil {
+		issue.RepairError = err.Error()
+		return
+	}
+	issue.Repaired = true
+}
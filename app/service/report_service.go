@@ -15,27 +15,42 @@ type ReportService interface {
 }
 
 type reportService struct {
-	repo    repository.ReportRepository
-	achRepo repository.AchievementRepository
+	repo         repository.ReportRepository
+	achRepo      repository.AchievementRepository
+	studentRepo  repository.StudentRepository
+	lecturerRepo repository.LecturerRepository
 }
 
-func NewReportService(repo repository.ReportRepository, achRepo repository.AchievementRepository) ReportService {
-	return &reportService{repo: repo, achRepo: achRepo}
+func NewReportService(repo repository.ReportRepository, achRepo repository.AchievementRepository, studentRepo repository.StudentRepository, lecturerRepo repository.LecturerRepository) ReportService {
+	return &reportService{repo: repo, achRepo: achRepo, studentRepo: studentRepo, lecturerRepo: lecturerRepo}
 }
 
+// GetStatistics hanya menghitung mahasiswa dalam scope user (global, unit organisasi, atau bimbingan)
 func (s *reportService) GetStatistics(c *fiber.Ctx) error {
-	authData, _ := middleware.CheckAuth(c.Get("Authorization"))
-	if authData.Role == "Mahasiswa" {
-		return c.Status(403).JSON(helper.APIResponse("error", "Forbidden", nil))
-	}
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
+
+	studentIDs := visibleStudentIDs(ctx, s.studentRepo, s.lecturerRepo, authData)
+	stats, _ := s.repo.GetAchievementStats(ctx, studentIDs)
 
-	stats, _ := s.repo.GetAchievementStats()
-	return c.Status(200).JSON(helper.APIResponse("success", "Global Statistics", stats))
+	message := "Global Statistics"
+	if studentIDs != nil {
+		message = "Scoped Statistics"
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", message, stats))
 }
 
 func (s *reportService) GetStudentReport(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
 	id, _ := uuid.Parse(c.Params("id"))
-	// Validation owner/advisor should be here
-	data, _ := s.achRepo.FindReferencesByStudentID(id)
+	student, err := s.studentRepo.FindByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Student not found", nil))
+	}
+	if !canAccessStudent(ctx, s.studentRepo, s.lecturerRepo, authData, student) {
+		return c.Status(403).JSON(helper.APIResponse("error", "Forbidden: Not your profile/advisee", nil))
+	}
+	data, _ := s.achRepo.FindReferencesByStudentID(ctx, id)
 	return c.Status(200).JSON(helper.APIResponse("success", "Student Achievement Report", data))
-}
\ No newline at end of file
+}
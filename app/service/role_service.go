@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gouas/app/models"
+	"gouas/app/repository"
+	"gouas/helper"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrSystemRole           = errors.New("system roles cannot be renamed or deleted")
+	ErrRoleInUse            = errors.New("role is still assigned to users")
+	ErrBuiltinPermission    = errors.New("built-in permissions cannot be deleted")
+	ErrLastPermissionHolder = errors.New("at least one active user must keep this permission")
+)
+
+// lockoutPermissions tidak boleh hilang dari semua user, agar admin tidak terkunci di luar sistem
+var lockoutPermissions = []string{models.PermUserManage, models.PermRoleManage}
+
+type RoleService interface {
+	GetAllRoles(c *fiber.Ctx) error
+	GetRole(c *fiber.Ctx) error
+	CreateRole(c *fiber.Ctx) error
+	UpdateRole(c *fiber.Ctx) error
+	DeleteRole(c *fiber.Ctx) error
+	AttachPermission(c *fiber.Ctx) error
+	DetachPermission(c *fiber.Ctx) error
+
+	GetAllPermissions(c *fiber.Ctx) error
+	CreatePermission(c *fiber.Ctx) error
+	UpdatePermission(c *fiber.Ctx) error
+	DeletePermission(c *fiber.Ctx) error
+}
+
+type roleService struct {
+	repo repository.RoleRepository
+	uow  repository.UnitOfWork
+}
+
+func NewRoleService(repo repository.RoleRepository, uow repository.UnitOfWork) RoleService {
+	return &roleService{repo: repo, uow: uow}
+}
+
+// ensurePermissionHolders memastikan setiap lockout permission yang akan hilang masih dimiliki user lain.
+// repo harus repos.Role dari UnitOfWork yang juga menjalankan perubahannya: lock dilepas saat commit,
+// jadi dua request yang sama-sama mencabut pemegang terakhir tidak bisa lolos bersamaan.
+func ensurePermissionHolders(ctx context.Context, repo repository.RoleRepository, lost []string, excludeRoleID, excludeUserID uuid.UUID) error {
+	locked := false
+	for _, perm := range lost {
+		if !containsString(lockoutPermissions, perm) {
+			continue
+		}
+		if !locked {
+			if err := repo.LockPermissionHolders(ctx); err != nil {
+				return err
+			}
+			locked = true
+		}
+		count, err := repo.CountUsersWithPermission(ctx, perm, excludeRoleID, excludeUserID)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: %s", ErrLastPermissionHolder, perm)
+		}
+	}
+	return nil
+}
+
+// roleErrorStatus memetakan error safeguard ke HTTP status
+func roleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrRoleInUse), errors.Is(err, ErrLastPermissionHolder):
+		return 409
+	case errors.Is(err, ErrSystemRole), errors.Is(err, ErrBuiltinPermission):
+		return 400
+	}
+	return 500
+}
+
+// --- Roles ---
+
+func (s *roleService) GetAllRoles(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	roles, err := s.repo.FindAllRoles(ctx)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Role list", roles))
+}
+
+func (s *roleService) GetRole(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	role, err := s.repo.FindRoleByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Role not found", nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Role detail", role))
+}
+
+func (s *roleService) CreateRole(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	var input struct {
+		Name          string      `json:"name"`
+		Description   string      `json:"description"`
+		PermissionIDs []uuid.UUID `json:"permissionIds"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: name is required", nil))
+	}
+
+	role := models.Role{Name: input.Name, Description: input.Description}
+	for _, permID := range input.PermissionIDs {
+		perm, err := s.repo.FindPermissionByID(ctx, permID)
+		if err != nil {
+			return c.Status(400).JSON(helper.APIResponse("error", "Permission not found: "+permID.String(), nil))
+		}
+		role.Permissions = append(role.Permissions, *perm)
+	}
+
+	created, err := s.repo.CreateRole(ctx, role)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(201).JSON(helper.APIResponse("success", "Role created", created))
+}
+
+func (s *roleService) UpdateRole(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	role, err := s.repo.FindRoleByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Role not found", nil))
+	}
+
+	var input struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: name is required", nil))
+	}
+	// Nama role sistem dipakai langsung oleh kode (mis. pembuatan profile)
+	if input.Name != role.Name && models.IsSystemRole(role.Name) {
+		return c.Status(400).JSON(helper.APIResponse("error", ErrSystemRole.Error(), nil))
+	}
+
+	role.Name = input.Name
+	role.Description = input.Description
+	if err := s.repo.UpdateRole(ctx, *role); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Role updated", role))
+}
+
+func (s *roleService) DeleteRole(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	role, err := s.repo.FindRoleByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Role not found", nil))
+	}
+
+	if err := s.canDeleteRole(ctx, role); err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if err := s.repo.DeleteRole(ctx, role.ID); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Role deleted", nil))
+}
+
+func (s *roleService) canDeleteRole(ctx context.Context, role *models.Role) error {
+	if models.IsSystemRole(role.Name) {
+		return ErrSystemRole
+	}
+	count, err := s.repo.CountUsersByRole(ctx, role.ID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%w (%d users)", ErrRoleInUse, count)
+	}
+	return nil
+}
+
+func (s *roleService) AttachPermission(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	role, err := s.repo.FindRoleByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Role not found", nil))
+	}
+
+	var input struct {
+		PermissionID uuid.UUID `json:"permissionId"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	perm, err := s.repo.FindPermissionByID(ctx, input.PermissionID)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Permission not found", nil))
+	}
+
+	if !role.HasPermission(perm.Name) {
+		if err := s.repo.AttachPermission(ctx, role.ID, *perm); err != nil {
+			return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+		}
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Permission attached", nil))
+}
+
+func (s *roleService) DetachPermission(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	role, err := s.repo.FindRoleByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Role not found", nil))
+	}
+	permID, _ := uuid.Parse(c.Params("permissionId"))
+	perm, err := s.repo.FindPermissionByID(ctx, permID)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Permission not found", nil))
+	}
+	if !role.HasPermission(perm.Name) {
+		return c.Status(200).JSON(helper.APIResponse("success", "Permission detached", nil))
+	}
+
+	// User di role ini kehilangan permission, jadi harus ada pemegang lain di luar role ini
+	err = s.uow.Do(ctx, func(repos repository.TxRepositories) error {
+		if err := ensurePermissionHolders(ctx, repos.Role, []string{perm.Name}, role.ID, uuid.Nil); err != nil {
+			return err
+		}
+		return repos.Role.DetachPermission(ctx, role.ID, *perm)
+	})
+	if err != nil {
+		return c.Status(roleErrorStatus(err)).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Permission detached", nil))
+}
+
+// --- Permissions ---
+
+func (s *roleService) GetAllPermissions(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	perms, err := s.repo.FindAllPermissions(ctx)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Permission list", perms))
+}
+
+func (s *roleService) CreatePermission(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	var input struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+
+	// Format nama permission: resource:action
+	resource, action, ok := strings.Cut(strings.TrimSpace(input.Name), ":")
+	if !ok || resource == "" || action == "" {
+		return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed: name must be in the form resource:action", nil))
+	}
+
+	created, err := s.repo.CreatePermission(ctx, models.Permission{
+		Name:        resource + ":" + action,
+		Resource:    resource,
+		Action:      action,
+		Description: input.Description,
+	})
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(201).JSON(helper.APIResponse("success", "Permission created", created))
+}
+
+// UpdatePermission hanya mengubah deskripsi; nama dipakai sebagai kunci oleh route
+func (s *roleService) UpdatePermission(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	perm, err := s.repo.FindPermissionByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Permission not found", nil))
+	}
+
+	var input struct {
+		Description string `json:"description"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid input", nil))
+	}
+	perm.Description = input.Description
+
+	if err := s.repo.UpdatePermission(ctx, *perm); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Permission updated", perm))
+}
+
+func (s *roleService) DeletePermission(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, _ := uuid.Parse(c.Params("id"))
+	perm, err := s.repo.FindPermissionByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Permission not found", nil))
+	}
+	if models.IsBuiltinPermission(perm.Name) {
+		return c.Status(400).JSON(helper.APIResponse("error", ErrBuiltinPermission.Error(), nil))
+	}
+
+	if err := s.repo.DeletePermission(ctx, perm.ID); err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Permission deleted", nil))
+}
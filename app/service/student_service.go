@@ -1,6 +1,8 @@
 package service
 
 import (
+	"context"
+	"gouas/app/models"
 	"gouas/app/repository"
 	"gouas/helper"
 	"gouas/middleware"
@@ -13,85 +15,117 @@ type StudentService interface {
 	GetAll(c *fiber.Ctx) error
 	GetDetail(c *fiber.Ctx) error
 	GetStudentAchievements(c *fiber.Ctx) error
-	AssignAdvisor(c *fiber.Ctx) error
+	GetPointLedger(c *fiber.Ctx) error
+	ReconcilePoints(c *fiber.Ctx) error
 }
 
 type studentService struct {
 	repo         repository.StudentRepository
 	achRepo      repository.AchievementRepository
 	lecturerRepo repository.LecturerRepository
+	pointRepo    repository.PointRepository
 }
 
-func NewStudentService(repo repository.StudentRepository, achRepo repository.AchievementRepository, lecturerRepo repository.LecturerRepository) StudentService {
-	return &studentService{repo: repo, achRepo: achRepo, lecturerRepo: lecturerRepo}
+func NewStudentService(repo repository.StudentRepository, achRepo repository.AchievementRepository, lecturerRepo repository.LecturerRepository, pointRepo repository.PointRepository) StudentService {
+	return &studentService{repo: repo, achRepo: achRepo, lecturerRepo: lecturerRepo, pointRepo: pointRepo}
 }
 
 func (s *studentService) GetAll(c *fiber.Ctx) error {
-	authData, _ := middleware.CheckAuth(c.Get("Authorization"))
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
 
-	if authData.Role == "Mahasiswa" {
+	scope := scopeOf(authData)
+	if scope == scopeOwn {
 		return c.Status(403).JSON(helper.APIResponse("error", "Mahasiswa cannot list all students", nil))
 	}
 
-	if authData.Role == "Dosen Wali" {
-		lecturer, _ := s.lecturerRepo.FindByUserID(uuid.MustParse(authData.UserID))
-		students, _ := s.lecturerRepo.FindAdvisees(lecturer.ID)
+	if scope == scopeAdvisees {
+		lecturer, _ := s.lecturerRepo.FindByUserID(ctx, uuid.MustParse(authData.UserID))
+		students, _ := s.lecturerRepo.FindAdvisees(ctx, lecturer.ID)
 		return c.Status(200).JSON(helper.APIResponse("success", "Advisees list retrieved", students))
 	}
 
-	students, _ := s.repo.FindAll()
+	if scope == scopeUnits {
+		students, _ := s.repo.FindByUnits(ctx, authData.Scopes)
+		return c.Status(200).JSON(helper.APIResponse("success", "Students in your units retrieved", students))
+	}
+
+	students, _ := s.repo.FindAll(ctx)
 	return c.Status(200).JSON(helper.APIResponse("success", "All students list retrieved", students))
 }
 
 func (s *studentService) GetDetail(c *fiber.Ctx) error {
-	authData, _ := middleware.CheckAuth(c.Get("Authorization"))
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
 	id, _ := uuid.Parse(c.Params("id"))
-	student, err := s.repo.FindByID(id)
+	student, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return c.Status(404).JSON(helper.APIResponse("error", "Student not found", nil))
 	}
 
-	// [ACCESS CHECK]
-	allowed := false
-	switch authData.Role {
-	case "Admin":
-		allowed = true
-	case "Mahasiswa":
-		if student.UserID.String() == authData.UserID {
-			allowed = true
-		}
-	case "Dosen Wali":
-		lecturer, _ := s.lecturerRepo.FindByUserID(uuid.MustParse(authData.UserID))
-		if student.AdvisorID != nil && *student.AdvisorID == lecturer.ID {
-			allowed = true
-		}
-	}
-
-	if !allowed {
+	if !s.canAccess(ctx, authData, student) {
 		return c.Status(403).JSON(helper.APIResponse("error", "Forbidden: Not your profile/advisee", nil))
 	}
 
 	return c.Status(200).JSON(helper.APIResponse("success", "Student detail retrieved", student))
 }
 
-func (s *studentService) AssignAdvisor(c *fiber.Ctx) error {
+func (s *studentService) GetStudentAchievements(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
+	id, _ := uuid.Parse(c.Params("id"))
+	student, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Student not found", nil))
+	}
+	if !s.canAccess(ctx, authData, student) {
+		return c.Status(403).JSON(helper.APIResponse("error", "Forbidden: Not your profile/advisee", nil))
+	}
+	data, _ := s.achRepo.FindReferencesByStudentID(ctx, id)
+	return c.Status(200).JSON(helper.APIResponse("success", "Achievements retrieved", data))
+}
+
+// GetPointLedger menampilkan rincian poin mahasiswa baris per baris
+func (s *studentService) GetPointLedger(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
 	id, _ := uuid.Parse(c.Params("id"))
-	var input struct {
-		AdvisorID string `json:"advisorId"`
+	student, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Student not found", nil))
+	}
+	if !s.canAccess(ctx, authData, student) {
+		return c.Status(403).JSON(helper.APIResponse("error", "Forbidden: Not your profile/advisee", nil))
 	}
-	c.BodyParser(&input)
-	advID, _ := uuid.Parse(input.AdvisorID)
 
-	err := s.repo.UpdateAdvisor(id, advID)
+	ledger, err := s.pointRepo.FindByStudentID(ctx, student.ID)
 	if err != nil {
 		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
 	}
-	return c.Status(200).JSON(helper.APIResponse("success", "Advisor assigned successfully", nil))
+	return c.Status(200).JSON(helper.APIResponse("success", "Point ledger retrieved", fiber.Map{
+		"total_points": student.TotalPoints,
+		"transactions": ledger,
+	}))
 }
 
-func (s *studentService) GetStudentAchievements(c *fiber.Ctx) error {
+// ReconcilePoints menyamakan TotalPoints dengan jumlah ledger (butuh point:reconcile di route)
+func (s *studentService) ReconcilePoints(c *fiber.Ctx) error {
+	ctx := c.UserContext()
 	id, _ := uuid.Parse(c.Params("id"))
-	// Access check should be same as GetDetail (Simplified here)
-	data, _ := s.achRepo.FindReferencesByStudentID(id)
-	return c.Status(200).JSON(helper.APIResponse("success", "Achievements retrieved", data))
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Student not found", nil))
+	}
+
+	total, err := s.pointRepo.Reconcile(ctx, id)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Points reconciled", fiber.Map{
+		"total_points": total,
+	}))
+}
+
+// canAccess: lihat canAccessStudent
+func (s *studentService) canAccess(ctx context.Context, authData *middleware.AuthResult, student *models.Student) bool {
+	return canAccessStudent(ctx, s.repo, s.lecturerRepo, authData, student)
 }
@@ -1,6 +1,8 @@
 package test
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
 
 	"gouas/app/models"
@@ -10,131 +12,29 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gorm.io/gorm"
 )
 
-// --- MOCK ACHIEVEMENT REPOSITORY ---
-type MockAchievementRepo struct {
-	mock.Mock
-}
-
-func (m *MockAchievementRepo) Create(data models.Achievement, studentID uuid.UUID) (*models.AchievementReference, error) {
-	args := m.Called(data, studentID)
-	if args.Get(0) == nil { return nil, args.Error(1) }
-	return args.Get(0).(*models.AchievementReference), args.Error(1)
-}
-func (m *MockAchievementRepo) FindReferenceByID(id uuid.UUID) (*models.AchievementReference, error) {
-	args := m.Called(id)
-	if args.Get(0) == nil { return nil, args.Error(1) }
-	return args.Get(0).(*models.AchievementReference), args.Error(1)
-}
-func (m *MockAchievementRepo) UpdateStatus(id uuid.UUID, status models.AchievementStatus) error {
-	args := m.Called(id, status)
-	return args.Error(0)
-}
-func (m *MockAchievementRepo) Verify(id uuid.UUID, verifierID uuid.UUID) error {
-	args := m.Called(id, verifierID)
-	return args.Error(0)
-}
-func (m *MockAchievementRepo) Reject(id uuid.UUID, note string) error {
-	args := m.Called(id, note)
-	return args.Error(0)
-}
-func (m *MockAchievementRepo) AddAttachment(mongoID string, attachment models.Attachment) error {
-	args := m.Called(mongoID, attachment)
-	return args.Error(0)
-}
-func (m *MockAchievementRepo) SoftDelete(id uuid.UUID) error {
-	args := m.Called(id)
-	return args.Error(0)
-}
-func (m *MockAchievementRepo) FindAllReferences() ([]models.AchievementReference, error) {
-	args := m.Called()
-	return args.Get(0).([]models.AchievementReference), args.Error(1)
-}
-func (m *MockAchievementRepo) FindReferencesByStudentID(studentID uuid.UUID) ([]models.AchievementReference, error) {
-	args := m.Called(studentID)
-	return args.Get(0).([]models.AchievementReference), args.Error(1)
-}
-func (m *MockAchievementRepo) FindReferencesByStudentIDs(studentIDs []uuid.UUID) ([]models.AchievementReference, error) {
-	args := m.Called(studentIDs)
-	return args.Get(0).([]models.AchievementReference), args.Error(1)
-}
-func (m *MockAchievementRepo) GetMongoDetail(mongoID string) (*models.Achievement, error) {
-	args := m.Called(mongoID)
-	if args.Get(0) == nil { return nil, args.Error(1) }
-	return args.Get(0).(*models.Achievement), args.Error(1)
-}
-func (m *MockAchievementRepo) UpdateMongo(mongoID string, data models.Achievement) error {
-	args := m.Called(mongoID, data)
-	return args.Error(0)
-}
-
-// --- MOCK STUDENT REPOSITORY ---
-type MockStudentRepo struct {
-	mock.Mock
-}
-
-func (m *MockStudentRepo) FindAll() ([]models.Student, error) {
-	args := m.Called()
-	return args.Get(0).([]models.Student), args.Error(1)
-}
-func (m *MockStudentRepo) FindByID(id uuid.UUID) (*models.Student, error) {
-	args := m.Called(id)
-	if args.Get(0) == nil { return nil, args.Error(1) }
-	return args.Get(0).(*models.Student), args.Error(1)
-}
-func (m *MockStudentRepo) FindByUserID(userID uuid.UUID) (*models.Student, error) {
-	args := m.Called(userID)
-	if args.Get(0) == nil { return nil, args.Error(1) }
-	return args.Get(0).(*models.Student), args.Error(1)
-}
-func (m *MockStudentRepo) UpdateAdvisor(studentID uuid.UUID, advisorID uuid.UUID) error {
-	args := m.Called(studentID, advisorID)
-	return args.Error(0)
-}
-func (m *MockStudentRepo) AddPoints(studentID uuid.UUID, points int) error {
-	args := m.Called(studentID, points)
-	return args.Error(0)
-}
-
-// --- [BARU] MOCK LECTURER REPOSITORY ---
-type MockLecturerRepo struct {
-	mock.Mock
-}
-
-func (m *MockLecturerRepo) FindAll() ([]models.Lecturer, error) {
-	args := m.Called()
-	return args.Get(0).([]models.Lecturer), args.Error(1)
-}
-func (m *MockLecturerRepo) FindByID(id uuid.UUID) (*models.Lecturer, error) {
-	args := m.Called(id)
-	if args.Get(0) == nil { return nil, args.Error(1) }
-	return args.Get(0).(*models.Lecturer), args.Error(1)
-}
-func (m *MockLecturerRepo) FindByUserID(userID uuid.UUID) (*models.Lecturer, error) {
-	args := m.Called(userID)
-	if args.Get(0) == nil { return nil, args.Error(1) }
-	return args.Get(0).(*models.Lecturer), args.Error(1)
-}
-func (m *MockLecturerRepo) FindAdvisees(lecturerID uuid.UUID) ([]models.Student, error) {
-	args := m.Called(lecturerID)
-	return args.Get(0).([]models.Student), args.Error(1)
-}
-
 // ==================== TESTS ====================
 
 func TestCreateAchievement_Success(t *testing.T) {
 	mockRepo := new(MockAchievementRepo)
 	mockStudentRepo := new(MockStudentRepo)
 	mockLecturerRepo := new(MockLecturerRepo)
-	svc := service.NewAchievementService(mockRepo, mockStudentRepo, mockLecturerRepo)
+	mockRuleRepo := new(MockPointRuleRepo)
+	mockTypeRepo := new(MockAchievementTypeRepo)
+	svc := service.NewAchievementService(mockRepo, mockStudentRepo, mockLecturerRepo, mockRuleRepo, mockTypeRepo)
 
 	studentID := uuid.New()
 	achievementData := models.Achievement{
 		Title:           "Juara Lomba Pemrograman",
-		AchievementType: "Kompetisi",
+		AchievementType: "competition",
 		Points:          10, // [FIX] Tambahkan poin agar lolos validasi
 		Tags:            []string{"coding"},
+		Details: models.AchievementDetails{
+			CompetitionName:  "Gemastik",
+			CompetitionLevel: "National",
+		},
 	}
 
 	expectedRef := &models.AchievementReference{
@@ -146,7 +46,7 @@ func TestCreateAchievement_Success(t *testing.T) {
 	// Pastikan mock On mencocokkan data yang sama (termasuk Points)
 	mockRepo.On("Create", achievementData, studentID).Return(expectedRef, nil)
 
-	result, err := svc.CreateAchievement(achievementData, studentID)
+	result, err := svc.CreateAchievement(context.Background(), achievementData, studentID)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -159,7 +59,9 @@ func TestVerifyAchievement_Success(t *testing.T) {
 	mockRepo := new(MockAchievementRepo)
 	mockStudentRepo := new(MockStudentRepo)
 	mockLecturerRepo := new(MockLecturerRepo)
-	svc := service.NewAchievementService(mockRepo, mockStudentRepo, mockLecturerRepo)
+	mockRuleRepo := new(MockPointRuleRepo)
+	mockTypeRepo := new(MockAchievementTypeRepo)
+	svc := service.NewAchievementService(mockRepo, mockStudentRepo, mockLecturerRepo, mockRuleRepo, mockTypeRepo)
 
 	// 2. Setup Variable Dummy
 	id := uuid.New()
@@ -201,27 +103,42 @@ func TestVerifyAchievement_Success(t *testing.T) {
 		},
 	}, nil)
 
-	// 6. Mock Setup: Eksekusi Update Status & Tambah Poin
-	mockRepo.On("Verify", id, verifierUserID).Return(nil)
-	
-	// Pastikan poin yang dipanggil adalah 30 (Sesuai level National)
-	mockStudentRepo.On("AddPoints", studentID, expectedPoints).Return(nil)
+	// 6. Mock Setup: Rule poin aktif (National = 30)
+	mockRuleRepo.On("FindActive").Return([]models.PointRule{
+		{Name: "Kompetisi Nasional", CompetitionLevel: "National", Points: 30, IsActive: true},
+		{Name: "Poin Dasar", Points: 5, IsActive: true},
+	}, nil)
 
-	// 7. Eksekusi Fungsi yang di-test
-	err := svc.VerifyAchievement(id, verifierUserID)
+	// 7. Mock Setup: Update Status + award poin ditulis bersamaan
+	verifier := models.StatusActor{UserID: verifierUserID, Role: "Dosen Wali", Permissions: models.DefaultRolePermissions["Dosen Wali"]}
+	var outbox []models.OutboxEvent
+	mockRepo.On("Verify", id, verifier, expectedPoints, mock.Anything).
+		Run(func(args mock.Arguments) { outbox = args.Get(3).([]models.OutboxEvent) }).
+		Return(nil)
 
-	// 8. Assertions
+	// 8. Eksekusi Fungsi yang di-test
+	err := svc.VerifyAchievement(context.Background(), id, verifier)
+
+	// 9. Assertions
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
 	mockStudentRepo.AssertExpectations(t)
 	mockLecturerRepo.AssertExpectations(t)
+
+	// Poin 30 (sesuai level National) dicatat oleh repo.Verify di transaksi yang sama;
+	// tidak ada award yang menunggu dispatcher
+	for _, e := range outbox {
+		assert.NotEqual(t, models.OutboxAwardPoints, e.Kind)
+	}
 }
 
 func TestRejectAchievement_Success(t *testing.T) {
 	mockRepo := new(MockAchievementRepo)
 	mockStudentRepo := new(MockStudentRepo)
 	mockLecturerRepo := new(MockLecturerRepo)
-	svc := service.NewAchievementService(mockRepo, mockStudentRepo, mockLecturerRepo)
+	mockRuleRepo := new(MockPointRuleRepo)
+	mockTypeRepo := new(MockAchievementTypeRepo)
+	svc := service.NewAchievementService(mockRepo, mockStudentRepo, mockLecturerRepo, mockRuleRepo, mockTypeRepo)
 
 	id := uuid.New()
 	verifierUserID := uuid.New()
@@ -241,18 +158,75 @@ func TestRejectAchievement_Success(t *testing.T) {
 		ID: lecturerProfileID,
 	}, nil)
 
-	mockRepo.On("Reject", id, note).Return(nil)
+	verifier := models.StatusActor{UserID: verifierUserID, Role: "Dosen Wali", Permissions: models.DefaultRolePermissions["Dosen Wali"]}
+	mockRepo.On("Reject", id, note, verifier, mock.Anything).Return(nil)
+
+	err := svc.RejectAchievement(context.Background(), id, verifier, note) // Note: VerifierID di logic reject murni biasanya diproses di handler/bridge
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRejectAchievement_LostRaceIsInvalidTransition(t *testing.T) {
+	mockRepo := new(MockAchievementRepo)
+	mockStudentRepo := new(MockStudentRepo)
+	mockLecturerRepo := new(MockLecturerRepo)
+	svc := service.NewAchievementService(mockRepo, mockStudentRepo, mockLecturerRepo, new(MockPointRuleRepo), new(MockAchievementTypeRepo))
+
+	id := uuid.New()
+	verifierUserID := uuid.New()
+	studentID := uuid.New()
+	lecturerProfileID := uuid.New()
+
+	// Snapshot masih submitted, tetapi request verify lain sudah commit lebih dulu
+	mockRepo.On("FindReferenceByID", id).Return(&models.AchievementReference{
+		ID: id, StudentID: studentID, Status: models.StatusSubmitted,
+	}, nil)
+	mockStudentRepo.On("FindByID", studentID).Return(&models.Student{ID: studentID, AdvisorID: &lecturerProfileID}, nil)
+	mockLecturerRepo.On("FindByUserID", verifierUserID).Return(&models.Lecturer{ID: lecturerProfileID}, nil)
+
+	verifier := models.StatusActor{UserID: verifierUserID, Role: "Dosen Wali", Permissions: models.DefaultRolePermissions["Dosen Wali"]}
+	mockRepo.On("Reject", id, "Data kurang lengkap", verifier, mock.Anything).Return(models.ErrInvalidTransition)
+
+	err := svc.RejectAchievement(context.Background(), id, verifier, "Data kurang lengkap")
 
-	err := svc.RejectAchievement(id, verifierUserID, note) // Note: VerifierID di logic reject murni biasanya diproses di handler/bridge
+	assert.ErrorIs(t, err, service.ErrInvalidTransition)
+	// Repo menerima status asal transisi untuk dicek ulang di bawah lock
+	assert.Equal(t, []models.AchievementStatus{models.StatusSubmitted}, mockRepo.From)
+}
+
+func TestSubmitAchievement_RecordsActor(t *testing.T) {
+	mockRepo := new(MockAchievementRepo)
+	mockStudentRepo := new(MockStudentRepo)
+	mockLecturerRepo := new(MockLecturerRepo)
+	mockRuleRepo := new(MockPointRuleRepo)
+	mockTypeRepo := new(MockAchievementTypeRepo)
+	svc := service.NewAchievementService(mockRepo, mockStudentRepo, mockLecturerRepo, mockRuleRepo, mockTypeRepo)
+
+	id := uuid.New()
+	studentID := uuid.New()
+	actor := models.StatusActor{UserID: uuid.New(), Role: "Mahasiswa", Permissions: models.DefaultRolePermissions["Mahasiswa"]}
+
+	// Prestasi yang pernah ditolak boleh diajukan ulang
+	mockRepo.On("FindReferenceByID", id).Return(&models.AchievementReference{
+		ID: id, StudentID: studentID, Status: models.StatusRejected,
+	}, nil)
+	mockStudentRepo.On("FindByID", studentID).Return(&models.Student{ID: studentID}, nil)
+	mockRepo.On("UpdateStatus", id, models.StatusSubmitted, actor, mock.Anything).Return(nil)
+
+	err := svc.SubmitAchievement(context.Background(), id, studentID, actor)
 
 	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
 }
 
 func TestCreateAchievement_PointsValidationError(t *testing.T) {
 	mockRepo := new(MockAchievementRepo)
 	mockStudentRepo := new(MockStudentRepo)
 	mockLecturerRepo := new(MockLecturerRepo)
-	svc := service.NewAchievementService(mockRepo, mockStudentRepo, mockLecturerRepo)
+	mockRuleRepo := new(MockPointRuleRepo)
+	mockTypeRepo := new(MockAchievementTypeRepo)
+	svc := service.NewAchievementService(mockRepo, mockStudentRepo, mockLecturerRepo, mockRuleRepo, mockTypeRepo)
 
 	studentID := uuid.New()
 	// Input tanpa points (points = 0)
@@ -262,7 +236,7 @@ func TestCreateAchievement_PointsValidationError(t *testing.T) {
 		Points:          0, 
 	}
 
-	result, err := svc.CreateAchievement(invalidData, studentID)
+	result, err := svc.CreateAchievement(context.Background(), invalidData, studentID)
 
 	assert.Error(t, err)
 	assert.Nil(t, result)
@@ -274,22 +248,191 @@ func TestCreateAchievement_SuccessWithPoints(t *testing.T) {
 	mockRepo := new(MockAchievementRepo)
 	mockStudentRepo := new(MockStudentRepo)
 	mockLecturerRepo := new(MockLecturerRepo)
-	svc := service.NewAchievementService(mockRepo, mockStudentRepo, mockLecturerRepo)
+	mockRuleRepo := new(MockPointRuleRepo)
+	mockTypeRepo := new(MockAchievementTypeRepo)
+	svc := service.NewAchievementService(mockRepo, mockStudentRepo, mockLecturerRepo, mockRuleRepo, mockTypeRepo)
 
 	studentID := uuid.New()
 	achievementData := models.Achievement{
 		Title:           "Juara 1",
-		AchievementType: "Competition",
+		AchievementType: "competition",
 		Points:          10, // Mengisi poin
 		Tags:            []string{"coding"},
+		Details: models.AchievementDetails{
+			CompetitionName:  "Hackathon Kampus",
+			CompetitionLevel: "Campus",
+		},
 	}
 
 	expectedRef := &models.AchievementReference{ID: uuid.New(), Status: models.StatusDraft}
 	mockRepo.On("Create", achievementData, studentID).Return(expectedRef, nil)
 
-	result, err := svc.CreateAchievement(achievementData, studentID)
+	result, err := svc.CreateAchievement(context.Background(), achievementData, studentID)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
 	mockRepo.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+func TestDeleteAchievement_StudentCannotDeleteSubmitted(t *testing.T) {
+	mockRepo := new(MockAchievementRepo)
+	mockStudentRepo := new(MockStudentRepo)
+	mockLecturerRepo := new(MockLecturerRepo)
+	mockRuleRepo := new(MockPointRuleRepo)
+	mockTypeRepo := new(MockAchievementTypeRepo)
+	svc := service.NewAchievementService(mockRepo, mockStudentRepo, mockLecturerRepo, mockRuleRepo, mockTypeRepo)
+
+	id := uuid.New()
+	actor := models.StatusActor{UserID: uuid.New(), Role: "Mahasiswa", Permissions: models.DefaultRolePermissions["Mahasiswa"]}
+
+	mockRepo.On("FindReferenceByID", id).Return(&models.AchievementReference{
+		ID: id, StudentID: uuid.New(), Status: models.StatusSubmitted,
+	}, nil)
+
+	err := svc.DeleteAchievement(context.Background(), id, actor)
+
+	assert.ErrorIs(t, err, service.ErrForbiddenTransition)
+	mockRepo.AssertNotCalled(t, "SoftDelete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteAchievement_AdminDeletesVerifiedAndSyncsMongo(t *testing.T) {
+	mockRepo := new(MockAchievementRepo)
+	svc := service.NewAchievementService(mockRepo, new(MockStudentRepo), new(MockLecturerRepo), new(MockPointRuleRepo), new(MockAchievementTypeRepo))
+
+	id := uuid.New()
+	actor := models.StatusActor{UserID: uuid.New(), Role: "Admin", Permissions: models.DefaultRolePermissions["Admin"]}
+	mockRepo.On("FindReferenceByID", id).Return(&models.AchievementReference{
+		ID: id, StudentID: uuid.New(), MongoAchievementID: "657f1a2b3c4d5e6f7a8b9c0d", Status: models.StatusVerified,
+	}, nil)
+	// Penarikan poin terjadi di repository (transaksi yang sama); service cukup mengirim sync Mongo
+	mockRepo.On("SoftDelete", id, actor, mock.MatchedBy(func(events []models.OutboxEvent) bool {
+		if len(events) != 1 || events[0].Kind != models.OutboxSyncMongo {
+			return false
+		}
+		var payload models.SyncMongoPayload
+		return json.Unmarshal(events[0].Payload, &payload) == nil && payload.Status == models.StatusDeleted && payload.PointsAwarded == 0
+	})).Return(nil)
+
+	err := svc.DeleteAchievement(context.Background(), id, actor)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRevokeAchievement_AdminSuccess(t *testing.T) {
+	mockRepo := new(MockAchievementRepo)
+	mockStudentRepo := new(MockStudentRepo)
+	mockLecturerRepo := new(MockLecturerRepo)
+	mockRuleRepo := new(MockPointRuleRepo)
+	mockTypeRepo := new(MockAchievementTypeRepo)
+	svc := service.NewAchievementService(mockRepo, mockStudentRepo, mockLecturerRepo, mockRuleRepo, mockTypeRepo)
+
+	id := uuid.New()
+	studentID := uuid.New()
+	admin := models.StatusActor{UserID: uuid.New(), Role: "Admin", Permissions: models.DefaultRolePermissions["Admin"]}
+	reason := "Sertifikat palsu"
+
+	mockRepo.On("FindReferenceByID", id).Return(&models.AchievementReference{
+		ID: id, StudentID: studentID, Status: models.StatusVerified, PointsAwarded: 30,
+	}, nil)
+	mockStudentRepo.On("FindByID", studentID).Return(&models.Student{ID: studentID}, nil)
+	var outbox []models.OutboxEvent
+	mockRepo.On("Revoke", id, reason, admin, mock.Anything).
+		Run(func(args mock.Arguments) { outbox = args.Get(3).([]models.OutboxEvent) }).
+		Return(nil)
+
+	err := svc.RevokeAchievement(context.Background(), id, admin, reason)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	// Poin dikurangi di dalam transaksi Revoke, bukan lewat event award
+	for _, e := range outbox {
+		assert.NotEqual(t, models.OutboxAwardPoints, e.Kind)
+	}
+}
+
+// Admin yang dibatasi scope unit tidak boleh melewati cek dosen wali untuk mahasiswa di luar unitnya
+func TestRevokeAchievement_ScopedAdminOutsideUnit(t *testing.T) {
+	mockRepo := new(MockAchievementRepo)
+	mockStudentRepo := new(MockStudentRepo)
+	mockLecturerRepo := new(MockLecturerRepo)
+	svc := service.NewAchievementService(mockRepo, mockStudentRepo, mockLecturerRepo, new(MockPointRuleRepo), new(MockAchievementTypeRepo))
+
+	id, studentID := uuid.New(), uuid.New()
+	scopes := []models.UserScope{{Unit: models.UnitFaculty, UnitID: uuid.New()}}
+	admin := models.StatusActor{UserID: uuid.New(), Role: "Admin", Permissions: models.DefaultRolePermissions["Admin"], Scopes: scopes}
+
+	mockRepo.On("FindReferenceByID", id).Return(&models.AchievementReference{
+		ID: id, StudentID: studentID, Status: models.StatusVerified, PointsAwarded: 30,
+	}, nil)
+	mockStudentRepo.On("InUnits", studentID, scopes).Return(false, nil)
+	mockStudentRepo.On("FindByID", studentID).Return(&models.Student{ID: studentID}, nil)
+	mockLecturerRepo.On("FindByUserID", admin.UserID).Return(nil, gorm.ErrRecordNotFound)
+
+	err := svc.RevokeAchievement(context.Background(), id, admin, "Sertifikat palsu")
+
+	assert.Error(t, err)
+	mockRepo.AssertNotCalled(t, "Revoke", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Revoke langsung setelah verify (sebelum dispatcher jalan) tidak boleh membuat ledger minus:
+// award ditulis di transaksi Verify, jadi tidak ada event award yang tertinggal di outbox
+func TestRevokeBeforeDispatch_NoPendingAward(t *testing.T) {
+	mockRepo := new(MockAchievementRepo)
+	mockStudentRepo := new(MockStudentRepo)
+	mockLecturerRepo := new(MockLecturerRepo)
+	mockRuleRepo := new(MockPointRuleRepo)
+	svc := service.NewAchievementService(mockRepo, mockStudentRepo, mockLecturerRepo, mockRuleRepo, new(MockAchievementTypeRepo))
+
+	id, studentID, lecturerID := uuid.New(), uuid.New(), uuid.New()
+	mongoID := "657f1a2b3c4d5e6f7a8b9c0d"
+	advisor := models.StatusActor{UserID: uuid.New(), Role: "Dosen Wali", Permissions: models.DefaultRolePermissions["Dosen Wali"]}
+
+	mockRepo.On("FindReferenceByID", id).Return(&models.AchievementReference{
+		ID: id, StudentID: studentID, MongoAchievementID: mongoID, Status: models.StatusSubmitted,
+	}, nil).Once()
+	mockRepo.On("FindReferenceByID", id).Return(&models.AchievementReference{
+		ID: id, StudentID: studentID, MongoAchievementID: mongoID, Status: models.StatusVerified, PointsAwarded: 5,
+	}, nil).Once()
+	mockStudentRepo.On("FindByID", studentID).Return(&models.Student{ID: studentID, AdvisorID: &lecturerID}, nil)
+	mockLecturerRepo.On("FindByUserID", advisor.UserID).Return(&models.Lecturer{ID: lecturerID}, nil)
+	mockRepo.On("GetMongoDetail", mongoID).Return(&models.Achievement{}, nil)
+	mockRuleRepo.On("FindActive").Return([]models.PointRule{{Name: "Poin Dasar", Points: 5, IsActive: true}}, nil)
+
+	var outbox []models.OutboxEvent
+	capture := func(args mock.Arguments) { outbox = append(outbox, args.Get(3).([]models.OutboxEvent)...) }
+	mockRepo.On("Verify", id, advisor, 5, mock.Anything).Run(capture).Return(nil)
+	mockRepo.On("Revoke", id, "Sertifikat palsu", advisor, mock.Anything).Run(capture).Return(nil)
+
+	assert.NoError(t, svc.VerifyAchievement(context.Background(), id, advisor))
+	assert.NoError(t, svc.RevokeAchievement(context.Background(), id, advisor, "Sertifikat palsu"))
+
+	mockRepo.AssertExpectations(t)
+	assert.NotEmpty(t, outbox)
+	for _, e := range outbox {
+		assert.NotEqual(t, models.OutboxAwardPoints, e.Kind)
+	}
+}
+
+func TestRevokeAchievement_ReasonRequired(t *testing.T) {
+	mockRepo := new(MockAchievementRepo)
+	mockStudentRepo := new(MockStudentRepo)
+	mockLecturerRepo := new(MockLecturerRepo)
+	mockRuleRepo := new(MockPointRuleRepo)
+	mockTypeRepo := new(MockAchievementTypeRepo)
+	svc := service.NewAchievementService(mockRepo, mockStudentRepo, mockLecturerRepo, mockRuleRepo, mockTypeRepo)
+
+	id := uuid.New()
+	studentID := uuid.New()
+	admin := models.StatusActor{UserID: uuid.New(), Role: "Admin", Permissions: models.DefaultRolePermissions["Admin"]}
+
+	mockRepo.On("FindReferenceByID", id).Return(&models.AchievementReference{
+		ID: id, StudentID: studentID, Status: models.StatusVerified,
+	}, nil)
+	mockStudentRepo.On("FindByID", studentID).Return(&models.Student{ID: studentID}, nil)
+
+	err := svc.RevokeAchievement(context.Background(), id, admin, "")
+
+	assert.ErrorIs(t, err, service.ErrNoteRequired)
+	mockRepo.AssertNotCalled(t, "Revoke", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,98 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"gouas/app/models"
+	"gouas/app/service"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+var hackathonType = models.AchievementType{
+	Name:     "hackathon",
+	Label:    "Hackathon",
+	IsActive: true,
+	Schema: json.RawMessage(`{
+		"type": "object",
+		"required": ["teamName", "durationHours"],
+		"additionalProperties": false,
+		"properties": {
+			"teamName": { "type": "string", "minLength": 1 },
+			"durationHours": { "type": "integer", "minimum": 1 },
+			"track": { "type": "string", "enum": ["web", "mobile", "ai"] }
+		}
+	}`),
+}
+
+func newTypeTestService(typeRepo *MockAchievementTypeRepo, repo *MockAchievementRepo) service.AchievementService {
+	return service.NewAchievementService(repo, new(MockStudentRepo), new(MockLecturerRepo), new(MockPointRuleRepo), typeRepo)
+}
+
+func TestCreateAchievement_CustomTypeValidAgainstSchema(t *testing.T) {
+	mockTypeRepo := new(MockAchievementTypeRepo)
+	mockRepo := new(MockAchievementRepo)
+	svc := newTypeTestService(mockTypeRepo, mockRepo)
+
+	studentID := uuid.New()
+	data := models.Achievement{
+		Title: "Juara Hackathon", AchievementType: "hackathon", Points: 10,
+		Details: models.AchievementDetails{CustomFields: map[string]interface{}{
+			"teamName": "Kopi Senja", "durationHours": float64(24), "track": "ai",
+		}},
+	}
+
+	mockTypeRepo.On("FindByName", "hackathon").Return(&hackathonType, nil)
+	mockRepo.On("Create", data, studentID).Return(&models.AchievementReference{ID: uuid.New()}, nil)
+
+	_, err := svc.CreateAchievement(context.Background(), data, studentID)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestCreateAchievement_CustomTypeSchemaViolations(t *testing.T) {
+	mockTypeRepo := new(MockAchievementTypeRepo)
+	mockRepo := new(MockAchievementRepo)
+	svc := newTypeTestService(mockTypeRepo, mockRepo)
+
+	data := models.Achievement{
+		Title: "Juara Hackathon", AchievementType: "Hackathon", Points: 10,
+		Details: models.AchievementDetails{CustomFields: map[string]interface{}{
+			"durationHours": 1.5, "track": "blockchain", "sponsor": "X",
+		}},
+	}
+	mockTypeRepo.On("FindByName", "hackathon").Return(&hackathonType, nil)
+
+	_, err := svc.CreateAchievement(context.Background(), data, uuid.New())
+
+	var vErr *service.ValidationError
+	assert.True(t, errors.As(err, &vErr))
+	assert.ElementsMatch(t, []string{
+		"details.customFields.teamName",
+		"details.customFields.durationHours",
+		"details.customFields.track",
+		"details.customFields.sponsor",
+	}, fieldsOf(t, err))
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestCreateAchievement_UnregisteredTypeRejected(t *testing.T) {
+	mockTypeRepo := new(MockAchievementTypeRepo)
+	mockRepo := new(MockAchievementRepo)
+	svc := newTypeTestService(mockTypeRepo, mockRepo)
+
+	mockTypeRepo.On("FindByName", "patent").Return(nil, gorm.ErrRecordNotFound)
+
+	_, err := svc.CreateAchievement(context.Background(), models.Achievement{
+		Title: "Paten", AchievementType: "patent", Points: 10,
+	}, uuid.New())
+
+	assert.Equal(t, []string{"achievementType"}, fieldsOf(t, err))
+}
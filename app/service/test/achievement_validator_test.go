@@ -0,0 +1,101 @@
+package test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gouas/app/models"
+	"gouas/app/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fieldsOf(t *testing.T, err error) []string {
+	var vErr *service.ValidationError
+	if !errors.As(err, &vErr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	var fields []string
+	for _, f := range vErr.Fields {
+		fields = append(fields, f.Field)
+	}
+	return fields
+}
+
+func TestValidateAchievement_CompetitionRequiresNameAndLevel(t *testing.T) {
+	err := service.ValidateAchievement(models.Achievement{
+		Title: "Juara 1", AchievementType: "competition", Points: 10,
+	})
+
+	fields := fieldsOf(t, err)
+	assert.Contains(t, fields, "details.competitionName")
+	assert.Contains(t, fields, "details.competitionLevel")
+}
+
+func TestValidateAchievement_CompetitionEnums(t *testing.T) {
+	err := service.ValidateAchievement(models.Achievement{
+		Title: "Juara 1", AchievementType: "competition", Points: 10,
+		Details: models.AchievementDetails{
+			CompetitionName: "Gemastik", CompetitionLevel: "Galactic", MedalType: "Platinum",
+		},
+	})
+
+	fields := fieldsOf(t, err)
+	assert.ElementsMatch(t, []string{"details.competitionLevel", "details.medalType"}, fields)
+}
+
+func TestValidateAchievement_PublicationRequiresTitleAndAuthors(t *testing.T) {
+	err := service.ValidateAchievement(models.Achievement{
+		Title: "Paper", AchievementType: "publication", Points: 10,
+		Details: models.AchievementDetails{PublicationType: "journal"},
+	})
+
+	fields := fieldsOf(t, err)
+	assert.Contains(t, fields, "details.publicationTitle")
+	assert.Contains(t, fields, "details.authors")
+}
+
+func TestValidateAchievement_DateOrdering(t *testing.T) {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, -1, 0)
+
+	err := service.ValidateAchievement(models.Achievement{
+		Title: "Ketua BEM", AchievementType: "organization", Points: 10,
+		Details: models.AchievementDetails{
+			OrganizationName: "BEM", Position: "Ketua", StartDate: &start, EndDate: &end,
+		},
+	})
+
+	assert.Equal(t, []string{"details.endDate"}, fieldsOf(t, err))
+}
+
+func TestValidateAchievement_UnknownType(t *testing.T) {
+	err := service.ValidateAchievement(models.Achievement{
+		Title: "Sesuatu", AchievementType: "Kompetisi", Points: 10,
+	})
+
+	assert.Equal(t, []string{"achievementType"}, fieldsOf(t, err))
+}
+
+func TestValidateAchievement_ValidPublication(t *testing.T) {
+	err := service.ValidateAchievement(models.Achievement{
+		Title: "Paper", AchievementType: "publication", Points: 10,
+		Details: models.AchievementDetails{
+			PublicationTitle: "Deep Learning untuk Bahasa Daerah",
+			PublicationType:  "journal",
+			Authors:          []string{"Budi", "Siti"},
+			ISSN:             "0378-5955",
+		},
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestValidISSN(t *testing.T) {
+	assert.True(t, service.ValidISSN("0378-5955"))
+	assert.True(t, service.ValidISSN("2434-561X"))
+	assert.True(t, service.ValidISSN("2434561x"))
+	assert.False(t, service.ValidISSN("0378-5954"))
+	assert.False(t, service.ValidISSN("12345"))
+}
@@ -0,0 +1,91 @@
+package test
+
+import (
+	"testing"
+
+	"gouas/app/models"
+	"gouas/app/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAchievementWorkflow_TransitionTable(t *testing.T) {
+	wf := service.NewAchievementWorkflow()
+
+	cases := []struct {
+		name    string
+		action  service.AchievementAction
+		from    models.AchievementStatus
+		role    string
+		wantTo  models.AchievementStatus
+		wantErr error
+	}{
+		{"student submits draft", service.ActionSubmit, models.StatusDraft, "Mahasiswa", models.StatusSubmitted, nil},
+		{"student resubmits rejected", service.ActionSubmit, models.StatusRejected, "Mahasiswa", models.StatusSubmitted, nil},
+		{"submit twice", service.ActionSubmit, models.StatusSubmitted, "Mahasiswa", "", service.ErrInvalidTransition},
+		{"advisor cannot submit", service.ActionSubmit, models.StatusDraft, "Dosen Wali", "", service.ErrForbiddenTransition},
+		{"advisor verifies", service.ActionVerify, models.StatusSubmitted, "Dosen Wali", models.StatusVerified, nil},
+		{"verify draft", service.ActionVerify, models.StatusDraft, "Dosen Wali", "", service.ErrInvalidTransition},
+		{"student cannot verify", service.ActionVerify, models.StatusSubmitted, "Mahasiswa", "", service.ErrForbiddenTransition},
+		{"advisor rejects", service.ActionReject, models.StatusSubmitted, "Dosen Wali", models.StatusRejected, nil},
+		{"reject verified", service.ActionReject, models.StatusVerified, "Dosen Wali", "", service.ErrInvalidTransition},
+		{"student edits draft", service.ActionUpdate, models.StatusDraft, "Mahasiswa", "", nil},
+		{"student edits submitted", service.ActionUpdate, models.StatusSubmitted, "Mahasiswa", "", service.ErrInvalidTransition},
+		{"student deletes draft", service.ActionDelete, models.StatusDraft, "Mahasiswa", models.StatusDeleted, nil},
+		{"student deletes submitted", service.ActionDelete, models.StatusSubmitted, "Mahasiswa", "", service.ErrForbiddenTransition},
+		{"admin deletes submitted", service.ActionDelete, models.StatusSubmitted, "Admin", models.StatusDeleted, nil},
+		{"admin deletes verified", service.ActionDelete, models.StatusVerified, "Admin", models.StatusDeleted, nil},
+		{"student deletes verified", service.ActionDelete, models.StatusVerified, "Mahasiswa", "", service.ErrForbiddenTransition},
+		{"delete deleted", service.ActionDelete, models.StatusDeleted, "Admin", "", service.ErrInvalidTransition},
+		{"advisor revokes verified", service.ActionRevoke, models.StatusVerified, "Dosen Wali", models.StatusRevoked, nil},
+		{"admin revokes verified", service.ActionRevoke, models.StatusVerified, "Admin", models.StatusRevoked, nil},
+		{"student cannot revoke", service.ActionRevoke, models.StatusVerified, "Mahasiswa", "", service.ErrForbiddenTransition},
+		{"revoke submitted", service.ActionRevoke, models.StatusSubmitted, "Admin", "", service.ErrInvalidTransition},
+		{"revoke twice", service.ActionRevoke, models.StatusRevoked, "Admin", "", service.ErrInvalidTransition},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tr, err := wf.Resolve(tc.action, tc.from, models.DefaultRolePermissions[tc.role])
+			if tc.wantErr != nil {
+				assert.ErrorIs(t, err, tc.wantErr)
+				assert.Nil(t, tr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.wantTo, tr.To)
+		})
+	}
+}
+
+func TestAchievementWorkflow_VerifyAwardsPoints(t *testing.T) {
+	wf := service.NewAchievementWorkflow()
+
+	tr, err := wf.Resolve(service.ActionVerify, models.StatusSubmitted, models.DefaultRolePermissions["Dosen Wali"])
+
+	assert.NoError(t, err)
+	assert.Contains(t, tr.Effects, service.EffectAwardPoints)
+}
+
+func TestAchievementWorkflow_RejectRequiresNote(t *testing.T) {
+	wf := service.NewAchievementWorkflow()
+
+	tr, err := wf.Resolve(service.ActionReject, models.StatusSubmitted, models.DefaultRolePermissions["Dosen Wali"])
+
+	assert.NoError(t, err)
+	assert.True(t, tr.RequireNote)
+}
+
+func TestAchievementWorkflow_UsesPermissionsNotRoleNames(t *testing.T) {
+	wf := service.NewAchievementWorkflow()
+
+	// Role baru (mis. Kaprodi) cukup diberi permission, tanpa perubahan kode
+	kaprodi := []string{models.PermAchievementRead, models.PermAchievementVerify}
+
+	tr, err := wf.Resolve(service.ActionVerify, models.StatusSubmitted, kaprodi)
+	assert.NoError(t, err)
+	assert.Equal(t, models.StatusVerified, tr.To)
+
+	_, err = wf.Resolve(service.ActionRevoke, models.StatusVerified, kaprodi)
+	assert.ErrorIs(t, err, service.ErrForbiddenTransition)
+}
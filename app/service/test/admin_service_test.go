@@ -3,7 +3,9 @@ package test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"gouas/app/models"
+	"gouas/app/repository"
 	"gouas/app/service"
 	"net/http/httptest"
 	"testing"
@@ -12,34 +14,12 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
 )
 
-type MockAdminRepo struct {
-	mock.Mock
-}
-
-func (m *MockAdminRepo) CreateUser(user models.User) (models.User, error) {
-	args := m.Called(user)
-	return args.Get(0).(models.User), args.Error(1)
-}
-
-func (m *MockAdminRepo) FindRoleByName(name string) (models.Role, error) {
-	args := m.Called(name)
-	return args.Get(0).(models.Role), args.Error(1)
-}
-
-// Dummy methods to satisfy interface
-func (m *MockAdminRepo) UpdateUserRole(userID uuid.UUID, roleID uuid.UUID) error { return nil }
-func (m *MockAdminRepo) FindAllUsers() ([]models.User, error) { return nil, nil }
-func (m *MockAdminRepo) FindUserByID(id uuid.UUID) (*models.User, error) { return nil, nil }
-func (m *MockAdminRepo) UpdateUser(user models.User) error { return nil }
-func (m *MockAdminRepo) DeleteUser(id uuid.UUID) error { return nil }
-func (m *MockAdminRepo) CreateStudentProfile(student models.Student) error { return nil }
-func (m *MockAdminRepo) CreateLecturerProfile(lecturer models.Lecturer) error { return nil }
-
 func TestCreateUser_Success(t *testing.T) {
 	mockRepo := new(MockAdminRepo)
-	adminSvc := service.NewAdminService(mockRepo)
+	adminSvc := service.NewAdminService(mockRepo, new(MockOrganizationRepo), &MockUnitOfWork{Repos: repository.TxRepositories{Admin: mockRepo}})
 	app := fiber.New()
 	app.Post("/users", adminSvc.CreateUser)
 
@@ -47,7 +27,7 @@ func TestCreateUser_Success(t *testing.T) {
 	mockRole := models.Role{ID: roleID, Name: "Admin"}
 
 	mockRepo.On("FindRoleByName", "Admin").Return(mockRole, nil)
-	mockRepo.On("CreateUser", mock.AnythingOfType("models.User")).Return(models.User{
+	mockRepo.On("CreateUser", mock.MatchedBy(func(u models.User) bool { return u.MustChangePassword })).Return(models.User{
 		Username: "admin_baru",
 		RoleID:   roleID,
 	}, nil)
@@ -55,7 +35,7 @@ func TestCreateUser_Success(t *testing.T) {
 	reqBody := map[string]string{
 		"username": "admin_baru",
 		"email":    "admin@email.com",
-		"password": "pass123",
+		"password": "kopi susu di kantin",
 		"fullName": "Admin Baru",
 		"roleName": "Admin",
 	}
@@ -63,9 +43,56 @@ func TestCreateUser_Success(t *testing.T) {
 	req := httptest.NewRequest("POST", "/users", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := app.Test(req)
+	resp, err := app.Test(req, -1) // bcrypt cost 14 lebih lama dari timeout default 1 detik
 
 	assert.NoError(t, err)
 	assert.Equal(t, 201, resp.StatusCode)
 	mockRepo.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+func TestCreateUser_WeakPasswordRejected(t *testing.T) {
+	mockRepo := new(MockAdminRepo)
+	adminSvc := service.NewAdminService(mockRepo, new(MockOrganizationRepo), &MockUnitOfWork{Repos: repository.TxRepositories{Admin: mockRepo}})
+	app := fiber.New()
+	app.Post("/users", adminSvc.CreateUser)
+
+	status := postJSON(app, "/users", map[string]string{
+		"username": "admin_baru", "email": "admin@email.com", "password": "pass123", "fullName": "Admin Baru", "roleName": "Admin",
+	})
+
+	assert.Equal(t, 400, status)
+	mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
+
+func TestCreateUser_ProfileFailureRollsBack(t *testing.T) {
+	mockRepo := new(MockAdminRepo)
+	mockStudentRepo := new(MockStudentRepo)
+	uow := &MockUnitOfWork{Repos: repository.TxRepositories{Admin: mockRepo, Student: mockStudentRepo}}
+	adminSvc := service.NewAdminService(mockRepo, new(MockOrganizationRepo), uow)
+	app := fiber.New()
+	app.Post("/users", adminSvc.CreateUser)
+
+	userID := uuid.New()
+	mockRepo.On("FindRoleByName", "Mahasiswa").Return(models.Role{ID: uuid.New(), Name: "Mahasiswa"}, nil)
+	mockRepo.On("CreateUser", mock.AnythingOfType("models.User")).Return(models.User{ID: userID, Username: "mhs_baru"}, nil)
+	mockStudentRepo.On("FindByUserID", userID).Return(nil, gorm.ErrRecordNotFound)
+	mockRepo.On("CreateStudentProfile", mock.AnythingOfType("models.Student")).Return(errors.New("duplicate nim"))
+
+	body, _ := json.Marshal(map[string]string{
+		"username": "mhs_baru",
+		"email":    "mhs@email.com",
+		"password": "kopi susu di kantin",
+		"fullName": "Mahasiswa Baru",
+		"roleName": "Mahasiswa",
+	})
+	req := httptest.NewRequest("POST", "/users", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := app.Test(req, -1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 500, resp.StatusCode)
+	// Error dikembalikan ke unit of work sehingga pembuatan user ikut di-rollback
+	assert.ErrorContains(t, uow.LastError, "failed to create student profile")
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,203 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"gouas/app/models"
+	"gouas/app/service"
+	"gouas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAdvisorRepo struct {
+	mock.Mock
+	Outbox []models.OutboxEvent // event yang ditulis ApplyAssignments terakhir
+}
+
+func (m *MockAdvisorRepo) FindHistory(ctx context.Context, studentID uuid.UUID) ([]models.AdvisorAssignment, error) {
+	args := m.Called(studentID)
+	return args.Get(0).([]models.AdvisorAssignment), args.Error(1)
+}
+
+// ApplyAssignments melewati mahasiswa yang id-nya dikembalikan sebagai "sudah diubah request lain"
+func (m *MockAdvisorRepo) ApplyAssignments(ctx context.Context, assignments []models.AdvisorAssignment, outbox func(applied []models.AdvisorAssignment) ([]models.OutboxEvent, error)) ([]models.AdvisorAssignment, error) {
+	args := m.Called(assignments)
+	changed, _ := args.Get(0).([]uuid.UUID)
+	var applied []models.AdvisorAssignment
+	for _, a := range assignments {
+		if !containsUUID(changed, a.StudentID) {
+			applied = append(applied, a)
+		}
+	}
+	events, err := outbox(applied)
+	if err != nil {
+		return nil, err
+	}
+	m.Outbox = events
+	return applied, args.Error(1)
+}
+func (m *MockAdvisorRepo) CountAdvisees(ctx context.Context, lecturerIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	args := m.Called(lecturerIDs)
+	return args.Get(0).(map[uuid.UUID]int64), args.Error(1)
+}
+func (m *MockAdvisorRepo) FindActiveLecturers(ctx context.Context, departmentID uuid.UUID) ([]models.Lecturer, error) {
+	args := m.Called(departmentID)
+	return args.Get(0).([]models.Lecturer), args.Error(1)
+}
+func (m *MockAdvisorRepo) FindUnassignedStudents(ctx context.Context, departmentID uuid.UUID) ([]models.Student, error) {
+	args := m.Called(departmentID)
+	return args.Get(0).([]models.Student), args.Error(1)
+}
+func (m *MockAdvisorRepo) CountPendingSubmissions(ctx context.Context, studentIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	args := m.Called(studentIDs)
+	return args.Get(0).(map[uuid.UUID]int64), args.Error(1)
+}
+
+func newAdvisorApp() (*fiber.App, repoMocks) {
+	m := newRepoMocks()
+	svc := service.NewAdvisorService(m.advisor, m.student, m.lecturer)
+
+	app := fiber.New()
+	admin := &middleware.AuthResult{UserID: uuid.New().String(), Permissions: []string{models.PermAdvisorAssign}}
+	app.Put("/students/:id/advisor", withAuth(admin), svc.Assign)
+	app.Post("/lecturers/:id/reassign-advisees", withAuth(admin), svc.ReassignAdvisees)
+	app.Post("/advisors/balance", withAuth(admin), svc.Balance)
+	return app, m
+}
+
+func TestAssignAdvisor_InvalidAdvisorID(t *testing.T) {
+	app, m := newAdvisorApp()
+
+	status := putJSON(app, "/students/"+uuid.NewString()+"/advisor", map[string]string{"advisorId": "bukan-uuid"})
+
+	assert.Equal(t, 400, status)
+	m.advisor.AssertNotCalled(t, "ApplyAssignments", mock.Anything)
+}
+
+func TestAssignAdvisor_RejectsInactiveLecturer(t *testing.T) {
+	app, m := newAdvisorApp()
+	student := &models.Student{ID: uuid.New(), NIM: "2141720001"}
+	lecturer := &models.Lecturer{ID: uuid.New(), NIP: "198503152010121001", User: models.User{IsActive: false}}
+	m.student.On("FindByID", student.ID).Return(student, nil)
+	m.lecturer.On("FindByID", lecturer.ID).Return(lecturer, nil)
+
+	status := putJSON(app, "/students/"+student.ID.String()+"/advisor", map[string]string{"advisorId": lecturer.ID.String()})
+
+	assert.Equal(t, 400, status)
+	m.advisor.AssertNotCalled(t, "ApplyAssignments", mock.Anything)
+}
+
+func TestAssignAdvisor_RecordsHistoryAndNotifiesPendingSubmissions(t *testing.T) {
+	app, m := newAdvisorApp()
+	oldAdvisor := uuid.New()
+	student := &models.Student{ID: uuid.New(), NIM: "2141720001", AdvisorID: &oldAdvisor}
+	lecturer := &models.Lecturer{ID: uuid.New(), UserID: uuid.New(), NIP: "198503152010121001", User: models.User{IsActive: true}}
+	m.student.On("FindByID", student.ID).Return(student, nil)
+	m.lecturer.On("FindByID", lecturer.ID).Return(lecturer, nil)
+	m.advisor.On("CountPendingSubmissions", []uuid.UUID{student.ID}).Return(map[uuid.UUID]int64{student.ID: 2}, nil)
+	m.advisor.On("ApplyAssignments", mock.MatchedBy(func(a []models.AdvisorAssignment) bool {
+		return len(a) == 1 && a[0].LecturerID == lecturer.ID && *a[0].PreviousLecturerID == oldAdvisor && a[0].Reason == models.AssignManual
+	})).Return(nil, nil)
+
+	status := putJSON(app, "/students/"+student.ID.String()+"/advisor", map[string]string{"advisorId": lecturer.ID.String()})
+
+	assert.Equal(t, 200, status)
+	m.advisor.AssertExpectations(t)
+	if assert.Len(t, m.advisor.Outbox, 1) {
+		assert.Equal(t, models.OutboxNotify, m.advisor.Outbox[0].Kind)
+		assert.Equal(t, lecturer.ID, m.advisor.Outbox[0].AggregateID)
+	}
+}
+
+func TestAssignAdvisor_ConflictWhenAdvisorChangedConcurrently(t *testing.T) {
+	app, m := newAdvisorApp()
+	student := &models.Student{ID: uuid.New(), NIM: "2141720001"}
+	lecturer := &models.Lecturer{ID: uuid.New(), NIP: "198503152010121001", User: models.User{IsActive: true}}
+	m.student.On("FindByID", student.ID).Return(student, nil)
+	m.lecturer.On("FindByID", lecturer.ID).Return(lecturer, nil)
+	// advisor_id sudah diisi request lain setelah data mahasiswa dibaca
+	m.advisor.On("ApplyAssignments", mock.Anything).Return([]uuid.UUID{student.ID}, nil)
+
+	status := putJSON(app, "/students/"+student.ID.String()+"/advisor", map[string]string{"advisorId": lecturer.ID.String()})
+
+	assert.Equal(t, 409, status)
+	assert.Empty(t, m.advisor.Outbox)
+}
+
+func TestReassignAdvisees_NoPeerInDepartment(t *testing.T) {
+	app, m := newAdvisorApp()
+	dept := uuid.New()
+	leaving := &models.Lecturer{ID: uuid.New(), DepartmentID: &dept}
+	m.lecturer.On("FindByID", leaving.ID).Return(leaving, nil)
+	m.lecturer.On("FindAdvisees", leaving.ID).Return([]models.Student{{ID: uuid.New()}}, nil)
+	m.advisor.On("FindActiveLecturers", dept).Return([]models.Lecturer{*leaving}, nil)
+
+	status := postJSON(app, "/lecturers/"+leaving.ID.String()+"/reassign-advisees", map[string]string{})
+
+	assert.Equal(t, 400, status)
+	m.advisor.AssertNotCalled(t, "ApplyAssignments", mock.Anything)
+}
+
+func TestBalanceAdvisors_DryRunFillsLeastLoaded(t *testing.T) {
+	app, m := newAdvisorApp()
+	dept := uuid.New()
+	busy := models.Lecturer{ID: uuid.New(), NIP: "197801012005011001"}
+	idle := models.Lecturer{ID: uuid.New(), NIP: "198503152010121001"}
+	students := []models.Student{{ID: uuid.New(), NIM: "2141720001"}, {ID: uuid.New(), NIM: "2141720002"}, {ID: uuid.New(), NIM: "2141720003"}}
+	m.advisor.On("FindUnassignedStudents", dept).Return(students, nil)
+	m.advisor.On("FindActiveLecturers", dept).Return([]models.Lecturer{busy, idle}, nil)
+	m.advisor.On("CountAdvisees", mock.Anything).Return(map[uuid.UUID]int64{busy.ID: 3, idle.ID: 1}, nil)
+
+	body, _ := json.Marshal(map[string]string{"departmentId": dept.String()})
+	req := httptest.NewRequest("POST", "/advisors/balance?dryRun=true", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var plan []struct {
+		NIM string `json:"nim"`
+		NIP string `json:"nip"`
+	}
+	decodeData(t, resp, &plan)
+	// idle (1) -> 2 -> 3 = sama dengan busy, seri dimenangkan NIP terkecil (busy)
+	assert.Equal(t, []string{idle.NIP, idle.NIP, busy.NIP}, []string{plan[0].NIP, plan[1].NIP, plan[2].NIP})
+	m.advisor.AssertNotCalled(t, "ApplyAssignments", mock.Anything)
+}
+
+func TestBalanceAdvisors_SkipsStudentsAssignedConcurrently(t *testing.T) {
+	app, m := newAdvisorApp()
+	dept := uuid.New()
+	lecturer := models.Lecturer{ID: uuid.New(), NIP: "198503152010121001"}
+	taken, free := models.Student{ID: uuid.New(), NIM: "2141720001"}, models.Student{ID: uuid.New(), NIM: "2141720002"}
+	m.advisor.On("FindUnassignedStudents", dept).Return([]models.Student{taken, free}, nil)
+	m.advisor.On("FindActiveLecturers", dept).Return([]models.Lecturer{lecturer}, nil)
+	m.advisor.On("CountAdvisees", mock.Anything).Return(map[uuid.UUID]int64{}, nil)
+	m.advisor.On("CountPendingSubmissions", []uuid.UUID{free.ID}).Return(map[uuid.UUID]int64{}, nil)
+	// Balance lain sudah membagi mahasiswa `taken` lebih dulu
+	m.advisor.On("ApplyAssignments", mock.MatchedBy(func(a []models.AdvisorAssignment) bool {
+		return len(a) == 2 && a[0].PreviousLecturerID == nil && a[1].PreviousLecturerID == nil
+	})).Return([]uuid.UUID{taken.ID}, nil)
+
+	body, _ := json.Marshal(map[string]string{"departmentId": dept.String()})
+	req := httptest.NewRequest("POST", "/advisors/balance", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var plan []struct {
+		NIM string `json:"nim"`
+	}
+	decodeData(t, resp, &plan)
+	if assert.Len(t, plan, 1) {
+		assert.Equal(t, free.NIM, plan[0].NIM)
+	}
+	m.advisor.AssertExpectations(t)
+}
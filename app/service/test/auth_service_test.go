@@ -2,47 +2,35 @@ package test
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"gouas/app/models"
 	"gouas/app/service"
 	"gouas/helper"
+	"gouas/middleware"
+	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
-type MockAuthRepo struct {
-	mock.Mock
-}
-
-func (m *MockAuthRepo) FindByUsername(username string) (*models.User, error) {
-	args := m.Called(username)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.User), args.Error(1)
-}
-
-func (m *MockAuthRepo) FindByID(id uuid.UUID) (*models.User, error) {
-	args := m.Called(id)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.User), args.Error(1)
-}
-
-func (m *MockAuthRepo) UpdateTokenIDs(userID uuid.UUID, accessID *uuid.UUID, refreshID *uuid.UUID) error {
-	args := m.Called(userID, accessID, refreshID)
-	return args.Error(0)
-}
-
 func TestLogin_Success(t *testing.T) {
 	mockRepo := new(MockAuthRepo)
-	authSvc := service.NewAuthService(mockRepo)
+	authSvc := service.NewAuthService(mockRepo, cleanAttemptRepo(), service.DefaultLoginPolicy())
 	app := fiber.New()
 	app.Post("/login", authSvc.Login)
 
@@ -64,7 +52,11 @@ func TestLogin_Success(t *testing.T) {
 	}
 
 	mockRepo.On("FindByUsername", "mahasiswa1").Return(mockUser, nil)
-	mockRepo.On("UpdateTokenIDs", userID, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("CreateSession", mock.MatchedBy(func(s models.UserSession) bool {
+		return s.UserID == userID && s.Device == "Chrome on Android"
+	}), mock.MatchedBy(func(r models.RefreshToken) bool {
+		return r.ID != uuid.Nil && r.TokenHash != ""
+	})).Return(nil)
 
 	reqBody := map[string]string{
 		"username": "mahasiswa1",
@@ -73,10 +65,192 @@ func TestLogin_Success(t *testing.T) {
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest("POST", "/login", bytes.NewReader(body))
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Linux; Android 14) AppleWebKit/537.36 Chrome/126.0 Mobile Safari/537.36")
+
+	resp, err := app.Test(req, -1) // bcrypt cost 14 lebih lama dari timeout default 1 detik
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLogin_LongDeviceNameTruncatedByCharacter(t *testing.T) {
+	mockRepo := new(MockAuthRepo)
+	authSvc := service.NewAuthService(mockRepo, cleanAttemptRepo(), service.DefaultLoginPolicy())
+	app := fiber.New()
+	app.Post("/login", authSvc.Login)
+
+	hashed, _ := helper.HashPassword("password123")
+	userID := uuid.New()
+	mockRepo.On("FindByUsername", "mahasiswa1").Return(&models.User{
+		ID: userID, Username: "mahasiswa1", PasswordHash: hashed, IsActive: true, Role: models.Role{Name: "Mahasiswa"},
+	}, nil)
+	// 120 karakter multi-byte: dipotong jadi 100 karakter tanpa membelah UTF-8
+	mockRepo.On("CreateSession", mock.MatchedBy(func(s models.UserSession) bool {
+		return utf8.ValidString(s.Device) && s.Device == strings.Repeat("ü", 100)
+	}), mock.Anything).Return(nil)
+
+	body, _ := json.Marshal(map[string]string{"username": "mahasiswa1", "password": "password123", "device": strings.Repeat("ü", 120)})
+	req := httptest.NewRequest("POST", "/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := app.Test(req)
+	resp, err := app.Test(req, -1)
 
 	assert.NoError(t, err)
 	assert.Equal(t, 200, resp.StatusCode)
 	mockRepo.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+func newRefreshApp() (*fiber.App, *MockAuthRepo) {
+	mockRepo := new(MockAuthRepo)
+	authSvc := service.NewAuthService(mockRepo, cleanAttemptRepo(), service.DefaultLoginPolicy())
+	app := fiber.New()
+	app.Post("/refresh", authSvc.Refresh)
+	return app, mockRepo
+}
+
+func postRefresh(app *fiber.App, token string) *http.Response {
+	req := httptest.NewRequest("POST", "/refresh", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, _ := app.Test(req)
+	return resp
+}
+
+func TestRefresh_RejectsTokenNotMatchingFamily(t *testing.T) {
+	app, mockRepo := newRefreshApp()
+	userID, sessionID, tokenID := uuid.New(), uuid.New(), uuid.New()
+	token, _ := helper.GenerateRefreshToken(userID, tokenID, sessionID)
+	mockRepo.On("FindRefreshToken", tokenID).Return(&models.RefreshToken{ID: tokenID, SessionID: sessionID, TokenHash: helper.HashToken("token-lain")}, nil)
+
+	resp := postRefresh(app, token)
+
+	assert.Equal(t, 401, resp.StatusCode)
+	mockRepo.AssertNotCalled(t, "RotateRefreshToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefresh_RejectsAccessToken(t *testing.T) {
+	app, mockRepo := newRefreshApp()
+	token, _ := helper.GenerateAccessToken(uuid.New(), "Admin", nil, uuid.New(), uuid.New())
+
+	resp := postRefresh(app, token)
+
+	assert.Equal(t, 401, resp.StatusCode)
+	mockRepo.AssertNotCalled(t, "FindRefreshToken", mock.Anything)
+}
+
+func TestRefresh_RotatesToken(t *testing.T) {
+	app, mockRepo := newRefreshApp()
+	userID, sessionID, tokenID := uuid.New(), uuid.New(), uuid.New()
+	token, _ := helper.GenerateRefreshToken(userID, tokenID, sessionID)
+	mockRepo.On("FindRefreshToken", tokenID).Return(&models.RefreshToken{ID: tokenID, SessionID: sessionID, TokenHash: helper.HashToken(token)}, nil)
+	mockRepo.On("FindActiveSession", sessionID).Return(&models.UserSession{ID: sessionID, UserID: userID}, nil)
+	mockRepo.On("FindByID", userID).Return(&models.User{ID: userID, IsActive: true}, nil)
+	mockRepo.On("RotateRefreshToken", tokenID, mock.MatchedBy(func(r models.RefreshToken) bool {
+		return r.SessionID == sessionID && r.ID != tokenID
+	}), mock.Anything).Return(true, nil)
+
+	resp := postRefresh(app, token)
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var tokens struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	decodeData(t, resp, &tokens)
+	assert.NotEqual(t, token, tokens.RefreshToken)
+	claims, err := helper.ValidateJWT(tokens.RefreshToken, helper.TokenRefresh)
+	assert.NoError(t, err)
+	assert.Equal(t, sessionID, claims.SessionID)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRefresh_ReuseRevokesFamily(t *testing.T) {
+	app, mockRepo := newRefreshApp()
+	userID, sessionID, tokenID := uuid.New(), uuid.New(), uuid.New()
+	token, _ := helper.GenerateRefreshToken(userID, tokenID, sessionID)
+	rotatedAt := time.Now().Add(-time.Minute)
+	mockRepo.On("FindRefreshToken", tokenID).Return(&models.RefreshToken{ID: tokenID, SessionID: sessionID, TokenHash: helper.HashToken(token), RotatedAt: &rotatedAt}, nil)
+	mockRepo.On("RevokeSession", userID, sessionID).Return(true, nil)
+
+	resp := postRefresh(app, token)
+
+	assert.Equal(t, 401, resp.StatusCode)
+	mockRepo.AssertCalled(t, "RevokeSession", userID, sessionID)
+	mockRepo.AssertNotCalled(t, "RotateRefreshToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSessions_ListMarksCurrentAndRevokeIsOwnerOnly(t *testing.T) {
+	mockRepo := new(MockAuthRepo)
+	authSvc := service.NewAuthService(mockRepo, cleanAttemptRepo(), service.DefaultLoginPolicy())
+	userID, laptop, phone := uuid.New(), uuid.New(), uuid.New()
+	authData := &middleware.AuthResult{UserID: userID.String(), SessionID: laptop.String()}
+	app := fiber.New()
+	app.Get("/sessions", withAuth(authData), authSvc.ListSessions)
+	app.Delete("/sessions/:id", withAuth(authData), authSvc.RevokeSession)
+
+	mockRepo.On("FindActiveSessions", userID).Return([]models.UserSession{{ID: phone, Device: "Chrome on Android"}, {ID: laptop, Device: "Firefox on Windows"}}, nil)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/sessions", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+	var sessions []models.UserSession
+	decodeData(t, resp, &sessions)
+	assert.False(t, sessions[0].Current)
+	assert.True(t, sessions[1].Current)
+
+	// Session milik user lain tidak ditemukan untuk user ini
+	other := uuid.New()
+	mockRepo.On("RevokeSession", userID, other).Return(false, nil)
+	resp, _ = app.Test(httptest.NewRequest("DELETE", "/sessions/"+other.String(), nil))
+	assert.Equal(t, 404, resp.StatusCode)
+
+	mockRepo.On("RevokeSession", userID, phone).Return(true, nil)
+	resp, _ = app.Test(httptest.NewRequest("DELETE", "/sessions/"+phone.String(), nil))
+	assert.Equal(t, 200, resp.StatusCode)
+}
+
+func writeKeyPEM(t *testing.T, name, blockType string, der []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600))
+	return path
+}
+
+func TestJWTKeys_RotationKeepsOldKeyValid(t *testing.T) {
+	// Kembalikan ke mode HS256 default agar test lain tidak terpengaruh
+	t.Cleanup(func() { helper.LoadJWTKeys(helper.JWTKeyConfig{}) })
+
+	oldPub, oldPriv, _ := ed25519.GenerateKey(rand.Reader)
+	oldDER, _ := x509.MarshalPKCS8PrivateKey(oldPriv)
+	assert.NoError(t, helper.LoadJWTKeys(helper.JWTKeyConfig{PrivateKeyFile: writeKeyPEM(t, "old.pem", "PRIVATE KEY", oldDER)}))
+	oldToken, _ := helper.GenerateAccessToken(uuid.New(), "Admin", nil, uuid.New(), uuid.New())
+
+	// Rotasi: kunci baru RSA untuk tanda tangan, kunci lama hanya untuk verifikasi
+	newPriv, _ := rsa.GenerateKey(rand.Reader, 2048)
+	oldPubDER, _ := x509.MarshalPKIXPublicKey(oldPub)
+	assert.NoError(t, helper.LoadJWTKeys(helper.JWTKeyConfig{
+		PrivateKeyFile: writeKeyPEM(t, "new.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(newPriv)),
+		PublicKeyFiles: []string{writeKeyPEM(t, "old.pub", "PUBLIC KEY", oldPubDER)},
+	}))
+	newToken, _ := helper.GenerateAccessToken(uuid.New(), "Admin", nil, uuid.New(), uuid.New())
+
+	_, err := helper.ValidateJWT(oldToken, helper.TokenAccess)
+	assert.NoError(t, err)
+	_, err = helper.ValidateJWT(newToken, helper.TokenAccess)
+	assert.NoError(t, err)
+
+	jwks := helper.JWKS()["keys"].([]map[string]string)
+	assert.Len(t, jwks, 2)
+	assert.Equal(t, "RS256", jwks[0]["alg"])
+	assert.Equal(t, "EdDSA", jwks[1]["alg"])
+
+	// Token HS256 (secret default) tidak lagi diterima setelah pindah ke kunci asimetris
+	hs := jwt.NewWithClaims(jwt.SigningMethodHS256, helper.JWTClaims{UserID: uuid.New(), Type: helper.TokenAccess})
+	forged, _ := hs.SignedString([]byte("secret"))
+	_, err = helper.ValidateJWT(forged, helper.TokenAccess)
+	assert.Error(t, err)
+}
+
+func TestJWTKeys_ProductionRejectsDefaultSecret(t *testing.T) {
+	t.Cleanup(func() { helper.LoadJWTKeys(helper.JWTKeyConfig{}) })
+
+	assert.Error(t, helper.LoadJWTKeys(helper.JWTKeyConfig{Env: "production", Secret: "secret"}))
+	assert.Error(t, helper.LoadJWTKeys(helper.JWTKeyConfig{Env: "production"}))
+	assert.NoError(t, helper.LoadJWTKeys(helper.JWTKeyConfig{Env: "development"}))
+}
@@ -0,0 +1,85 @@
+package test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"gouas/app/models"
+	"gouas/app/service"
+	"gouas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReportRepo struct {
+	mock.Mock
+}
+
+func (m *MockReportRepo) GetAchievementStats(ctx context.Context, studentIDs []uuid.UUID) (map[string]interface{}, error) {
+	args := m.Called(studentIDs)
+	return args.Get(0).(map[string]interface{}), args.Error(1)
+}
+
+func TestGetStatistics_ScopedToProgram(t *testing.T) {
+	reportRepo := new(MockReportRepo)
+	studentRepo := new(MockStudentRepo)
+	svc := service.NewReportService(reportRepo, new(MockAchievementRepo), studentRepo, new(MockLecturerRepo))
+
+	informatika := uuid.New()
+	scopes := []models.UserScope{{Unit: models.UnitProgramStudy, UnitID: informatika}}
+	kaprodi := &middleware.AuthResult{
+		UserID:      uuid.New().String(),
+		Role:        "Kaprodi",
+		Permissions: models.DefaultRolePermissions["Kaprodi"],
+		Scopes:      scopes,
+	}
+	inProgram := models.Student{ID: uuid.New(), StudyProgramID: &informatika}
+	studentRepo.On("FindByUnits", scopes).Return([]models.Student{inProgram}, nil)
+	reportRepo.On("GetAchievementStats", []uuid.UUID{inProgram.ID}).Return(map[string]interface{}{}, nil)
+
+	app := fiber.New()
+	app.Get("/reports/statistics", withAuth(kaprodi), svc.GetStatistics)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/reports/statistics", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	reportRepo.AssertExpectations(t)
+}
+
+func TestGetStatistics_GlobalWithoutScopes(t *testing.T) {
+	reportRepo := new(MockReportRepo)
+	svc := service.NewReportService(reportRepo, new(MockAchievementRepo), new(MockStudentRepo), new(MockLecturerRepo))
+
+	admin := &middleware.AuthResult{UserID: uuid.New().String(), Role: "Admin", Permissions: models.DefaultRolePermissions["Admin"]}
+	reportRepo.On("GetAchievementStats", []uuid.UUID(nil)).Return(map[string]interface{}{}, nil)
+
+	app := fiber.New()
+	app.Get("/reports/statistics", withAuth(admin), svc.GetStatistics)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/reports/statistics", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	reportRepo.AssertExpectations(t)
+}
+
+func TestGetStudentReport_OutsideScopeForbidden(t *testing.T) {
+	studentRepo := new(MockStudentRepo)
+	svc := service.NewReportService(new(MockReportRepo), new(MockAchievementRepo), studentRepo, new(MockLecturerRepo))
+
+	kaprodi := &middleware.AuthResult{
+		UserID:      uuid.New().String(),
+		Permissions: models.DefaultRolePermissions["Kaprodi"],
+		Scopes:      []models.UserScope{{Unit: models.UnitProgramStudy, UnitID: uuid.New()}},
+	}
+	other := &models.Student{ID: uuid.New()}
+	studentRepo.On("FindByID", other.ID).Return(other, nil)
+	studentRepo.On("InUnits", other.ID, kaprodi.Scopes).Return(false, nil)
+
+	app := fiber.New()
+	app.Get("/reports/student/:id", withAuth(kaprodi), svc.GetStudentReport)
+	resp, _ := app.Test(httptest.NewRequest("GET", "/reports/student/"+other.ID.String(), nil))
+
+	assert.Equal(t, 403, resp.StatusCode)
+}
@@ -0,0 +1,634 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gouas/app/models"
+	"gouas/app/repository"
+	"gouas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// withAuth mensimulasikan middleware.Authenticate untuk test handler
+func withAuth(authData *middleware.AuthResult) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals("auth", authData)
+		return c.Next()
+	}
+}
+
+func postJSON(app *fiber.App, path string, payload interface{}) int {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req, -1) // tanpa timeout: handler password memakai bcrypt cost 14
+	return resp.StatusCode
+}
+
+func putJSON(app *fiber.App, path string, payload interface{}) int {
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("PUT", path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req, -1) // tanpa timeout: ganti email mengecek password (bcrypt)
+	return resp.StatusCode
+}
+
+func decodeData(t *testing.T, resp *http.Response, v interface{}) {
+	var out struct {
+		Data json.RawMessage `json:"data"`
+	}
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	assert.NoError(t, json.Unmarshal(out.Data, v))
+}
+
+func containsUUID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+// MockUnitOfWork menjalankan fn langsung dengan repository mock (tanpa transaksi nyata)
+type MockUnitOfWork struct {
+	Repos     repository.TxRepositories
+	LastError error
+}
+
+func (u *MockUnitOfWork) Do(ctx context.Context, fn func(repos repository.TxRepositories) error) error {
+	u.LastError = fn(u.Repos)
+	return u.LastError
+}
+
+type MockAdminRepo struct {
+	mock.Mock
+}
+
+func (m *MockAdminRepo) CreateUser(ctx context.Context, user models.User) (models.User, error) {
+	args := m.Called(user)
+	return args.Get(0).(models.User), args.Error(1)
+}
+func (m *MockAdminRepo) FindRoleByName(ctx context.Context, name string) (models.Role, error) {
+	args := m.Called(name)
+	return args.Get(0).(models.Role), args.Error(1)
+}
+
+// Dummy methods to satisfy interface
+func (m *MockAdminRepo) UpdateUserRole(ctx context.Context, userID uuid.UUID, roleID uuid.UUID) error {
+	return nil
+}
+func (m *MockAdminRepo) FindAllUsers(ctx context.Context) ([]models.User, error) { return nil, nil }
+func (m *MockAdminRepo) FindUserByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+func (m *MockAdminRepo) UpdateUser(ctx context.Context, user models.User) error { return nil }
+func (m *MockAdminRepo) DeleteUser(ctx context.Context, id uuid.UUID) error     { return nil }
+func (m *MockAdminRepo) CreateStudentProfile(ctx context.Context, student models.Student) error {
+	args := m.Called(student)
+	return args.Error(0)
+}
+func (m *MockAdminRepo) FindUserScopes(ctx context.Context, userID uuid.UUID) ([]models.UserScope, error) {
+	return nil, nil
+}
+func (m *MockAdminRepo) ReplaceUserScopes(ctx context.Context, userID uuid.UUID, scopes []models.UserScope) error {
+	args := m.Called(userID, scopes)
+	return args.Error(0)
+}
+func (m *MockAdminRepo) CreateLecturerProfile(ctx context.Context, lecturer models.Lecturer) error {
+	return nil
+}
+
+type MockAuthRepo struct {
+	mock.Mock
+}
+
+func (m *MockAuthRepo) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	args := m.Called(username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+func (m *MockAuthRepo) FindByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+func (m *MockAuthRepo) CreateSession(ctx context.Context, session models.UserSession, refresh models.RefreshToken) (models.UserSession, error) {
+	args := m.Called(session, refresh)
+	return session, args.Error(0)
+}
+func (m *MockAuthRepo) FindActiveSession(ctx context.Context, id uuid.UUID) (*models.UserSession, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.UserSession), args.Error(1)
+}
+func (m *MockAuthRepo) FindActiveSessions(ctx context.Context, userID uuid.UUID) ([]models.UserSession, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]models.UserSession), args.Error(1)
+}
+func (m *MockAuthRepo) FindRefreshToken(ctx context.Context, id uuid.UUID) (*models.RefreshToken, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RefreshToken), args.Error(1)
+}
+func (m *MockAuthRepo) RotateRefreshToken(ctx context.Context, oldID uuid.UUID, next models.RefreshToken, accessID uuid.UUID) (bool, error) {
+	args := m.Called(oldID, next, accessID)
+	return args.Bool(0), args.Error(1)
+}
+func (m *MockAuthRepo) RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uuid.UUID) (bool, error) {
+	args := m.Called(userID, sessionID)
+	return args.Bool(0), args.Error(1)
+}
+func (m *MockAuthRepo) RevokeAllSessions(ctx context.Context, userID uuid.UUID, exceptSessionID uuid.UUID) error {
+	args := m.Called(userID, exceptSessionID)
+	return args.Error(0)
+}
+func (m *MockAuthRepo) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	args := m.Called(email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+func (m *MockAuthRepo) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string, keepSessionID uuid.UUID) error {
+	args := m.Called(userID, passwordHash, keepSessionID)
+	return args.Error(0)
+}
+func (m *MockAuthRepo) CreateResetToken(ctx context.Context, token models.PasswordResetToken) error {
+	args := m.Called(token)
+	return args.Error(0)
+}
+func (m *MockAuthRepo) CountResetTokensSince(ctx context.Context, userID uuid.UUID, since time.Time) (int64, error) {
+	args := m.Called(userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *MockAuthRepo) FindResetToken(ctx context.Context, tokenHash string) (*models.PasswordResetToken, error) {
+	args := m.Called(tokenHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PasswordResetToken), args.Error(1)
+}
+func (m *MockAuthRepo) ResetPassword(ctx context.Context, tokenID uuid.UUID, passwordHash string) (bool, error) {
+	args := m.Called(tokenID, passwordHash)
+	return args.Bool(0), args.Error(1)
+}
+
+type MockLoginAttemptRepo struct {
+	mock.Mock
+}
+
+func (m *MockLoginAttemptRepo) Record(ctx context.Context, attempt models.LoginAttempt) error {
+	args := m.Called(attempt)
+	return args.Error(0)
+}
+
+// Reserve memanggil decide dengan counter (username, IP) yang di-set lewat On("Reserve", username)
+func (m *MockLoginAttemptRepo) Reserve(ctx context.Context, attempt models.LoginAttempt, since time.Time, decide func(username, ip repository.FailureStats) string) (models.LoginAttempt, error) {
+	args := m.Called(attempt.Username)
+	attempt.ID = uuid.New()
+	attempt.Result = decide(args.Get(0).(repository.FailureStats), args.Get(1).(repository.FailureStats))
+	if attempt.Result == "" {
+		attempt.Result = models.LoginPending
+	}
+	return attempt, args.Error(2)
+}
+func (m *MockLoginAttemptRepo) Complete(ctx context.Context, id uuid.UUID, result string, userID *uuid.UUID) error {
+	args := m.Called(result)
+	return args.Error(0)
+}
+func (m *MockLoginAttemptRepo) FindByUsername(ctx context.Context, username string, limit int) ([]models.LoginAttempt, error) {
+	args := m.Called(username, limit)
+	return args.Get(0).([]models.LoginAttempt), args.Error(1)
+}
+
+// cleanAttemptRepo: belum ada login gagal, semua audit diterima
+func cleanAttemptRepo() *MockLoginAttemptRepo {
+	repo := new(MockLoginAttemptRepo)
+	repo.On("Reserve", mock.Anything).Return(repository.FailureStats{}, repository.FailureStats{}, nil).Maybe()
+	repo.On("Complete", mock.Anything).Return(nil).Maybe()
+	repo.On("Record", mock.Anything).Return(nil).Maybe()
+	return repo
+}
+
+// --- MOCK STUDENT REPOSITORY ---
+type MockStudentRepo struct {
+	mock.Mock
+}
+
+func (m *MockStudentRepo) FindAll(ctx context.Context) ([]models.Student, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Student), args.Error(1)
+}
+func (m *MockStudentRepo) InUnits(ctx context.Context, studentID uuid.UUID, scopes []models.UserScope) (bool, error) {
+	args := m.Called(studentID, scopes)
+	return args.Bool(0), args.Error(1)
+}
+func (m *MockStudentRepo) FindByUnits(ctx context.Context, scopes []models.UserScope) ([]models.Student, error) {
+	args := m.Called(scopes)
+	return args.Get(0).([]models.Student), args.Error(1)
+}
+func (m *MockStudentRepo) FindByID(ctx context.Context, id uuid.UUID) (*models.Student, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Student), args.Error(1)
+}
+func (m *MockStudentRepo) FindByUserID(ctx context.Context, userID uuid.UUID) (*models.Student, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Student), args.Error(1)
+}
+
+// --- [BARU] MOCK LECTURER REPOSITORY ---
+type MockLecturerRepo struct {
+	mock.Mock
+}
+
+func (m *MockLecturerRepo) FindAll(ctx context.Context) ([]models.Lecturer, error) {
+	args := m.Called()
+	return args.Get(0).([]models.Lecturer), args.Error(1)
+}
+func (m *MockLecturerRepo) FindByID(ctx context.Context, id uuid.UUID) (*models.Lecturer, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Lecturer), args.Error(1)
+}
+func (m *MockLecturerRepo) FindByUserID(ctx context.Context, userID uuid.UUID) (*models.Lecturer, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Lecturer), args.Error(1)
+}
+func (m *MockLecturerRepo) FindAdvisees(ctx context.Context, lecturerID uuid.UUID) ([]models.Student, error) {
+	args := m.Called(lecturerID)
+	return args.Get(0).([]models.Student), args.Error(1)
+}
+
+type MockProfileRepo struct {
+	mock.Mock
+}
+
+func (m *MockProfileRepo) CreateStudent(ctx context.Context, student models.Student) (models.Student, error) {
+	args := m.Called(student)
+	return args.Get(0).(models.Student), args.Error(1)
+}
+func (m *MockProfileRepo) UpdateStudent(ctx context.Context, student models.Student) error {
+	args := m.Called(student)
+	return args.Error(0)
+}
+func (m *MockProfileRepo) DeleteStudent(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+func (m *MockProfileRepo) CountStudentRecords(ctx context.Context, studentID uuid.UUID) (int64, error) {
+	args := m.Called(studentID)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *MockProfileRepo) CreateLecturer(ctx context.Context, lecturer models.Lecturer) (models.Lecturer, error) {
+	args := m.Called(lecturer)
+	return args.Get(0).(models.Lecturer), args.Error(1)
+}
+func (m *MockProfileRepo) UpdateLecturer(ctx context.Context, lecturer models.Lecturer) error {
+	args := m.Called(lecturer)
+	return args.Error(0)
+}
+func (m *MockProfileRepo) DeleteLecturer(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+func (m *MockProfileRepo) NIMTaken(ctx context.Context, nim string, excludeID uuid.UUID) (bool, error) {
+	args := m.Called(nim, excludeID)
+	return args.Bool(0), args.Error(1)
+}
+func (m *MockProfileRepo) NIPTaken(ctx context.Context, nip string, excludeID uuid.UUID) (bool, error) {
+	args := m.Called(nip, excludeID)
+	return args.Bool(0), args.Error(1)
+}
+func (m *MockProfileRepo) EmailTaken(ctx context.Context, email string, excludeUserID uuid.UUID) (bool, error) {
+	args := m.Called(email, excludeUserID)
+	return args.Bool(0), args.Error(1)
+}
+func (m *MockProfileRepo) UsernameTaken(ctx context.Context, username string) (bool, error) {
+	args := m.Called(username)
+	return args.Bool(0), args.Error(1)
+}
+func (m *MockProfileRepo) TakenValues(ctx context.Context, field string, values []string) (map[string]bool, error) {
+	args := m.Called(field, values)
+	taken, _ := args.Get(0).(map[string]bool)
+	return taken, args.Error(1)
+}
+func (m *MockProfileRepo) UpdateContact(ctx context.Context, userID uuid.UUID, email string, phone string) error {
+	args := m.Called(userID, email, phone)
+	return args.Error(0)
+}
+
+type MockOrganizationRepo struct {
+	mock.Mock
+}
+
+func (m *MockOrganizationRepo) FindAllFaculties(ctx context.Context) ([]models.Faculty, error) {
+	return nil, nil
+}
+func (m *MockOrganizationRepo) FindFacultyByID(ctx context.Context, id uuid.UUID) (*models.Faculty, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Faculty), args.Error(1)
+}
+func (m *MockOrganizationRepo) CreateFaculty(ctx context.Context, faculty models.Faculty) (models.Faculty, error) {
+	args := m.Called(faculty)
+	return args.Get(0).(models.Faculty), args.Error(1)
+}
+func (m *MockOrganizationRepo) UpdateFaculty(ctx context.Context, faculty models.Faculty) error {
+	return nil
+}
+func (m *MockOrganizationRepo) DeleteFaculty(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+func (m *MockOrganizationRepo) FindAllDepartments(ctx context.Context, facultyID *uuid.UUID) ([]models.Department, error) {
+	return nil, nil
+}
+func (m *MockOrganizationRepo) FindDepartmentByID(ctx context.Context, id uuid.UUID) (*models.Department, error) {
+	return nil, nil
+}
+func (m *MockOrganizationRepo) CreateDepartment(ctx context.Context, dept models.Department) (models.Department, error) {
+	args := m.Called(dept)
+	return args.Get(0).(models.Department), args.Error(1)
+}
+func (m *MockOrganizationRepo) UpdateDepartment(ctx context.Context, dept models.Department) error {
+	return nil
+}
+func (m *MockOrganizationRepo) DeleteDepartment(ctx context.Context, id uuid.UUID) error { return nil }
+func (m *MockOrganizationRepo) FindAllStudyPrograms(ctx context.Context, departmentID *uuid.UUID) ([]models.StudyProgram, error) {
+	return nil, nil
+}
+func (m *MockOrganizationRepo) FindStudyProgramByID(ctx context.Context, id uuid.UUID) (*models.StudyProgram, error) {
+	return nil, nil
+}
+func (m *MockOrganizationRepo) CreateStudyProgram(ctx context.Context, program models.StudyProgram) (models.StudyProgram, error) {
+	return program, nil
+}
+func (m *MockOrganizationRepo) UpdateStudyProgram(ctx context.Context, program models.StudyProgram) error {
+	return nil
+}
+func (m *MockOrganizationRepo) DeleteStudyProgram(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+func (m *MockOrganizationRepo) NameTaken(ctx context.Context, unit string, parentID *uuid.UUID, name string, excludeID uuid.UUID) (bool, error) {
+	args := m.Called(unit, parentID, name, excludeID)
+	return args.Bool(0), args.Error(1)
+}
+func (m *MockOrganizationRepo) CountDependents(ctx context.Context, unit string, id uuid.UUID) (int64, error) {
+	args := m.Called(unit, id)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *MockOrganizationRepo) UnitExists(ctx context.Context, unit string, id uuid.UUID) (bool, error) {
+	args := m.Called(unit, id)
+	return args.Bool(0), args.Error(1)
+}
+func (m *MockOrganizationRepo) FindUnitNames(ctx context.Context, unit string) ([]repository.UnitName, error) {
+	args := m.Called(unit)
+	return args.Get(0).([]repository.UnitName), args.Error(1)
+}
+func (m *MockOrganizationRepo) MergeUnits(ctx context.Context, unit string, sourceID, targetID uuid.UUID) (int64, error) {
+	args := m.Called(unit, sourceID, targetID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// --- MOCK ACHIEVEMENT REPOSITORY ---
+type MockAchievementRepo struct {
+	mock.Mock
+	From []models.AchievementStatus // status asal terakhir yang diteruskan ke method transisi
+}
+
+func (m *MockAchievementRepo) Create(ctx context.Context, data models.Achievement, studentID uuid.UUID) (*models.AchievementReference, error) {
+	args := m.Called(data, studentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AchievementReference), args.Error(1)
+}
+func (m *MockAchievementRepo) FindReferenceByID(ctx context.Context, id uuid.UUID) (*models.AchievementReference, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AchievementReference), args.Error(1)
+}
+func (m *MockAchievementRepo) UpdateStatus(ctx context.Context, id uuid.UUID, from []models.AchievementStatus, status models.AchievementStatus, actor models.StatusActor, outbox []models.OutboxEvent) error {
+	m.From = from
+	args := m.Called(id, status, actor, outbox)
+	return args.Error(0)
+}
+func (m *MockAchievementRepo) Verify(ctx context.Context, id uuid.UUID, from []models.AchievementStatus, actor models.StatusActor, points int, outbox []models.OutboxEvent) error {
+	m.From = from
+	args := m.Called(id, actor, points, outbox)
+	return args.Error(0)
+}
+func (m *MockAchievementRepo) Revoke(ctx context.Context, id uuid.UUID, from []models.AchievementStatus, reason string, actor models.StatusActor, outbox []models.OutboxEvent) error {
+	m.From = from
+	args := m.Called(id, reason, actor, outbox)
+	return args.Error(0)
+}
+func (m *MockAchievementRepo) Reject(ctx context.Context, id uuid.UUID, from []models.AchievementStatus, note string, actor models.StatusActor, outbox []models.OutboxEvent) error {
+	m.From = from
+	args := m.Called(id, note, actor, outbox)
+	return args.Error(0)
+}
+func (m *MockAchievementRepo) AddAttachment(ctx context.Context, mongoID string, attachment models.Attachment) error {
+	args := m.Called(mongoID, attachment)
+	return args.Error(0)
+}
+func (m *MockAchievementRepo) SoftDelete(ctx context.Context, id uuid.UUID, from []models.AchievementStatus, actor models.StatusActor, outbox []models.OutboxEvent) error {
+	m.From = from
+	args := m.Called(id, actor, outbox)
+	return args.Error(0)
+}
+func (m *MockAchievementRepo) FindStatusEvents(ctx context.Context, id uuid.UUID) ([]models.AchievementStatusEvent, error) {
+	args := m.Called(id)
+	return args.Get(0).([]models.AchievementStatusEvent), args.Error(1)
+}
+func (m *MockAchievementRepo) FindReferencesByStudentID(ctx context.Context, studentID uuid.UUID) ([]models.AchievementReference, error) {
+	args := m.Called(studentID)
+	return args.Get(0).([]models.AchievementReference), args.Error(1)
+}
+func (m *MockAchievementRepo) FindReferences(ctx context.Context, filter repository.AchievementFilter) ([]models.AchievementReference, int64, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]models.AchievementReference), args.Get(1).(int64), args.Error(2)
+}
+func (m *MockAchievementRepo) GetMongoDetail(ctx context.Context, mongoID string) (*models.Achievement, error) {
+	args := m.Called(mongoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Achievement), args.Error(1)
+}
+func (m *MockAchievementRepo) SearchMongo(ctx context.Context, query string, studentIDs []uuid.UUID, limit int) ([]repository.SearchHit, error) {
+	args := m.Called(query, studentIDs, limit)
+	return args.Get(0).([]repository.SearchHit), args.Error(1)
+}
+func (m *MockAchievementRepo) FindReferencesByMongoIDs(ctx context.Context, mongoIDs []string) ([]models.AchievementReference, error) {
+	args := m.Called(mongoIDs)
+	return args.Get(0).([]models.AchievementReference), args.Error(1)
+}
+func (m *MockAchievementRepo) SyncMongoStatus(ctx context.Context, mongoID string, status models.AchievementStatus, pointsAwarded int) error {
+	args := m.Called(mongoID, status, pointsAwarded)
+	return args.Error(0)
+}
+func (m *MockAchievementRepo) UpdateMongo(ctx context.Context, mongoID string, data models.Achievement) error {
+	args := m.Called(mongoID, data)
+	return args.Error(0)
+}
+
+// --- MOCK POINT REPOSITORY ---
+type MockPointRepo struct {
+	mock.Mock
+}
+
+func (m *MockPointRepo) Award(ctx context.Context, studentID uuid.UUID, achievementID uuid.UUID, amount int, actor models.StatusActor) error {
+	args := m.Called(studentID, achievementID, amount, actor)
+	return args.Error(0)
+}
+func (m *MockPointRepo) FindByStudentID(ctx context.Context, studentID uuid.UUID) ([]models.PointTransaction, error) {
+	args := m.Called(studentID)
+	return args.Get(0).([]models.PointTransaction), args.Error(1)
+}
+func (m *MockPointRepo) Reconcile(ctx context.Context, studentID uuid.UUID) (int, error) {
+	args := m.Called(studentID)
+	return args.Int(0), args.Error(1)
+}
+
+// --- MOCK POINT RULE REPOSITORY ---
+type MockPointRuleRepo struct {
+	mock.Mock
+}
+
+func (m *MockPointRuleRepo) FindAll(ctx context.Context) ([]models.PointRule, error) {
+	args := m.Called()
+	return args.Get(0).([]models.PointRule), args.Error(1)
+}
+func (m *MockPointRuleRepo) FindActive(ctx context.Context) ([]models.PointRule, error) {
+	args := m.Called()
+	return args.Get(0).([]models.PointRule), args.Error(1)
+}
+func (m *MockPointRuleRepo) FindByID(ctx context.Context, id uuid.UUID) (*models.PointRule, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PointRule), args.Error(1)
+}
+func (m *MockPointRuleRepo) Create(ctx context.Context, rule models.PointRule) (*models.PointRule, error) {
+	args := m.Called(rule)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PointRule), args.Error(1)
+}
+func (m *MockPointRuleRepo) Update(ctx context.Context, rule models.PointRule) error {
+	args := m.Called(rule)
+	return args.Error(0)
+}
+
+// --- MOCK ACHIEVEMENT TYPE REPOSITORY ---
+type MockAchievementTypeRepo struct {
+	mock.Mock
+}
+
+func (m *MockAchievementTypeRepo) FindAll(ctx context.Context) ([]models.AchievementType, error) {
+	args := m.Called()
+	return args.Get(0).([]models.AchievementType), args.Error(1)
+}
+func (m *MockAchievementTypeRepo) FindByID(ctx context.Context, id uuid.UUID) (*models.AchievementType, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AchievementType), args.Error(1)
+}
+func (m *MockAchievementTypeRepo) FindByName(ctx context.Context, name string) (*models.AchievementType, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AchievementType), args.Error(1)
+}
+func (m *MockAchievementTypeRepo) Create(ctx context.Context, t models.AchievementType) (*models.AchievementType, error) {
+	args := m.Called(t)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AchievementType), args.Error(1)
+}
+func (m *MockAchievementTypeRepo) Update(ctx context.Context, t models.AchievementType) error {
+	args := m.Called(t)
+	return args.Error(0)
+}
+
+// --- MOCK NOTIFIER ---
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(userID uuid.UUID, subject string, message string) error {
+	args := m.Called(userID, subject, message)
+	return args.Error(0)
+}
+
+// repoMocks mengumpulkan mock repository yang dipakai app test per service;
+// uow menjalankan transaksi dengan mock yang sama
+type repoMocks struct {
+	admin    *MockAdminRepo
+	profile  *MockProfileRepo
+	student  *MockStudentRepo
+	lecturer *MockLecturerRepo
+	org      *MockOrganizationRepo
+	advisor  *MockAdvisorRepo
+	jobs     *MockImportJobRepo
+	uow      *MockUnitOfWork
+}
+
+func newRepoMocks() repoMocks {
+	m := repoMocks{
+		admin:    new(MockAdminRepo),
+		profile:  new(MockProfileRepo),
+		student:  new(MockStudentRepo),
+		lecturer: new(MockLecturerRepo),
+		org:      new(MockOrganizationRepo),
+		advisor:  new(MockAdvisorRepo),
+		jobs:     &MockImportJobRepo{finished: make(chan models.ImportJob, 1)},
+	}
+	m.uow = &MockUnitOfWork{Repos: repository.TxRepositories{Admin: m.admin, Student: m.student, Lecturer: m.lecturer, Advisor: m.advisor}}
+	return m
+}
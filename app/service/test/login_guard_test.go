@@ -0,0 +1,199 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"gouas/app/models"
+	"gouas/app/repository"
+	"gouas/app/service"
+	"gouas/helper"
+	"gouas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func postLogin(app *fiber.App, username, password string) *http.Response {
+	body, _ := json.Marshal(map[string]string{"username": username, "password": password})
+	req := httptest.NewRequest("POST", "/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req, -1)
+	return resp
+}
+
+func TestLogin_LockedUsernameSkipsPasswordCheck(t *testing.T) {
+	authRepo := new(MockAuthRepo)
+	attemptRepo := new(MockLoginAttemptRepo)
+	lastFail := time.Now().Add(-time.Minute)
+	attemptRepo.On("Reserve", "mahasiswa1").Return(repository.FailureStats{Count: 10, LastFail: &lastFail}, repository.FailureStats{}, nil)
+
+	app := fiber.New()
+	app.Post("/login", service.NewAuthService(authRepo, attemptRepo, service.DefaultLoginPolicy()).Login)
+
+	resp := postLogin(app, " Mahasiswa1 ", "whatever")
+
+	assert.Equal(t, fiber.StatusLocked, resp.StatusCode)
+	assert.NotEmpty(t, resp.Header.Get("Retry-After"))
+	authRepo.AssertNotCalled(t, "FindByUsername", mock.Anything)
+	attemptRepo.AssertExpectations(t)
+}
+
+func TestLogin_BackoffReturnsRetryAfter(t *testing.T) {
+	authRepo := new(MockAuthRepo)
+	attemptRepo := new(MockLoginAttemptRepo)
+	lastFail := time.Now()
+	// 5 kali gagal dengan BackoffAfter 3 -> jeda 4 detik
+	attemptRepo.On("Reserve", "mahasiswa1").Return(repository.FailureStats{Count: 5, LastFail: &lastFail}, repository.FailureStats{}, nil)
+
+	app := fiber.New()
+	app.Post("/login", service.NewAuthService(authRepo, attemptRepo, service.DefaultLoginPolicy()).Login)
+
+	resp := postLogin(app, "mahasiswa1", "whatever")
+
+	assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "4", resp.Header.Get("Retry-After"))
+	authRepo.AssertNotCalled(t, "FindByUsername", mock.Anything)
+}
+
+func TestLogin_InactiveAndUnknownUserGetSameMessage(t *testing.T) {
+	authRepo := new(MockAuthRepo)
+	attemptRepo := cleanAttemptRepo()
+	hashed, _ := helper.HashPassword("password123")
+	authRepo.On("FindByUsername", "nonaktif").Return(&models.User{ID: uuid.New(), Username: "nonaktif", PasswordHash: hashed}, nil)
+	authRepo.On("FindByUsername", "hantu").Return(nil, assert.AnError)
+
+	app := fiber.New()
+	app.Post("/login", service.NewAuthService(authRepo, attemptRepo, service.DefaultLoginPolicy()).Login)
+
+	var messages []string
+	for _, username := range []string{"nonaktif", "hantu"} {
+		resp := postLogin(app, username, "password123")
+		assert.Equal(t, 401, resp.StatusCode)
+		var body map[string]interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		messages = append(messages, body["message"].(string))
+	}
+	assert.Equal(t, messages[0], messages[1])
+	attemptRepo.AssertCalled(t, "Complete", models.LoginInactive)
+	attemptRepo.AssertCalled(t, "Complete", models.LoginInvalid)
+}
+
+func TestUnlockUser_RecordsAdminUnlock(t *testing.T) {
+	authRepo := new(MockAuthRepo)
+	attemptRepo := new(MockLoginAttemptRepo)
+	userID, adminID := uuid.New(), uuid.New()
+	authRepo.On("FindByID", userID).Return(&models.User{ID: userID, Username: "Mahasiswa1"}, nil)
+	attemptRepo.On("Record", mock.MatchedBy(func(a models.LoginAttempt) bool {
+		return a.Result == models.LoginAdminUnlock && a.Username == "mahasiswa1" && a.UserID != nil && *a.UserID == adminID
+	})).Return(nil)
+
+	app := fiber.New()
+	app.Post("/users/:id/unlock", withAuth(&middleware.AuthResult{UserID: adminID.String(), Permissions: []string{models.PermUserManage}}), service.NewAuthService(authRepo, attemptRepo, service.DefaultLoginPolicy()).UnlockUser)
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/users/"+userID.String()+"/unlock", nil))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	attemptRepo.AssertExpectations(t)
+}
+
+// serialAttemptRepo meniru Reserve di Postgres: counter dibaca & percobaan dicatat di bawah satu lock
+type serialAttemptRepo struct {
+	mu       sync.Mutex
+	attempts []models.LoginAttempt
+}
+
+func (r *serialAttemptRepo) Record(ctx context.Context, attempt models.LoginAttempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts = append(r.attempts, attempt)
+	return nil
+}
+
+func (r *serialAttemptRepo) Reserve(ctx context.Context, attempt models.LoginAttempt, since time.Time, decide func(username, ip repository.FailureStats) string) (models.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var user, ip repository.FailureStats
+	for i := range r.attempts {
+		a := r.attempts[i]
+		if a.Result != models.LoginPending && a.Result != models.LoginInvalid && a.Result != models.LoginInactive {
+			continue
+		}
+		if a.Username == attempt.Username {
+			user.Count++
+			user.LastFail = &r.attempts[i].CreatedAt
+		}
+		if a.IPAddress == attempt.IPAddress {
+			ip.Count++
+			ip.LastFail = &r.attempts[i].CreatedAt
+		}
+	}
+	attempt.ID = uuid.New()
+	attempt.CreatedAt = time.Now()
+	attempt.Result = decide(user, ip)
+	if attempt.Result == "" {
+		attempt.Result = models.LoginPending
+	}
+	r.attempts = append(r.attempts, attempt)
+	return attempt, nil
+}
+
+func (r *serialAttemptRepo) Complete(ctx context.Context, id uuid.UUID, result string, userID *uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.attempts {
+		if r.attempts[i].ID == id {
+			r.attempts[i].Result = result
+		}
+	}
+	return nil
+}
+
+func (r *serialAttemptRepo) FindByUsername(ctx context.Context, username string, limit int) ([]models.LoginAttempt, error) {
+	return nil, nil
+}
+
+// Tebakan paralel tidak boleh lolos bersamaan sebelum hasil bcrypt pertama tercatat
+func TestLogin_ConcurrentGuessesAreLimited(t *testing.T) {
+	authRepo := new(MockAuthRepo)
+	hashed, _ := helper.HashPassword("password123")
+	authRepo.On("FindByUsername", "mahasiswa1").Return(&models.User{ID: uuid.New(), Username: "mahasiswa1", PasswordHash: hashed, IsActive: true}, nil)
+
+	policy := service.DefaultLoginPolicy()
+	app := fiber.New()
+	app.Post("/login", service.NewAuthService(authRepo, &serialAttemptRepo{}, policy).Login)
+
+	const guesses = 12
+	statuses := make(chan int, guesses)
+	var wg sync.WaitGroup
+	for i := 0; i < guesses; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			statuses <- postLogin(app, "mahasiswa1", fmt.Sprintf("tebakan-%d", i)).StatusCode
+		}(i)
+	}
+	wg.Wait()
+	close(statuses)
+
+	checked := 0
+	for status := range statuses {
+		if status == 401 {
+			checked++
+		} else {
+			assert.Equal(t, fiber.StatusTooManyRequests, status)
+		}
+	}
+	// Hanya BackoffAfter tebakan pertama yang sampai ke pengecekan password
+	assert.Equal(t, policy.BackoffAfter, checked)
+}
@@ -0,0 +1,123 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"gouas/app/models"
+	"gouas/app/repository"
+	"gouas/app/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newOrgApp(repo *MockOrganizationRepo) *fiber.App {
+	svc := service.NewOrganizationService(repo)
+	app := fiber.New()
+	app.Post("/faculties", svc.CreateFaculty)
+	app.Delete("/faculties/:id", svc.DeleteFaculty)
+	app.Post("/departments", svc.CreateDepartment)
+	app.Get("/organization/duplicates", svc.GetDuplicates)
+	app.Post("/organization/merge", svc.MergeUnits)
+	return app
+}
+
+func TestCreateFaculty_NormalizesName(t *testing.T) {
+	repo := new(MockOrganizationRepo)
+	repo.On("NameTaken", models.UnitFaculty, (*uuid.UUID)(nil), "Fakultas Teknik", uuid.Nil).Return(false, nil)
+	repo.On("CreateFaculty", models.Faculty{Code: "FT", Name: "Fakultas Teknik"}).Return(models.Faculty{Name: "Fakultas Teknik"}, nil)
+
+	status := postJSON(newOrgApp(repo), "/faculties", map[string]string{"code": " ft", "name": "  Fakultas   Teknik "})
+
+	assert.Equal(t, 201, status)
+	repo.AssertExpectations(t)
+}
+
+func TestCreateFaculty_DuplicateNameRejected(t *testing.T) {
+	repo := new(MockOrganizationRepo)
+	// Nama dicek case-insensitive di repository
+	repo.On("NameTaken", models.UnitFaculty, (*uuid.UUID)(nil), "fakultas teknik", uuid.Nil).Return(true, nil)
+
+	status := postJSON(newOrgApp(repo), "/faculties", map[string]string{"name": "fakultas teknik"})
+
+	assert.Equal(t, 409, status)
+	repo.AssertNotCalled(t, "CreateFaculty", mock.Anything)
+}
+
+func TestCreateDepartment_RequiresExistingFaculty(t *testing.T) {
+	repo := new(MockOrganizationRepo)
+	facultyID := uuid.New()
+	repo.On("UnitExists", models.UnitFaculty, facultyID).Return(false, nil)
+
+	app := newOrgApp(repo)
+	assert.Equal(t, 400, postJSON(app, "/departments", map[string]string{"name": "Teknik Elektro"}))
+	assert.Equal(t, 400, postJSON(app, "/departments", map[string]string{"name": "Teknik Elektro", "facultyId": facultyID.String()}))
+	repo.AssertNotCalled(t, "CreateDepartment", mock.Anything)
+}
+
+func TestCreateDepartment_NameUniqueWithinFaculty(t *testing.T) {
+	repo := new(MockOrganizationRepo)
+	facultyID := uuid.New()
+	repo.On("UnitExists", models.UnitFaculty, facultyID).Return(true, nil)
+	// "Informatika" di fakultas lain tidak bentrok: nama dicek di bawah fakultas yang dipilih
+	repo.On("NameTaken", models.UnitDepartment, &facultyID, "Informatika", uuid.Nil).Return(false, nil)
+	repo.On("CreateDepartment", mock.AnythingOfType("models.Department")).Return(models.Department{Name: "Informatika"}, nil)
+
+	status := postJSON(newOrgApp(repo), "/departments", map[string]string{"name": "Informatika", "facultyId": facultyID.String()})
+
+	assert.Equal(t, 201, status)
+	repo.AssertExpectations(t)
+}
+
+func TestDeleteFaculty_InUseRejected(t *testing.T) {
+	repo := new(MockOrganizationRepo)
+	id := uuid.New()
+	repo.On("FindFacultyByID", id).Return(&models.Faculty{ID: id, Name: "Fakultas Teknik"}, nil)
+	repo.On("CountDependents", models.UnitFaculty, id).Return(int64(3), nil)
+
+	resp, _ := newOrgApp(repo).Test(httptest.NewRequest("DELETE", "/faculties/"+id.String(), nil))
+
+	assert.Equal(t, 409, resp.StatusCode)
+	repo.AssertNotCalled(t, "DeleteFaculty", mock.Anything)
+}
+
+func TestGetDuplicates_FlagsTyposAndSuggestsBusierTarget(t *testing.T) {
+	repo := new(MockOrganizationRepo)
+	correct := repository.UnitName{ID: uuid.New(), Name: "Informatika"}
+	typo := repository.UnitName{ID: uuid.New(), Name: "Informatka"}
+	other := repository.UnitName{ID: uuid.New(), Name: "Sistem Informasi"}
+	repo.On("FindUnitNames", models.UnitProgramStudy).Return([]repository.UnitName{typo, correct, other}, nil)
+	repo.On("CountDependents", models.UnitProgramStudy, correct.ID).Return(int64(120), nil)
+	repo.On("CountDependents", models.UnitProgramStudy, typo.ID).Return(int64(3), nil)
+
+	resp, _ := newOrgApp(repo).Test(httptest.NewRequest("GET", "/organization/duplicates?unit=program_study", nil))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var candidates []struct {
+		Source   struct{ ID uuid.UUID } `json:"source"`
+		Target   struct{ ID uuid.UUID } `json:"target"`
+		Distance int                    `json:"distance"`
+	}
+	decodeData(t, resp, &candidates)
+	if assert.Len(t, candidates, 1) {
+		assert.Equal(t, typo.ID, candidates[0].Source.ID)
+		assert.Equal(t, correct.ID, candidates[0].Target.ID)
+		assert.Equal(t, 1, candidates[0].Distance)
+	}
+}
+
+func TestMergeUnits_MovesReferencesToTarget(t *testing.T) {
+	repo := new(MockOrganizationRepo)
+	source, target := uuid.New(), uuid.New()
+	repo.On("UnitExists", models.UnitProgramStudy, source).Return(true, nil)
+	repo.On("UnitExists", models.UnitProgramStudy, target).Return(true, nil)
+	repo.On("MergeUnits", models.UnitProgramStudy, source, target).Return(int64(3), nil)
+	app := newOrgApp(repo)
+
+	assert.Equal(t, 200, postJSON(app, "/organization/merge", map[string]string{"unit": "program_study", "sourceId": source.String(), "targetId": target.String()}))
+	assert.Equal(t, 400, postJSON(app, "/organization/merge", map[string]string{"unit": "program_study", "sourceId": source.String(), "targetId": source.String()}))
+	repo.AssertNumberOfCalls(t, "MergeUnits", 1)
+}
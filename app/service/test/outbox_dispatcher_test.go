@@ -0,0 +1,145 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"gouas/app/models"
+	"gouas/app/service"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// --- MOCK OUTBOX REPOSITORY ---
+type MockOutboxRepo struct{ mock.Mock }
+
+func (m *MockOutboxRepo) Claim(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	args := m.Called(limit, lease)
+	return args.Get(0).([]models.OutboxEvent), args.Error(1)
+}
+func (m *MockOutboxRepo) MarkDone(ctx context.Context, id uuid.UUID) error {
+	return m.Called(id).Error(0)
+}
+func (m *MockOutboxRepo) MarkRetry(ctx context.Context, id uuid.UUID, errMsg string, nextAttempt *time.Time) error {
+	return m.Called(id, errMsg, nextAttempt).Error(0)
+}
+func (m *MockOutboxRepo) FindFailed(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	args := m.Called(limit)
+	return args.Get(0).([]models.OutboxEvent), args.Error(1)
+}
+func (m *MockOutboxRepo) Requeue(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(id)
+	return args.Bool(0), args.Error(1)
+}
+
+func outboxEvent(t *testing.T, kind string, payload interface{}, attempts int) models.OutboxEvent {
+	e, err := models.NewOutboxEvent(kind, uuid.New(), payload)
+	assert.NoError(t, err)
+	e.ID = uuid.New()
+	e.Attempts = attempts
+	return e
+}
+
+func TestOutboxDispatcher_AppliesEvents(t *testing.T) {
+	outboxRepo := new(MockOutboxRepo)
+	pointRepo := new(MockPointRepo)
+	achRepo := new(MockAchievementRepo)
+	notifier := new(MockNotifier)
+
+	award := models.AwardPointsPayload{StudentID: uuid.New(), AchievementRefID: uuid.New(), Amount: 30, ActorID: uuid.New(), ActorRole: "Dosen Wali"}
+	notify := models.NotifyPayload{UserID: uuid.New(), Subject: "Achievement verified", Message: "ok"}
+	sync := models.SyncMongoPayload{MongoID: "657f1a2b3c4d5e6f7a8b9c0d", Status: models.StatusVerified, PointsAwarded: 30}
+	events := []models.OutboxEvent{
+		outboxEvent(t, models.OutboxAwardPoints, award, 1),
+		outboxEvent(t, models.OutboxNotify, notify, 1),
+		outboxEvent(t, models.OutboxSyncMongo, sync, 1),
+	}
+
+	outboxRepo.On("Claim", mock.Anything, mock.Anything).Return(events, nil)
+	outboxRepo.On("MarkDone", mock.Anything).Return(nil)
+	pointRepo.On("Award", award.StudentID, award.AchievementRefID, 30, models.StatusActor{UserID: award.ActorID, Role: "Dosen Wali"}).Return(nil)
+	notifier.On("Notify", notify.UserID, notify.Subject, notify.Message).Return(nil)
+	achRepo.On("SyncMongoStatus", sync.MongoID, models.StatusVerified, 30).Return(nil)
+
+	d := service.NewOutboxDispatcher(outboxRepo, pointRepo, achRepo, notifier)
+	done, err := d.DispatchOnce(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, done)
+	outboxRepo.AssertNumberOfCalls(t, "MarkDone", 3)
+	pointRepo.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+	achRepo.AssertExpectations(t)
+}
+
+func TestOutboxDispatcher_RetriesWithBackoff(t *testing.T) {
+	outboxRepo := new(MockOutboxRepo)
+	pointRepo := new(MockPointRepo)
+
+	award := models.AwardPointsPayload{StudentID: uuid.New(), AchievementRefID: uuid.New(), Amount: 10}
+	retry := outboxEvent(t, models.OutboxAwardPoints, award, 3)
+	exhausted := outboxEvent(t, models.OutboxAwardPoints, award, 10)
+
+	outboxRepo.On("Claim", mock.Anything, mock.Anything).Return([]models.OutboxEvent{retry, exhausted}, nil)
+	pointRepo.On("Award", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("db down"))
+	var next *time.Time
+	outboxRepo.On("MarkRetry", retry.ID, "db down", mock.Anything).
+		Run(func(args mock.Arguments) { next = args.Get(2).(*time.Time) }).Return(nil)
+	outboxRepo.On("MarkRetry", exhausted.ID, "db down", (*time.Time)(nil)).Return(nil)
+
+	d := service.NewOutboxDispatcher(outboxRepo, pointRepo, new(MockAchievementRepo), new(MockNotifier))
+	done, err := d.DispatchOnce(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, done)
+	// Percobaan ke-3: 10s * 2^2
+	if assert.NotNil(t, next) {
+		assert.WithinDuration(t, time.Now().Add(40*time.Second), *next, 2*time.Second)
+	}
+	outboxRepo.AssertExpectations(t)
+	outboxRepo.AssertNotCalled(t, "MarkDone", mock.Anything)
+}
+
+func TestOutbox_FailedAwardEventCanBeRetried(t *testing.T) {
+	outboxRepo := new(MockOutboxRepo)
+	pointRepo := new(MockPointRepo)
+
+	award := models.AwardPointsPayload{StudentID: uuid.New(), AchievementRefID: uuid.New(), Amount: 10}
+	exhausted := outboxEvent(t, models.OutboxAwardPoints, award, 10)
+	outboxRepo.On("Claim", mock.Anything, mock.Anything).Return([]models.OutboxEvent{exhausted}, nil)
+	pointRepo.On("Award", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("db down"))
+	outboxRepo.On("MarkRetry", exhausted.ID, "db down", (*time.Time)(nil)).Return(nil)
+
+	// 1. Batas retry habis -> event failed, bukan hilang
+	_, err := service.NewOutboxDispatcher(outboxRepo, pointRepo, new(MockAchievementRepo), new(MockNotifier)).DispatchOnce(context.Background())
+	assert.NoError(t, err)
+
+	// 2. Admin melihat & mengantrekan ulang event tersebut
+	exhausted.Status = models.OutboxFailed
+	outboxRepo.On("FindFailed", 100).Return([]models.OutboxEvent{exhausted}, nil)
+	outboxRepo.On("Requeue", exhausted.ID).Return(true, nil)
+	outboxRepo.On("Requeue", mock.Anything).Return(false, nil)
+
+	svc := service.NewOutboxService(outboxRepo)
+	app := fiber.New()
+	app.Get("/outbox/failed", svc.GetFailed)
+	app.Post("/outbox/:id/retry", svc.Retry)
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/outbox/failed", nil))
+	var failed []models.OutboxEvent
+	decodeData(t, resp, &failed)
+	if assert.Len(t, failed, 1) {
+		assert.Equal(t, exhausted.ID, failed[0].ID)
+	}
+
+	resp, _ = app.Test(httptest.NewRequest("POST", "/outbox/"+exhausted.ID.String()+"/retry", nil))
+	assert.Equal(t, 200, resp.StatusCode)
+	resp, _ = app.Test(httptest.NewRequest("POST", "/outbox/"+uuid.NewString()+"/retry", nil))
+	assert.Equal(t, 404, resp.StatusCode)
+	outboxRepo.AssertExpectations(t)
+}
@@ -0,0 +1,153 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"gouas/app/models"
+	"gouas/app/repository"
+	"gouas/app/service"
+	"gouas/helper"
+	"gouas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mailMessage struct {
+	To, Subject, Body string
+}
+
+type MockMailer struct {
+	sent chan mailMessage
+}
+
+func (m *MockMailer) Send(to string, subject string, body string) error {
+	m.sent <- mailMessage{to, subject, body}
+	return nil
+}
+
+func newPasswordApp(authData *middleware.AuthResult) (*fiber.App, *MockAuthRepo, *MockMailer) {
+	repo := new(MockAuthRepo)
+	mailer := &MockMailer{sent: make(chan mailMessage, 1)}
+	svc := service.NewPasswordService(repo, cleanAttemptRepo(), service.DefaultLoginPolicy(), mailer, 30*time.Minute, "https://prestasi.kampus.ac.id/reset?token=")
+
+	app := fiber.New()
+	app.Post("/auth/change-password", withAuth(authData), svc.ChangePassword)
+	app.Post("/auth/forgot-password", svc.ForgotPassword)
+	app.Post("/auth/reset-password", svc.ResetPassword)
+	return app, repo, mailer
+}
+
+func TestValidatePassword_Policy(t *testing.T) {
+	t.Cleanup(func() { helper.LoadPasswordPolicy(helper.PasswordPolicyConfig{MinLength: 8}) })
+	list := filepath.Join(t.TempDir(), "breached.txt")
+	// satu password plaintext & satu SHA-1 format HIBP (sha1("qwertyuiop"))
+	os.WriteFile(list, []byte("password123\nB0399D2029F64D445BD131FFAA399A42D2F8E7DC:3810555\n"), 0600)
+	assert.NoError(t, helper.LoadPasswordPolicy(helper.PasswordPolicyConfig{MinLength: 10, BreachedListFile: list}))
+
+	assert.Error(t, helper.ValidatePassword("Pendek1!", "budi"), "kurang dari 10")
+	assert.Error(t, helper.ValidatePassword("password123", "budi"), "breached (plaintext)")
+	assert.Error(t, helper.ValidatePassword("qwertyuiop", "budi"), "breached (sha1)")
+	assert.Error(t, helper.ValidatePassword("Budi-2024-rahasia", "budi"), "mengandung username")
+	assert.NoError(t, helper.ValidatePassword("kopi susu di kantin", "budi"))
+}
+
+func TestChangePassword_KeepsCurrentSession(t *testing.T) {
+	hash, _ := helper.HashPassword("admin123")
+	user := &models.User{ID: uuid.New(), Username: "admin", PasswordHash: hash, MustChangePassword: true}
+	sessionID := uuid.New()
+	app, repo, _ := newPasswordApp(&middleware.AuthResult{UserID: user.ID.String(), SessionID: sessionID.String(), MustChangePassword: true})
+	repo.On("FindByID", user.ID).Return(user, nil)
+	repo.On("UpdatePassword", user.ID, mock.AnythingOfType("string"), sessionID).Return(nil)
+
+	status := postJSON(app, "/auth/change-password", map[string]string{"currentPassword": "admin123", "newPassword": "kopi susu di kantin"})
+
+	assert.Equal(t, 200, status)
+	repo.AssertExpectations(t)
+}
+
+func TestChangePassword_WrongCurrentPassword(t *testing.T) {
+	hash, _ := helper.HashPassword("admin123")
+	user := &models.User{ID: uuid.New(), Username: "admin", PasswordHash: hash}
+	app, repo, _ := newPasswordApp(&middleware.AuthResult{UserID: user.ID.String(), SessionID: uuid.NewString()})
+	repo.On("FindByID", user.ID).Return(user, nil)
+
+	status := postJSON(app, "/auth/change-password", map[string]string{"currentPassword": "salah", "newPassword": "kopi susu di kantin"})
+
+	assert.Equal(t, 400, status)
+	repo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChangePassword_FailedChecksShareLoginCounter(t *testing.T) {
+	hash, _ := helper.HashPassword("admin123")
+	user := &models.User{ID: uuid.New(), Username: "Admin", PasswordHash: hash}
+	repo := new(MockAuthRepo)
+	repo.On("FindByID", user.ID).Return(user, nil)
+	lastFail := time.Now()
+	attempts := new(MockLoginAttemptRepo)
+	attempts.On("Reserve", "admin").Return(repository.FailureStats{}, repository.FailureStats{}, nil).Once()
+	attempts.On("Complete", models.LoginInvalid).Return(nil).Once()
+	// Tebakan berikutnya sudah jauh melewati BackoffAfter (jeda > 1 menit): ditolak sebelum bcrypt
+	attempts.On("Reserve", "admin").Return(repository.FailureStats{Count: 9, LastFail: &lastFail}, repository.FailureStats{}, nil).Once()
+	svc := service.NewPasswordService(repo, attempts, service.DefaultLoginPolicy(), &MockMailer{}, 30*time.Minute, "")
+	app := fiber.New()
+	app.Post("/auth/change-password", withAuth(&middleware.AuthResult{UserID: user.ID.String(), SessionID: uuid.NewString()}), svc.ChangePassword)
+	body := map[string]string{"currentPassword": "salah", "newPassword": "kopi susu di kantin"}
+
+	assert.Equal(t, 400, postJSON(app, "/auth/change-password", body))
+	assert.Equal(t, 429, postJSON(app, "/auth/change-password", body))
+	attempts.AssertExpectations(t)
+	repo.AssertNotCalled(t, "UpdatePassword", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestForgotPassword_UnknownEmailLooksTheSame(t *testing.T) {
+	app, repo, mailer := newPasswordApp(nil)
+	repo.On("FindByEmail", "tidakada@kampus.ac.id").Return(nil, assert.AnError)
+
+	status := postJSON(app, "/auth/forgot-password", map[string]string{"email": "tidakada@kampus.ac.id"})
+
+	assert.Equal(t, 200, status)
+	repo.AssertNotCalled(t, "CreateResetToken", mock.Anything)
+	assert.Empty(t, mailer.sent)
+}
+
+func TestForgotThenReset_TokenFromEmailIsHashedAndSingleUse(t *testing.T) {
+	app, repo, mailer := newPasswordApp(nil)
+	user := &models.User{ID: uuid.New(), Username: "budi", Email: "budi@kampus.ac.id", FullName: "Budi", IsActive: true}
+	var stored models.PasswordResetToken
+	repo.On("FindByEmail", "budi@kampus.ac.id").Return(user, nil)
+	repo.On("CountResetTokensSince", user.ID).Return(int64(0), nil)
+	repo.On("CreateResetToken", mock.Anything).Run(func(args mock.Arguments) {
+		stored = args.Get(0).(models.PasswordResetToken)
+	}).Return(nil)
+
+	assert.Equal(t, 200, postJSON(app, "/auth/forgot-password", map[string]string{"email": "budi@kampus.ac.id"}))
+
+	var msg mailMessage
+	select {
+	case msg = <-mailer.sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("reset email was not sent")
+	}
+	assert.Equal(t, "budi@kampus.ac.id", msg.To)
+	token := regexp.MustCompile(`reset\?token=(\S+)`).FindStringSubmatch(msg.Body)[1]
+	assert.NotEqual(t, token, stored.TokenHash, "token mentah tidak boleh disimpan")
+	assert.Equal(t, helper.HashToken(token), stored.TokenHash)
+
+	stored.ID = uuid.New()
+	stored.User = *user
+	repo.On("FindResetToken", stored.TokenHash).Return(&stored, nil)
+	// Request kedua dengan token yang sama kalah saat klaim atomic
+	repo.On("ResetPassword", stored.ID, mock.AnythingOfType("string")).Return(true, nil).Once()
+	repo.On("ResetPassword", stored.ID, mock.AnythingOfType("string")).Return(false, nil).Once()
+
+	body := map[string]string{"token": token, "newPassword": "kopi susu di kantin"}
+	assert.Equal(t, 200, postJSON(app, "/auth/reset-password", body))
+	assert.Equal(t, 400, postJSON(app, "/auth/reset-password", body))
+}
@@ -0,0 +1,106 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"gouas/app/models"
+	"gouas/app/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func defaultRules() []models.PointRule {
+	return []models.PointRule{
+		{Name: "Kompetisi Nasional", CompetitionLevel: "National", Points: 30, IsActive: true},
+		{Name: "Juara 1 Nasional", AchievementType: "competition", CompetitionLevel: "National", Rank: 1, Points: 40, IsActive: true},
+		{Name: "Medali Emas", MedalType: "Gold", Points: 35, IsActive: true},
+		{Name: "Jurnal Internasional", AchievementType: "publication", PublicationType: "journal", Points: 45, IsActive: true},
+		{Name: "Poin Dasar", Points: 5, IsActive: true},
+	}
+}
+
+func TestResolvePoints_MostSpecificRuleWins(t *testing.T) {
+	ach := models.Achievement{
+		AchievementType: "competition",
+		Details:         models.AchievementDetails{CompetitionLevel: "National", Rank: 1},
+	}
+
+	points, rule := service.ResolvePoints(defaultRules(), ach, time.Now())
+
+	assert.Equal(t, 40, points)
+	assert.Equal(t, "Juara 1 Nasional", rule.Name)
+}
+
+func TestResolvePoints_MatchIsCaseInsensitive(t *testing.T) {
+	ach := models.Achievement{
+		AchievementType: "Publication",
+		Details:         models.AchievementDetails{PublicationType: "Journal"},
+	}
+
+	points, _ := service.ResolvePoints(defaultRules(), ach, time.Now())
+
+	assert.Equal(t, 45, points)
+}
+
+func TestResolvePoints_CatchAllRule(t *testing.T) {
+	ach := models.Achievement{AchievementType: "organization"}
+
+	points, rule := service.ResolvePoints(defaultRules(), ach, time.Now())
+
+	assert.Equal(t, 5, points)
+	assert.Equal(t, "Poin Dasar", rule.Name)
+}
+
+func TestResolvePoints_FallsBackToClaimedPoints(t *testing.T) {
+	ach := models.Achievement{AchievementType: "organization", Points: 12}
+	rules := []models.PointRule{
+		{Name: "Kompetisi Nasional", CompetitionLevel: "National", Points: 30, IsActive: true},
+	}
+
+	points, rule := service.ResolvePoints(rules, ach, time.Now())
+
+	assert.Equal(t, 12, points)
+	assert.Nil(t, rule)
+}
+
+func TestResolvePoints_SkipsInactiveAndExpiredRules(t *testing.T) {
+	lastYear := time.Now().AddDate(-1, 0, 0)
+	ach := models.Achievement{Details: models.AchievementDetails{CompetitionLevel: "National"}, Points: 7}
+	rules := []models.PointRule{
+		{Name: "Nonaktif", CompetitionLevel: "National", Points: 30, IsActive: false},
+		{Name: "Pedoman Lama", CompetitionLevel: "National", Points: 25, IsActive: true, ValidUntil: &lastYear},
+	}
+
+	points, rule := service.ResolvePoints(rules, ach, time.Now())
+
+	assert.Equal(t, 7, points)
+	assert.Nil(t, rule)
+}
+
+func TestResolvePoints_PriorityBreaksTies(t *testing.T) {
+	ach := models.Achievement{Details: models.AchievementDetails{CompetitionLevel: "National"}}
+	rules := []models.PointRule{
+		{Name: "Pedoman Fakultas", CompetitionLevel: "National", Points: 30, IsActive: true},
+		{Name: "Pedoman Universitas", CompetitionLevel: "National", Points: 35, Priority: 10, IsActive: true},
+	}
+
+	points, _ := service.ResolvePoints(rules, ach, time.Now())
+
+	assert.Equal(t, 35, points)
+}
+
+func TestResolvePoints_PriorityBeatsSpecificity(t *testing.T) {
+	ach := models.Achievement{
+		AchievementType: "competition",
+		Details:         models.AchievementDetails{CompetitionLevel: "National", Rank: 1},
+	}
+	rules := append(defaultRules(), models.PointRule{
+		Name: "Pedoman Fakultas 2025", CompetitionLevel: "National", Points: 50, Priority: 1, IsActive: true,
+	})
+
+	points, rule := service.ResolvePoints(rules, ach, time.Now())
+
+	assert.Equal(t, 50, points)
+	assert.Equal(t, "Pedoman Fakultas 2025", rule.Name)
+}
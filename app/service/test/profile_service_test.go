@@ -0,0 +1,162 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gouas/app/models"
+	"gouas/app/repository"
+	"gouas/app/service"
+	"gouas/helper"
+	"gouas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+func newProfileApp(authData *middleware.AuthResult) (*fiber.App, repoMocks) {
+	m := newRepoMocks()
+	svc := service.NewProfileService(m.profile, m.student, m.lecturer, m.admin, m.org, cleanAttemptRepo(), service.DefaultLoginPolicy())
+
+	app := fiber.New()
+	app.Post("/students", svc.CreateStudent)
+	app.Delete("/students/:id", svc.DeleteStudent)
+	app.Put("/lecturers/:id", svc.UpdateLecturer)
+	app.Put("/auth/profile", withAuth(authData), svc.UpdateContact)
+	return app, m
+}
+
+func TestValidateNIM(t *testing.T) {
+	assert.NoError(t, service.ValidateNIM("2141720001"))
+	assert.Error(t, service.ValidateNIM("NIM-budi-12345"))
+	assert.Error(t, service.ValidateNIM("1234567"))
+}
+
+func TestValidateNIP(t *testing.T) {
+	assert.NoError(t, service.ValidateNIP("198503152010121001"))
+	assert.Error(t, service.ValidateNIP("19850315201012100"), "17 digit")
+	assert.Error(t, service.ValidateNIP("198513152010121001"), "bulan lahir 13")
+	assert.Error(t, service.ValidateNIP("198503151980121001"), "diangkat sebelum lahir")
+	assert.Error(t, service.ValidateNIP("198503152010123001"), "kode jenis kelamin 3")
+	assert.Error(t, service.ValidateNIP("198503152010121000"), "nomor urut 000")
+}
+
+func TestNormalizePhone(t *testing.T) {
+	for _, in := range []string{"0812-3456-7890", "62 812 3456 7890", "+6281234567890"} {
+		phone, err := service.NormalizePhone(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, "+6281234567890", phone)
+	}
+	_, err := service.NormalizePhone("021-5551234")
+	assert.Error(t, err)
+}
+
+func TestCreateStudentProfile_DuplicateNIM(t *testing.T) {
+	app, m := newProfileApp(nil)
+	userID := uuid.New()
+	m.admin.On("FindUserByID", userID).Return(&models.User{ID: userID, Role: models.Role{Name: "Mahasiswa"}}, nil)
+	m.student.On("FindByUserID", userID).Return(nil, gorm.ErrRecordNotFound)
+	m.profile.On("NIMTaken", "2141720001", uuid.Nil).Return(true, nil)
+
+	status := postJSON(app, "/students", map[string]string{"userId": userID.String(), "nim": " 2141720001 "})
+
+	assert.Equal(t, 409, status)
+	m.profile.AssertNotCalled(t, "CreateStudent", mock.Anything)
+}
+
+func TestCreateStudentProfile_RequiresStudentRole(t *testing.T) {
+	app, m := newProfileApp(nil)
+	userID := uuid.New()
+	m.admin.On("FindUserByID", userID).Return(&models.User{ID: userID, Role: models.Role{Name: "Dosen Wali"}}, nil)
+
+	status := postJSON(app, "/students", map[string]string{"userId": userID.String(), "nim": "2141720001"})
+
+	assert.Equal(t, 400, status)
+	m.profile.AssertNotCalled(t, "CreateStudent", mock.Anything)
+}
+
+func TestUpdateLecturerProfile_InvalidNIP(t *testing.T) {
+	app, m := newProfileApp(nil)
+	lecturer := &models.Lecturer{ID: uuid.New(), NIP: "NIP-dosen-12345"}
+	m.lecturer.On("FindByID", lecturer.ID).Return(lecturer, nil)
+
+	status := putJSON(app, "/lecturers/"+lecturer.ID.String(), map[string]string{"nip": "19850315"})
+
+	assert.Equal(t, 400, status)
+	m.profile.AssertNotCalled(t, "UpdateLecturer", mock.Anything)
+}
+
+func TestDeleteStudentProfile_WithRecordsRejected(t *testing.T) {
+	app, m := newProfileApp(nil)
+	student := &models.Student{ID: uuid.New()}
+	m.student.On("FindByID", student.ID).Return(student, nil)
+	m.profile.On("CountStudentRecords", student.ID).Return(int64(2), nil)
+
+	resp, _ := app.Test(httptest.NewRequest("DELETE", "/students/"+student.ID.String(), nil))
+
+	assert.Equal(t, 409, resp.StatusCode)
+	m.profile.AssertNotCalled(t, "DeleteStudent", mock.Anything)
+}
+
+func TestUpdateContact_NormalizesPhoneKeepsEmail(t *testing.T) {
+	user := models.User{ID: uuid.New(), Email: "budi@kampus.ac.id"}
+	app, m := newProfileApp(&middleware.AuthResult{UserID: user.ID.String()})
+	m.admin.On("FindUserByID", user.ID).Return(&user, nil)
+	m.profile.On("UpdateContact", user.ID, "budi@kampus.ac.id", "+6281234567890").Return(nil)
+
+	status := putJSON(app, "/auth/profile", map[string]string{"phone": "081234567890"})
+
+	assert.Equal(t, 200, status)
+	m.profile.AssertExpectations(t)
+}
+
+func TestUpdateContact_EmailChangeRequiresCurrentPassword(t *testing.T) {
+	hashed, _ := helper.HashPassword("kopi susu di kantin")
+	user := models.User{ID: uuid.New(), Email: "budi@kampus.ac.id", PasswordHash: hashed}
+	app, m := newProfileApp(&middleware.AuthResult{UserID: user.ID.String()})
+	m.admin.On("FindUserByID", user.ID).Return(&user, nil)
+
+	missing := putJSON(app, "/auth/profile", map[string]string{"email": "budi.baru@kampus.ac.id"})
+	wrong := putJSON(app, "/auth/profile", map[string]string{"email": "budi.baru@kampus.ac.id", "currentPassword": "tebakan"})
+
+	assert.Equal(t, 400, missing)
+	assert.Equal(t, 400, wrong)
+	m.profile.AssertNotCalled(t, "EmailTaken", mock.Anything, mock.Anything)
+	m.profile.AssertNotCalled(t, "UpdateContact", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateContact_EmailChangeThrottledAfterFailedChecks(t *testing.T) {
+	user := models.User{ID: uuid.New(), Username: "Budi", Email: "budi@kampus.ac.id", PasswordHash: "tidak-boleh-dicek"}
+	m := newRepoMocks()
+	m.admin.On("FindUserByID", user.ID).Return(&user, nil)
+	// Counter yang sama dengan login: 9 kali gagal barusan -> backoff
+	lastFail := time.Now()
+	attempts := new(MockLoginAttemptRepo)
+	attempts.On("Reserve", "budi").Return(repository.FailureStats{Count: 9, LastFail: &lastFail}, repository.FailureStats{}, nil)
+	svc := service.NewProfileService(m.profile, m.student, m.lecturer, m.admin, m.org, attempts, service.DefaultLoginPolicy())
+	app := fiber.New()
+	app.Put("/auth/profile", withAuth(&middleware.AuthResult{UserID: user.ID.String()}), svc.UpdateContact)
+
+	status := putJSON(app, "/auth/profile", map[string]string{"email": "budi.baru@kampus.ac.id", "currentPassword": "tebakan"})
+
+	assert.Equal(t, 429, status)
+	attempts.AssertNotCalled(t, "Complete", mock.Anything)
+	m.profile.AssertNotCalled(t, "UpdateContact", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUpdateContact_DuplicateEmail(t *testing.T) {
+	hashed, _ := helper.HashPassword("kopi susu di kantin")
+	user := models.User{ID: uuid.New(), Email: "budi@kampus.ac.id", PasswordHash: hashed}
+	app, m := newProfileApp(&middleware.AuthResult{UserID: user.ID.String()})
+	m.admin.On("FindUserByID", user.ID).Return(&user, nil)
+	m.profile.On("EmailTaken", "ani@kampus.ac.id", user.ID).Return(true, nil)
+
+	status := putJSON(app, "/auth/profile", map[string]string{"email": "ani@kampus.ac.id", "currentPassword": "kopi susu di kantin"})
+
+	assert.Equal(t, 409, status)
+	m.profile.AssertNotCalled(t, "UpdateContact", mock.Anything, mock.Anything, mock.Anything)
+}
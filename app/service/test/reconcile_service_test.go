@@ -0,0 +1,105 @@
+package test
+
+import (
+	"context"
+	"gouas/app/models"
+	"gouas/app/repository"
+	"gouas/app/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// --- MOCK RECONCILE REPOSITORY ---
+type MockReconcileRepo struct{ mock.Mock }
+
+func (m *MockReconcileRepo) ListMongoAchievements(ctx context.Context) ([]repository.MongoAchievementStub, error) {
+	args := m.Called()
+	return args.Get(0).([]repository.MongoAchievementStub), args.Error(1)
+}
+func (m *MockReconcileRepo) ListReferences(ctx context.Context) ([]models.AchievementReference, error) {
+	args := m.Called()
+	return args.Get(0).([]models.AchievementReference), args.Error(1)
+}
+func (m *MockReconcileRepo) DeleteMongoAchievement(ctx context.Context, mongoID string) error {
+	return m.Called(mongoID).Error(0)
+}
+func (m *MockReconcileRepo) SetMongoStudentID(ctx context.Context, mongoID string, studentID uuid.UUID) error {
+	return m.Called(mongoID, studentID).Error(0)
+}
+func (m *MockReconcileRepo) MarkReferenceDeleted(ctx context.Context, id uuid.UUID, note string) error {
+	return m.Called(id, note).Error(0)
+}
+
+func reconcileFixture() (*MockReconcileRepo, map[string]uuid.UUID) {
+	studentA, studentB := uuid.New(), uuid.New()
+	old := time.Now().Add(-time.Hour)
+
+	okDoc := primitive.NewObjectID()
+	mismatchDoc := primitive.NewObjectID()
+	orphanDoc := primitive.NewObjectID()
+	freshOrphan := primitive.NewObjectID()
+
+	ids := map[string]uuid.UUID{
+		"ok": uuid.New(), "mismatch": uuid.New(), "dangling": uuid.New(), "danglingVerified": uuid.New(), "deleted": uuid.New(),
+		"studentA": studentA,
+	}
+
+	repo := new(MockReconcileRepo)
+	repo.On("ListReferences").Return([]models.AchievementReference{
+		{ID: ids["ok"], StudentID: studentA, MongoAchievementID: okDoc.Hex(), Status: models.StatusDraft},
+		{ID: ids["mismatch"], StudentID: studentA, MongoAchievementID: mismatchDoc.Hex(), Status: models.StatusSubmitted},
+		{ID: ids["dangling"], StudentID: studentA, MongoAchievementID: primitive.NewObjectID().Hex(), Status: models.StatusDraft},
+		{ID: ids["danglingVerified"], StudentID: studentA, MongoAchievementID: primitive.NewObjectID().Hex(), Status: models.StatusVerified},
+		{ID: ids["deleted"], StudentID: studentA, MongoAchievementID: primitive.NewObjectID().Hex(), Status: models.StatusDeleted},
+	}, nil)
+	repo.On("ListMongoAchievements").Return([]repository.MongoAchievementStub{
+		{ID: okDoc, StudentID: studentA.String(), CreatedAt: old},
+		{ID: mismatchDoc, StudentID: studentB.String(), CreatedAt: old},
+		{ID: orphanDoc, StudentID: studentB.String(), CreatedAt: old},
+		{ID: freshOrphan, StudentID: studentB.String(), CreatedAt: time.Now()},
+	}, nil)
+	return repo, ids
+}
+
+func TestReconcile_ReportOnly(t *testing.T) {
+	repo, _ := reconcileFixture()
+	svc := service.NewReconcileService(repo, 15*time.Minute)
+
+	report, err := svc.Run(context.Background(), false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{
+		service.IssueDanglingReference: 2,
+		service.IssueStudentMismatch:   1,
+		service.IssueOrphanDocument:    1, // dokumen baru masih dalam grace period
+	}, report.Summary())
+	repo.AssertNotCalled(t, "DeleteMongoAchievement", mock.Anything)
+	repo.AssertNotCalled(t, "MarkReferenceDeleted", mock.Anything, mock.Anything)
+}
+
+func TestReconcile_Repair(t *testing.T) {
+	repo, ids := reconcileFixture()
+	repo.On("MarkReferenceDeleted", ids["dangling"], mock.Anything).Return(nil)
+	repo.On("SetMongoStudentID", mock.Anything, ids["studentA"]).Return(nil)
+	repo.On("DeleteMongoAchievement", mock.Anything).Return(nil)
+	svc := service.NewReconcileService(repo, 15*time.Minute)
+
+	report, err := svc.Run(context.Background(), true)
+
+	assert.NoError(t, err)
+	repaired := 0
+	for _, issue := range report.Issues {
+		if issue.Repaired {
+			repaired++
+		}
+	}
+	// Reference verified yang menggantung hanya dilaporkan
+	assert.Equal(t, 3, repaired)
+	repo.AssertNotCalled(t, "MarkReferenceDeleted", ids["danglingVerified"], mock.Anything)
+	repo.AssertNumberOfCalls(t, "DeleteMongoAchievement", 1)
+}
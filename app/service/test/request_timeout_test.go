@@ -0,0 +1,33 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gouas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestTimeout_CancelsHandlerContextAtDeadline(t *testing.T) {
+	app := fiber.New()
+	app.Use(middleware.RequestTimeout(50 * time.Millisecond))
+	cancelled := make(chan error, 1)
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		ctx := c.UserContext()
+		select {
+		case <-ctx.Done():
+			cancelled <- ctx.Err()
+		case <-time.After(5 * time.Second):
+			cancelled <- nil
+		}
+		return c.SendStatus(200)
+	})
+
+	resp, _ := app.Test(httptest.NewRequest("GET", "/slow", nil), -1)
+
+	assert.Equal(t, 504, resp.StatusCode)
+	assert.Error(t, <-cancelled)
+}
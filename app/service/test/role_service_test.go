@@ -0,0 +1,219 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"gouas/app/models"
+	"gouas/app/repository"
+	"gouas/app/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockRoleRepo struct {
+	mock.Mock
+}
+
+func (m *MockRoleRepo) FindAllRoles(ctx context.Context) ([]models.Role, error) { return nil, nil }
+func (m *MockRoleRepo) FindRoleByID(ctx context.Context, id uuid.UUID) (*models.Role, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Role), args.Error(1)
+}
+func (m *MockRoleRepo) CreateRole(ctx context.Context, role models.Role) (models.Role, error) {
+	return role, nil
+}
+func (m *MockRoleRepo) UpdateRole(ctx context.Context, role models.Role) error { return nil }
+func (m *MockRoleRepo) DeleteRole(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+func (m *MockRoleRepo) CountUsersByRole(ctx context.Context, roleID uuid.UUID) (int64, error) {
+	args := m.Called(roleID)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *MockRoleRepo) FindAllPermissions(ctx context.Context) ([]models.Permission, error) {
+	return nil, nil
+}
+func (m *MockRoleRepo) FindPermissionByID(ctx context.Context, id uuid.UUID) (*models.Permission, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Permission), args.Error(1)
+}
+func (m *MockRoleRepo) CreatePermission(ctx context.Context, perm models.Permission) (models.Permission, error) {
+	return perm, nil
+}
+func (m *MockRoleRepo) UpdatePermission(ctx context.Context, perm models.Permission) error {
+	return nil
+}
+func (m *MockRoleRepo) DeletePermission(ctx context.Context, id uuid.UUID) error { return nil }
+func (m *MockRoleRepo) AttachPermission(ctx context.Context, roleID uuid.UUID, perm models.Permission) error {
+	return nil
+}
+func (m *MockRoleRepo) DetachPermission(ctx context.Context, roleID uuid.UUID, perm models.Permission) error {
+	args := m.Called(roleID, perm.Name)
+	return args.Error(0)
+}
+func (m *MockRoleRepo) CountUsersWithPermission(ctx context.Context, perm string, excludeRoleID, excludeUserID uuid.UUID) (int64, error) {
+	args := m.Called(perm, excludeRoleID, excludeUserID)
+	return args.Get(0).(int64), args.Error(1)
+}
+func (m *MockRoleRepo) LockPermissionHolders(ctx context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func newRoleApp(repo *MockRoleRepo) *fiber.App {
+	svc := service.NewRoleService(repo, &MockUnitOfWork{Repos: repository.TxRepositories{Role: repo}})
+	app := fiber.New()
+	app.Delete("/roles/:id", svc.DeleteRole)
+	app.Delete("/roles/:id/permissions/:permissionId", svc.DetachPermission)
+	app.Delete("/permissions/:id", svc.DeletePermission)
+	return app
+}
+
+func TestDeleteRole_InUseRejected(t *testing.T) {
+	repo := new(MockRoleRepo)
+	role := &models.Role{ID: uuid.New(), Name: "Kaprodi"}
+	repo.On("FindRoleByID", role.ID).Return(role, nil)
+	repo.On("CountUsersByRole", role.ID).Return(int64(2), nil)
+
+	req := httptest.NewRequest("DELETE", "/roles/"+role.ID.String(), nil)
+	resp, _ := newRoleApp(repo).Test(req)
+
+	assert.Equal(t, 409, resp.StatusCode)
+	repo.AssertNotCalled(t, "DeleteRole", mock.Anything)
+}
+
+func TestDeleteRole_SystemRoleRejected(t *testing.T) {
+	repo := new(MockRoleRepo)
+	role := &models.Role{ID: uuid.New(), Name: "Mahasiswa"}
+	repo.On("FindRoleByID", role.ID).Return(role, nil)
+
+	req := httptest.NewRequest("DELETE", "/roles/"+role.ID.String(), nil)
+	resp, _ := newRoleApp(repo).Test(req)
+
+	assert.Equal(t, 400, resp.StatusCode)
+	repo.AssertNotCalled(t, "DeleteRole", mock.Anything)
+}
+
+func TestDeleteRole_UnusedCustomRole(t *testing.T) {
+	repo := new(MockRoleRepo)
+	role := &models.Role{ID: uuid.New(), Name: "Wakil Dekan"}
+	repo.On("FindRoleByID", role.ID).Return(role, nil)
+	repo.On("CountUsersByRole", role.ID).Return(int64(0), nil)
+	repo.On("DeleteRole", role.ID).Return(nil)
+
+	req := httptest.NewRequest("DELETE", "/roles/"+role.ID.String(), nil)
+	resp, _ := newRoleApp(repo).Test(req)
+
+	assert.Equal(t, 200, resp.StatusCode)
+	repo.AssertExpectations(t)
+}
+
+func TestDetachPermission_LastUserManagerRejected(t *testing.T) {
+	repo := new(MockRoleRepo)
+	perm := &models.Permission{ID: uuid.New(), Name: models.PermUserManage}
+	role := &models.Role{ID: uuid.New(), Name: "Admin", Permissions: []models.Permission{*perm}}
+	repo.On("FindRoleByID", role.ID).Return(role, nil)
+	repo.On("FindPermissionByID", perm.ID).Return(perm, nil)
+	// Tidak ada user aktif lain (di luar role ini) yang memegang user:manage
+	repo.On("LockPermissionHolders").Return(nil)
+	repo.On("CountUsersWithPermission", models.PermUserManage, role.ID, uuid.Nil).Return(int64(0), nil)
+
+	req := httptest.NewRequest("DELETE", "/roles/"+role.ID.String()+"/permissions/"+perm.ID.String(), nil)
+	resp, _ := newRoleApp(repo).Test(req)
+
+	assert.Equal(t, 409, resp.StatusCode)
+	repo.AssertNotCalled(t, "DetachPermission", mock.Anything, mock.Anything)
+}
+
+func TestDetachPermission_OtherHolderRemains(t *testing.T) {
+	repo := new(MockRoleRepo)
+	perm := &models.Permission{ID: uuid.New(), Name: models.PermUserManage}
+	role := &models.Role{ID: uuid.New(), Name: "Admin", Permissions: []models.Permission{*perm}}
+	repo.On("FindRoleByID", role.ID).Return(role, nil)
+	repo.On("FindPermissionByID", perm.ID).Return(perm, nil)
+	repo.On("LockPermissionHolders").Return(nil)
+	repo.On("CountUsersWithPermission", models.PermUserManage, role.ID, uuid.Nil).Return(int64(1), nil)
+	repo.On("DetachPermission", role.ID, models.PermUserManage).Return(nil)
+
+	req := httptest.NewRequest("DELETE", "/roles/"+role.ID.String()+"/permissions/"+perm.ID.String(), nil)
+	resp, _ := newRoleApp(repo).Test(req)
+
+	assert.Equal(t, 200, resp.StatusCode)
+	repo.AssertExpectations(t)
+}
+
+func TestDetachPermission_ChecksHoldersInsideLockedTransaction(t *testing.T) {
+	repo, txRepo := new(MockRoleRepo), new(MockRoleRepo)
+	perm := &models.Permission{ID: uuid.New(), Name: models.PermRoleManage}
+	role := &models.Role{ID: uuid.New(), Name: "Admin", Permissions: []models.Permission{*perm}}
+	repo.On("FindRoleByID", role.ID).Return(role, nil)
+	repo.On("FindPermissionByID", perm.ID).Return(perm, nil)
+	// Lock, cek pemegang & detach harus memakai repository transaksi yang sama
+	txRepo.On("LockPermissionHolders").Return(nil).Once()
+	txRepo.On("CountUsersWithPermission", models.PermRoleManage, role.ID, uuid.Nil).Return(int64(1), nil)
+	txRepo.On("DetachPermission", role.ID, models.PermRoleManage).Return(nil)
+
+	svc := service.NewRoleService(repo, &MockUnitOfWork{Repos: repository.TxRepositories{Role: txRepo}})
+	app := fiber.New()
+	app.Delete("/roles/:id/permissions/:permissionId", svc.DetachPermission)
+	req := httptest.NewRequest("DELETE", "/roles/"+role.ID.String()+"/permissions/"+perm.ID.String(), nil)
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, 200, resp.StatusCode)
+	txRepo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "CountUsersWithPermission", mock.Anything, mock.Anything, mock.Anything)
+	repo.AssertNotCalled(t, "DetachPermission", mock.Anything, mock.Anything)
+}
+
+func TestDeletePermission_BuiltinRejected(t *testing.T) {
+	repo := new(MockRoleRepo)
+	perm := &models.Permission{ID: uuid.New(), Name: models.PermAchievementVerify}
+	repo.On("FindPermissionByID", perm.ID).Return(perm, nil)
+
+	req := httptest.NewRequest("DELETE", "/permissions/"+perm.ID.String(), nil)
+	resp, _ := newRoleApp(repo).Test(req)
+
+	assert.Equal(t, 400, resp.StatusCode)
+}
+
+func TestAssignRole_LastUserManagerRejected(t *testing.T) {
+	adminRepo := new(MockAdminRepo)
+	roleRepo := new(MockRoleRepo)
+	adminSvc := service.NewAdminService(adminRepo, new(MockOrganizationRepo), &MockUnitOfWork{Repos: repository.TxRepositories{Admin: adminRepo, Role: roleRepo}})
+	app := fiber.New()
+	app.Put("/users/:id/role", adminSvc.AssignRole)
+
+	user := &models.User{
+		ID:       uuid.New(),
+		IsActive: true,
+		Role: models.Role{Name: "Admin", Permissions: []models.Permission{
+			{Name: models.PermUserManage}, {Name: models.PermReportRead},
+		}},
+	}
+	kaprodi := models.Role{ID: uuid.New(), Name: "Kaprodi", Permissions: []models.Permission{{Name: models.PermReportRead}}}
+	adminRepo.On("FindRoleByName", "Kaprodi").Return(kaprodi, nil)
+	adminRepo.On("FindUserByID", user.ID).Return(user, nil)
+	roleRepo.On("LockPermissionHolders").Return(nil)
+	roleRepo.On("CountUsersWithPermission", models.PermUserManage, uuid.Nil, user.ID).Return(int64(0), nil)
+
+	body, _ := json.Marshal(map[string]string{"roleName": "Kaprodi"})
+	req := httptest.NewRequest("PUT", "/users/"+user.ID.String()+"/role", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, _ := app.Test(req)
+
+	assert.Equal(t, 409, resp.StatusCode)
+}
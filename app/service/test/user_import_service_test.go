@@ -0,0 +1,226 @@
+package test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gouas/app/models"
+	"gouas/app/service"
+	"gouas/helper"
+	"gouas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockImportJobRepo struct {
+	mock.Mock
+	finished chan models.ImportJob
+}
+
+func (m *MockImportJobRepo) Create(ctx context.Context, job models.ImportJob) (models.ImportJob, error) {
+	args := m.Called(job)
+	job.ID = uuid.New()
+	return job, args.Error(0)
+}
+func (m *MockImportJobRepo) Save(ctx context.Context, job models.ImportJob) error {
+	if job.Status == models.ImportCompleted || job.Status == models.ImportFailed {
+		m.finished <- job
+	}
+	return nil
+}
+func (m *MockImportJobRepo) FindByID(ctx context.Context, id uuid.UUID) (*models.ImportJob, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+func (m *MockImportJobRepo) FailInterrupted(ctx context.Context) (int64, error) { return 0, nil }
+
+func newImportApp() (*fiber.App, repoMocks) {
+	m := newRepoMocks()
+	svc := service.NewUserImportService(m.admin, m.profile, m.lecturer, m.org, m.jobs, m.uow)
+
+	app := fiber.New()
+	admin := &middleware.AuthResult{UserID: uuid.New().String(), Permissions: []string{models.PermUserManage}}
+	app.Post("/users/import", withAuth(admin), svc.Import)
+	return app, m
+}
+
+func uploadFile(app *fiber.App, path, filename string, content []byte) *http.Response {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, _ := w.CreateFormFile("file", filename)
+	part.Write(content)
+	w.Close()
+
+	req := httptest.NewRequest("POST", path, &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	resp, _ := app.Test(req, -1)
+	return resp
+}
+
+// CSV dengan pemisah ';' (export Excel locale Indonesia)
+const intakeCSV = "Username;Email;Full Name;Role;NIM;Advisor NIP\n" +
+	"mhs1;mhs1@kampus.ac.id;Mahasiswa Satu;Mahasiswa;2141720001;\n" +
+	"mhs2;mhs2@kampus.ac.id;Mahasiswa Dua;Mahasiswa;2141720001;198503152010121001\n"
+
+func expectIntakeLookups(m repoMocks) {
+	m.lecturer.On("FindAll").Return([]models.Lecturer{}, nil)
+	m.admin.On("FindRoleByName", "Mahasiswa").Return(models.Role{ID: uuid.New(), Name: "Mahasiswa"}, nil)
+	m.profile.On("TakenValues", mock.Anything, mock.Anything).Return(map[string]bool{}, nil)
+}
+
+func TestImportUsers_DryRunReportsRowErrors(t *testing.T) {
+	app, m := newImportApp()
+	expectIntakeLookups(m)
+
+	resp := uploadFile(app, "/users/import?dryRun=true", "intake.csv", []byte(intakeCSV))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var report struct {
+		TotalRows int                     `json:"totalRows"`
+		ValidRows int                     `json:"validRows"`
+		Errors    []models.ImportRowError `json:"errors"`
+	}
+	decodeData(t, resp, &report)
+	assert.Equal(t, 2, report.TotalRows)
+	assert.Equal(t, 1, report.ValidRows)
+	assert.ElementsMatch(t, []models.ImportRowError{
+		{Row: 3, Field: "nim", Message: "duplicate of row 2"},
+		{Row: 3, Field: "advisor_nip", Message: "lecturer with NIP 198503152010121001 not found"},
+	}, report.Errors)
+	m.jobs.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestImportUsers_ChecksExistingValuesOncePerColumn(t *testing.T) {
+	app, m := newImportApp()
+	m.lecturer.On("FindAll").Return([]models.Lecturer{}, nil)
+	m.admin.On("FindRoleByName", "Mahasiswa").Return(models.Role{ID: uuid.New(), Name: "Mahasiswa"}, nil)
+	m.profile.On("TakenValues", "username", []string{"mhs1", "mhs2"}).Return(map[string]bool{"mhs2": true}, nil).Once()
+	m.profile.On("TakenValues", "email", []string{"mhs1@kampus.ac.id", "MHS2@kampus.ac.id"}).Return(map[string]bool{"mhs2@kampus.ac.id": true}, nil).Once()
+	m.profile.On("TakenValues", "nim", []string{"2141720001", "2141720002"}).Return(map[string]bool{}, nil).Once()
+	m.profile.On("TakenValues", "nip", []string(nil)).Return(map[string]bool{}, nil).Once()
+
+	csv := "username,email,full_name,role,nim\n" +
+		"mhs1,mhs1@kampus.ac.id,Mahasiswa Satu,Mahasiswa,2141720001\n" +
+		"mhs2,MHS2@kampus.ac.id,Mahasiswa Dua,Mahasiswa,2141720002\n"
+	resp := uploadFile(app, "/users/import?dryRun=true", "intake.csv", []byte(csv))
+
+	assert.Equal(t, 200, resp.StatusCode)
+	var report struct {
+		ValidRows int                     `json:"validRows"`
+		Errors    []models.ImportRowError `json:"errors"`
+	}
+	decodeData(t, resp, &report)
+	assert.Equal(t, 1, report.ValidRows)
+	assert.ElementsMatch(t, []models.ImportRowError{
+		{Row: 3, Field: "username", Message: "already exists"},
+		{Row: 3, Field: "email", Message: "already exists"},
+	}, report.Errors)
+	m.profile.AssertExpectations(t)
+	m.profile.AssertNotCalled(t, "UsernameTaken", mock.Anything)
+	m.profile.AssertNotCalled(t, "EmailTaken", mock.Anything, mock.Anything)
+	m.profile.AssertNotCalled(t, "NIMTaken", mock.Anything, mock.Anything)
+}
+
+func TestImportUsers_InvalidRowsRejectWholeFile(t *testing.T) {
+	app, m := newImportApp()
+	expectIntakeLookups(m)
+
+	resp := uploadFile(app, "/users/import", "intake.csv", []byte(intakeCSV))
+
+	assert.Equal(t, 400, resp.StatusCode)
+	m.jobs.AssertNotCalled(t, "Create", mock.Anything)
+	m.admin.AssertNotCalled(t, "CreateUser", mock.Anything)
+}
+
+func TestImportUsers_RunsJobInBackground(t *testing.T) {
+	app, m := newImportApp()
+	expectIntakeLookups(m)
+	userID := uuid.New()
+	m.jobs.On("Create", mock.Anything).Return(nil)
+	m.admin.On("CreateUser", mock.AnythingOfType("models.User")).Return(models.User{ID: userID, Username: "mhs1"}, nil)
+	m.student.On("FindByUserID", userID).Return(nil, gorm.ErrRecordNotFound)
+	m.admin.On("CreateStudentProfile", mock.MatchedBy(func(s models.Student) bool {
+		return s.NIM == "2141720001" && s.UserID == userID
+	})).Return(nil)
+
+	csv := "username,email,full_name,role,nim\nmhs1,mhs1@kampus.ac.id,Mahasiswa Satu,Mahasiswa,2141720001\n"
+	resp := uploadFile(app, "/users/import", "intake.csv", []byte(csv))
+	assert.Equal(t, 202, resp.StatusCode)
+
+	select {
+	case job := <-m.jobs.finished:
+		assert.Equal(t, models.ImportCompleted, job.Status)
+		assert.Equal(t, 1, job.SucceededRows)
+		assert.Equal(t, 0, job.FailedRows)
+	case <-time.After(time.Minute): // bcrypt cost 14 lambat, terutama dengan -race
+		t.Fatal("import job did not finish")
+	}
+	m.admin.AssertExpectations(t)
+}
+
+func TestImportUsers_RecordsAdvisorAssignment(t *testing.T) {
+	app, m := newImportApp()
+	lecturerID, userID := uuid.New(), uuid.New()
+	m.lecturer.On("FindAll").Return([]models.Lecturer{{ID: lecturerID, NIP: "198503152010121001"}}, nil)
+	m.admin.On("FindRoleByName", "Mahasiswa").Return(models.Role{ID: uuid.New(), Name: "Mahasiswa"}, nil)
+	m.profile.On("TakenValues", mock.Anything, mock.Anything).Return(map[string]bool{}, nil)
+	m.jobs.On("Create", mock.Anything).Return(nil)
+	m.admin.On("CreateUser", mock.AnythingOfType("models.User")).Return(models.User{ID: userID, Username: "mhs1"}, nil)
+	m.student.On("FindByUserID", userID).Return(nil, gorm.ErrRecordNotFound)
+	var studentID uuid.UUID
+	m.admin.On("CreateStudentProfile", mock.MatchedBy(func(s models.Student) bool { return s.AdvisorID == nil })).
+		Run(func(args mock.Arguments) { studentID = args.Get(0).(models.Student).ID }).
+		Return(nil)
+	// advisor_id diisi lewat riwayat penugasan di unit of work yang sama
+	m.advisor.On("ApplyAssignments", mock.MatchedBy(func(a []models.AdvisorAssignment) bool {
+		return len(a) == 1 && a[0].StudentID == studentID && a[0].LecturerID == lecturerID &&
+			a[0].PreviousLecturerID == nil && a[0].Reason == models.AssignImport
+	})).Return(nil, nil)
+
+	csv := "username,email,full_name,role,nim,advisor_nip\nmhs1,mhs1@kampus.ac.id,Mahasiswa Satu,Mahasiswa,2141720001,198503152010121001\n"
+	resp := uploadFile(app, "/users/import", "intake.csv", []byte(csv))
+	assert.Equal(t, 202, resp.StatusCode)
+
+	select {
+	case job := <-m.jobs.finished:
+		assert.Equal(t, models.ImportCompleted, job.Status)
+		assert.Equal(t, 1, job.SucceededRows)
+	case <-time.After(time.Minute):
+		t.Fatal("import job did not finish")
+	}
+	m.advisor.AssertExpectations(t)
+}
+
+func TestReadSpreadsheet_XLSX(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Intake" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst><si><t>username</t></si><si><t>nim</t></si><si><r><t>mhs</t></r><r><t>1</t></r></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>` +
+			`<row r="2"><c r="A2" t="s"><v>2</v></c><c r="C2"><v>2141720001</v></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+	for name, content := range files {
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	zw.Close()
+
+	rows, err := helper.ReadSpreadsheet("intake.xlsx", buf.Bytes())
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"username", "", "nim"}, {"mhs1", "", "2141720001"}}, rows)
+}
@@ -0,0 +1,538 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"gouas/app/models"
+	"gouas/app/repository"
+	"gouas/helper"
+	"gouas/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	maxImportRows      = 5000
+	maxImportFileBytes = 10 << 20
+	// progress job disimpan setiap sekian baris agar tidak menulis DB per baris
+	importProgressEvery = 25
+	importRowTimeout    = 30 * time.Second
+)
+
+// UserImportService membuat user + profile secara massal dari file CSV/XLSX
+type UserImportService interface {
+	Import(c *fiber.Ctx) error
+	GetJob(c *fiber.Ctx) error
+}
+
+type userImportService struct {
+	adminRepo    repository.AdminRepository
+	profileRepo  repository.ProfileRepository
+	lecturerRepo repository.LecturerRepository
+	orgRepo      repository.OrganizationRepository
+	jobRepo      repository.ImportJobRepository
+	uow          repository.UnitOfWork
+}
+
+func NewUserImportService(adminRepo repository.AdminRepository, profileRepo repository.ProfileRepository, lecturerRepo repository.LecturerRepository, orgRepo repository.OrganizationRepository, jobRepo repository.ImportJobRepository, uow repository.UnitOfWork) UserImportService {
+	return &userImportService{adminRepo, profileRepo, lecturerRepo, orgRepo, jobRepo, uow}
+}
+
+// importColumns memetakan nama header (sudah dinormalisasi) ke kolom baku
+var importColumns = map[string]string{
+	"username": "username", "email": "email", "password": "password",
+	"full_name": "full_name", "fullname": "full_name", "name": "full_name", "nama": "full_name",
+	"role": "role", "role_name": "role",
+	"nim": "nim", "nip": "nip",
+	"program": "program", "study_program": "program", "program_study": "program", "prodi": "program",
+	"department": "department", "jurusan": "department",
+	"academic_year": "academic_year", "angkatan": "academic_year",
+	"advisor_nip": "advisor_nip", "dosen_wali_nip": "advisor_nip",
+}
+
+var requiredImportColumns = []string{"username", "email", "full_name", "role"}
+
+// importRow adalah satu baris data; Line = nomor baris di file (header = 1)
+type importRow struct {
+	Line   int
+	Values map[string]string
+}
+
+// plannedUser adalah baris yang lolos validasi dan siap dibuat
+type plannedUser struct {
+	Line       int
+	Input      newUserInput
+	AdvisorNIP string
+}
+
+type importReport struct {
+	TotalRows int                     `json:"totalRows"`
+	ValidRows int                     `json:"validRows"`
+	Errors    []models.ImportRowError `json:"errors"`
+}
+
+func normalizeHeader(h string) string {
+	h = strings.ToLower(strings.TrimSpace(h))
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(h)
+}
+
+// parseImportRows membaca header lalu mengubah setiap baris non-kosong menjadi importRow
+func parseImportRows(records [][]string) ([]importRow, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("file is empty")
+	}
+
+	columns := make([]string, len(records[0]))
+	present := map[string]bool{}
+	for i, h := range records[0] {
+		if strings.TrimSpace(h) == "" {
+			continue
+		}
+		col, ok := importColumns[normalizeHeader(h)]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", h)
+		}
+		columns[i] = col
+		present[col] = true
+	}
+	for _, col := range requiredImportColumns {
+		if !present[col] {
+			return nil, fmt.Errorf("missing required column %q", col)
+		}
+	}
+
+	var rows []importRow
+	for i, rec := range records[1:] {
+		values := map[string]string{}
+		empty := true
+		for j, v := range rec {
+			if j >= len(columns) || columns[j] == "" {
+				continue
+			}
+			v = strings.TrimSpace(v)
+			values[columns[j]] = v
+			if v != "" {
+				empty = false
+			}
+		}
+		if !empty {
+			rows = append(rows, importRow{Line: i + 2, Values: values})
+		}
+	}
+	return rows, nil
+}
+
+// importValidator menyimpan cache lookup & nilai unik yang sudah dipakai baris sebelumnya
+type importValidator struct {
+	s            *userImportService
+	roles        map[string]*models.Role
+	programs     map[string]uuid.UUID
+	departments  map[string]uuid.UUID
+	lecturerNIPs map[string]bool
+	seen         map[string]map[string]int
+	// taken berisi nilai unik yang sudah ada di database, diambil sekali per kolom
+	taken map[string]map[string]bool
+}
+
+func (s *userImportService) newValidator(ctx context.Context) (*importValidator, error) {
+	v := &importValidator{
+		s:            s,
+		roles:        map[string]*models.Role{},
+		programs:     map[string]uuid.UUID{},
+		departments:  map[string]uuid.UUID{},
+		lecturerNIPs: map[string]bool{},
+		seen:         map[string]map[string]int{"username": {}, "email": {}, "nim": {}, "nip": {}},
+	}
+
+	programs, err := s.orgRepo.FindAllStudyPrograms(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range programs {
+		v.programs[strings.ToLower(p.Name)] = p.ID
+		if p.Code != "" {
+			v.programs[strings.ToLower(p.Code)] = p.ID
+		}
+	}
+	depts, err := s.orgRepo.FindAllDepartments(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range depts {
+		v.departments[strings.ToLower(d.Name)] = d.ID
+		if d.Code != "" {
+			v.departments[strings.ToLower(d.Code)] = d.ID
+		}
+	}
+	lecturers, err := s.lecturerRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range lecturers {
+		v.lecturerNIPs[l.NIP] = true
+	}
+	return v, nil
+}
+
+func (v *importValidator) role(ctx context.Context, name string) *models.Role {
+	if r, ok := v.roles[name]; ok {
+		return r
+	}
+	var found *models.Role
+	if r, err := v.s.adminRepo.FindRoleByName(ctx, name); err == nil {
+		found = &r
+	}
+	v.roles[name] = found
+	return found
+}
+
+// loadTaken mengambil nilai username/email/nim/nip yang sudah dipakai dengan satu query per kolom,
+// bukan 3-4 query per baris
+func (v *importValidator) loadTaken(ctx context.Context, rows []importRow) error {
+	v.taken = map[string]map[string]bool{}
+	for field := range v.seen {
+		var values []string
+		for _, row := range rows {
+			if val := row.Values[field]; val != "" {
+				values = append(values, val)
+			}
+		}
+		taken, err := v.s.profileRepo.TakenValues(ctx, field, values)
+		if err != nil {
+			return err
+		}
+		v.taken[field] = taken
+	}
+	return nil
+}
+
+// unique mengecek duplikasi di dalam file lalu di database
+func (v *importValidator) unique(field, value string, line int) string {
+	key := strings.ToLower(value)
+	if first, ok := v.seen[field][key]; ok {
+		return fmt.Sprintf("duplicate of row %d", first)
+	}
+	v.seen[field][key] = line
+	if field == "email" {
+		value = key
+	}
+	if v.taken[field][value] {
+		return "already exists"
+	}
+	return ""
+}
+
+// validate memeriksa semua baris; baris valid dikembalikan sebagai plannedUser
+func (v *importValidator) validate(ctx context.Context, rows []importRow) ([]plannedUser, []models.ImportRowError, error) {
+	var planned []plannedUser
+	var errs []models.ImportRowError
+
+	if err := v.loadTaken(ctx, rows); err != nil {
+		return nil, nil, err
+	}
+
+	// NIP dosen di file ini boleh dipakai sebagai advisor_nip baris mahasiswa
+	fileNIPs := map[string]bool{}
+	for _, row := range rows {
+		if row.Values["role"] == "Dosen Wali" && row.Values["nip"] != "" {
+			fileNIPs[row.Values["nip"]] = true
+		}
+	}
+
+	for _, row := range rows {
+		val := row.Values
+		var rowErrs []models.ImportRowError
+		fail := func(field, msg string) {
+			rowErrs = append(rowErrs, models.ImportRowError{Row: row.Line, Field: field, Message: msg})
+		}
+
+		in := newUserInput{
+			Username: val["username"],
+			Email:    val["email"],
+			Password: val["password"],
+			FullName: val["full_name"],
+			RoleName: val["role"],
+			profileInput: profileInput{
+				NIM:          val["nim"],
+				NIP:          val["nip"],
+				AcademicYear: val["academic_year"],
+			},
+		}
+
+		switch {
+		case in.Username == "":
+			fail("username", "is required")
+		case len(in.Username) > 50:
+			fail("username", "must be at most 50 characters")
+		default:
+			if msg := v.unique("username", in.Username, row.Line); msg != "" {
+				fail("username", msg)
+			}
+		}
+
+		if err := ValidateEmail(in.Email); err != nil {
+			fail("email", err.Error())
+		} else if msg := v.unique("email", in.Email, row.Line); msg != "" {
+			fail("email", msg)
+		}
+
+		if in.FullName == "" {
+			fail("full_name", "is required")
+		}
+
+		if in.Password != "" {
+			if err := helper.ValidatePassword(in.Password, in.Username); err != nil {
+				fail("password", err.Error())
+			}
+		}
+
+		if v.role(ctx, in.RoleName) == nil {
+			fail("role", fmt.Sprintf("role %q not found", in.RoleName))
+		}
+
+		// ID unit diambil dari cache dan baru dipasang setelah validate agar tidak ada query UnitExists per baris
+		var programID, departmentID *uuid.UUID
+		isStudent, isLecturer := in.RoleName == "Mahasiswa", in.RoleName == "Dosen Wali"
+		for _, col := range []string{"nim", "program", "academic_year", "advisor_nip", "nip", "department"} {
+			allowed := isLecturer
+			if col != "nip" && col != "department" {
+				allowed = isStudent
+			}
+			if val[col] != "" && !allowed {
+				fail(col, "is not allowed for role "+in.RoleName)
+			}
+		}
+
+		if isStudent {
+			if in.NIM == "" {
+				fail("nim", "is required")
+			} else if msg := v.unique("nim", in.NIM, row.Line); msg != "" {
+				fail("nim", msg)
+			}
+			if p := val["program"]; p != "" {
+				if id, ok := v.programs[strings.ToLower(p)]; ok {
+					programID = &id
+				} else {
+					fail("program", fmt.Sprintf("study program %q not found", p))
+				}
+			}
+			if nip := val["advisor_nip"]; nip != "" && !v.lecturerNIPs[nip] && !fileNIPs[nip] {
+				fail("advisor_nip", fmt.Sprintf("lecturer with NIP %s not found", nip))
+			}
+		}
+
+		if isLecturer {
+			if in.NIP == "" {
+				fail("nip", "is required")
+			} else if msg := v.unique("nip", in.NIP, row.Line); msg != "" {
+				fail("nip", msg)
+			}
+			if d := val["department"]; d != "" {
+				if id, ok := v.departments[strings.ToLower(d)]; ok {
+					departmentID = &id
+				} else {
+					fail("department", fmt.Sprintf("department %q not found", d))
+				}
+			}
+		}
+
+		// Format NIM/NIP & angkatan memakai validasi yang sama dengan CreateUser
+		if err := in.profileInput.validate(ctx, v.s.orgRepo); err != nil {
+			field := "academic_year"
+			if msg := err.Error(); strings.HasPrefix(msg, "nim ") || strings.HasPrefix(msg, "nip ") {
+				field = msg[:3]
+			}
+			fail(field, err.Error())
+		}
+		in.StudyProgramID, in.DepartmentID = programID, departmentID
+
+		if len(rowErrs) > 0 {
+			errs = append(errs, rowErrs...)
+			continue
+		}
+		planned = append(planned, plannedUser{Line: row.Line, Input: in, AdvisorNIP: val["advisor_nip"]})
+	}
+	return planned, errs, nil
+}
+
+// Import menerima multipart field "file" (.csv/.xlsx).
+// ?dryRun=true hanya memvalidasi; tanpa dryRun file yang valid seluruhnya diproses di background.
+func (s *userImportService) Import(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	authData := middleware.CurrentAuth(c)
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "File is required (multipart field 'file')", nil))
+	}
+	if fh.Size > maxImportFileBytes {
+		return c.Status(400).JSON(helper.APIResponse("error", "File is too large (max 10MB)", nil))
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Cannot read file", nil))
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Cannot read file", nil))
+	}
+
+	records, err := helper.ReadSpreadsheet(fh.Filename, data)
+	if err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	rows, err := parseImportRows(records)
+	if err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	if len(rows) == 0 {
+		return c.Status(400).JSON(helper.APIResponse("error", "File has no data rows", nil))
+	}
+	if len(rows) > maxImportRows {
+		return c.Status(400).JSON(helper.APIResponse("error", fmt.Sprintf("Too many rows (max %d)", maxImportRows), nil))
+	}
+
+	validator, err := s.newValidator(ctx)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	planned, rowErrs, err := validator.validate(ctx, rows)
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+	report := importReport{TotalRows: len(rows), ValidRows: len(planned), Errors: rowErrs}
+	if report.Errors == nil {
+		report.Errors = []models.ImportRowError{}
+	}
+
+	if c.QueryBool("dryRun") {
+		return c.Status(200).JSON(helper.APIResponse("success", "Dry run finished", report))
+	}
+	// Semua baris harus valid agar angkatan tidak ter-import setengah
+	if len(rowErrs) > 0 {
+		return c.Status(400).JSON(helper.APIResponse("error", "Validation Failed", report))
+	}
+
+	job, err := s.jobRepo.Create(ctx, models.ImportJob{
+		CreatedBy: uuid.MustParse(authData.UserID),
+		FileName:  fh.Filename,
+		Status:    models.ImportPending,
+		TotalRows: len(planned),
+		Errors:    []models.ImportRowError{},
+	})
+	if err != nil {
+		return c.Status(500).JSON(helper.APIResponse("error", err.Error(), nil))
+	}
+
+	go s.run(job, planned)
+	return c.Status(202).JSON(helper.APIResponse("success", "Import started", job))
+}
+
+// run membuat user satu per satu (tiap baris transaksi sendiri) dan mencatat progress ke job.
+// Password kosong diganti password acak; user memakai reset password untuk login pertama.
+func (s *userImportService) run(job models.ImportJob, planned []plannedUser) {
+	ctx := context.Background()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[IMPORT] job %s crashed: %v", job.ID, r)
+			now := time.Now()
+			job.Status, job.LastError, job.FinishedAt = models.ImportFailed, fmt.Sprint(r), &now
+			s.saveJob(ctx, job)
+		}
+	}()
+
+	job.Status = models.ImportRunning
+	s.saveJob(ctx, job)
+
+	// Dosen dibuat lebih dulu agar advisor_nip mahasiswa di file yang sama bisa di-resolve
+	sort.SliceStable(planned, func(i, j int) bool {
+		return planned[i].Input.RoleName == "Dosen Wali" && planned[j].Input.RoleName != "Dosen Wali"
+	})
+	advisors := map[string]uuid.UUID{}
+	if lecturers, err := s.lecturerRepo.FindAll(ctx); err == nil {
+		for _, l := range lecturers {
+			advisors[l.NIP] = l.ID
+		}
+	}
+
+	for i, p := range planned {
+		if err := s.importOne(ctx, p, advisors); err != nil {
+			job.FailedRows++
+			job.Errors = append(job.Errors, models.ImportRowError{Row: p.Line, Message: err.Error()})
+		} else {
+			job.SucceededRows++
+		}
+		job.ProcessedRows++
+		if (i+1)%importProgressEvery == 0 {
+			s.saveJob(ctx, job)
+		}
+	}
+
+	now := time.Now()
+	job.Status, job.FinishedAt = models.ImportCompleted, &now
+	s.saveJob(ctx, job)
+	log.Printf("[IMPORT] job %s finished: %d succeeded, %d failed", job.ID, job.SucceededRows, job.FailedRows)
+}
+
+func (s *userImportService) importOne(ctx context.Context, p plannedUser, advisors map[string]uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, importRowTimeout)
+	defer cancel()
+
+	in := p.Input
+	if p.AdvisorNIP != "" {
+		id, ok := advisors[p.AdvisorNIP]
+		if !ok {
+			return fmt.Errorf("advisor with NIP %s was not created", p.AdvisorNIP)
+		}
+		in.AdvisorID = &id
+	}
+	if in.Password == "" {
+		in.Password = randomPassword()
+	}
+
+	user, err := createUserWithProfile(ctx, s.adminRepo, s.uow, in)
+	if err != nil {
+		return err
+	}
+	if in.RoleName == "Dosen Wali" {
+		if lecturer, err := s.lecturerRepo.FindByUserID(ctx, user.ID); err == nil {
+			advisors[lecturer.NIP] = lecturer.ID
+		}
+	}
+	return nil
+}
+
+func (s *userImportService) saveJob(ctx context.Context, job models.ImportJob) {
+	if err := s.jobRepo.Save(ctx, job); err != nil {
+		log.Printf("[IMPORT] failed to save job %s: %v", job.ID, err)
+	}
+}
+
+func randomPassword() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func (s *userImportService) GetJob(c *fiber.Ctx) error {
+	ctx := c.UserContext()
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(helper.APIResponse("error", "Invalid job ID", nil))
+	}
+	job, err := s.jobRepo.FindByID(ctx, id)
+	if err != nil {
+		return c.Status(404).JSON(helper.APIResponse("error", "Import job not found", nil))
+	}
+	return c.Status(200).JSON(helper.APIResponse("success", "Import job", job))
+}
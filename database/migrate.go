@@ -1,8 +1,17 @@
 package database
 
 import (
+	"context"
+	"fmt"
 	"gouas/app/models"
+	"gouas/helper"
 	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func Migrate() {
@@ -10,13 +19,346 @@ func Migrate() {
 		&models.Role{},
 		&models.Permission{},
 		&models.User{},
+		&models.UserSession{},
+		&models.RefreshToken{},
+		&models.PasswordResetToken{},
+		&models.LoginAttempt{},
+		&models.Faculty{},
+		&models.Department{},
+		&models.StudyProgram{},
 		&models.Student{}, // Dibuat DULUAN
 		&models.Lecturer{},
+		&models.UserScope{},
+		&models.AdvisorAssignment{},
 		&models.AchievementReference{}, // Dibuat TERAKHIR
+		&models.AchievementStatusEvent{},
+		&models.PointTransaction{},
+		&models.PointRule{},
+		&models.AchievementType{},
+		&models.OutboxEvent{},
+		&models.ImportJob{},
 	)
 
 	if err != nil {
 		log.Fatal("Failed to migrate database: ", err)
 	}
+
+	if err := backfillPointLedger(); err != nil {
+		log.Fatal("Failed to backfill point ledger: ", err)
+	}
+	if err := backfillAchievementTypes(); err != nil {
+		log.Fatal("Failed to backfill achievement types: ", err)
+	}
+	if err := migrateLegacyOrganization(); err != nil {
+		log.Fatal("Failed to migrate organization data: ", err)
+	}
+	if err := backfillAdvisorHistory(); err != nil {
+		log.Fatal("Failed to backfill advisor history: ", err)
+	}
+	if err := dropLegacyTokenColumns(); err != nil {
+		log.Fatal("Failed to drop legacy token columns: ", err)
+	}
 	log.Println("Database migration completed successfully")
 }
+
+// backfillPointLedger mencatat total_points lama sebagai saldo awal,
+// hanya dijalankan sekali saat ledger masih kosong
+func backfillPointLedger() error {
+	var count int64
+	if err := DB.Model(&models.PointTransaction{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	return DB.Exec(`
+		INSERT INTO point_transactions (student_id, reason, amount, note, created_at)
+		SELECT id, ?, total_points, 'Saldo awal sebelum ledger poin', NOW()
+		FROM students
+		WHERE total_points <> 0`, models.PointReasonOpeningBalance).Error
+}
+
+// backfillAdvisorHistory mencatat dosen wali yang sudah ada sebagai riwayat awal,
+// hanya dijalankan sekali saat tabel riwayat masih kosong
+func backfillAdvisorHistory() error {
+	var count int64
+	if err := DB.Model(&models.AdvisorAssignment{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	return DB.Exec(`
+		INSERT INTO advisor_assignments (student_id, lecturer_id, reason, note, effective_from, created_at)
+		SELECT id, advisor_id, ?, 'Dosen wali sebelum riwayat penugasan', created_at, NOW()
+		FROM students
+		WHERE advisor_id IS NOT NULL`, models.AssignLegacy).Error
+}
+
+// dropLegacyTokenColumns menghapus penyimpanan token lama: whitelist single-session di users
+// (diganti user_sessions) dan hash refresh token di session (diganti refresh_tokens).
+// Token lama tidak lolos validasi baru sehingga user cukup login ulang.
+func dropLegacyTokenColumns() error {
+	m := DB.Migrator()
+	legacy := []struct {
+		model interface{}
+		col   string
+	}{
+		{&models.User{}, "current_access_token_id"},
+		{&models.User{}, "current_refresh_token_id"},
+		{&models.UserSession{}, "refresh_token_hash"},
+	}
+	for _, l := range legacy {
+		if m.HasColumn(l.model, l.col) {
+			if err := m.DropColumn(l.model, l.col); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// backfillAchievementTypes menyalin achievementType dari Mongo ke reference lama yang belum punya
+func backfillAchievementTypes() error {
+	var refs []models.AchievementReference
+	if err := DB.Select("id", "mongo_achievement_id").
+		Where("achievement_type IS NULL OR achievement_type = ''").Find(&refs).Error; err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	for _, ref := range refs {
+		objID, err := primitive.ObjectIDFromHex(ref.MongoAchievementID)
+		if err != nil {
+			continue
+		}
+		var doc models.Achievement
+		if err := MongoDB.Collection("achievements").FindOne(ctx, bson.M{"_id": objID}).Decode(&doc); err != nil {
+			continue
+		}
+		if err := DB.Model(&models.AchievementReference{}).Where("id = ?", ref.ID).
+			Update("achievement_type", strings.ToLower(doc.AchievementType)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// normalizedSQL merapikan teks bebas lama (trim + spasi ganda) sebelum dicocokkan
+func normalizedSQL(col string) string {
+	return fmt.Sprintf(`regexp_replace(trim(coalesce(%s, '')), '\s+', ' ', 'g')`, col)
+}
+
+// migrateLegacyOrganization memindahkan teks bebas program_study / department / faculty
+// ke tabel master data, lalu mengisi foreign key mahasiswa, dosen & user scope.
+// Nama dicocokkan case-insensitive sehingga variasi penulisan digabung jadi satu unit;
+// salah ketik dilaporkan untuk di-merge admin.
+func migrateLegacyOrganization() error {
+	m := DB.Migrator()
+	legacyCol := func(model interface{}, col string) string {
+		if m.HasColumn(model, col) {
+			return normalizedSQL(col)
+		}
+		return "''"
+	}
+
+	// 1. Mahasiswa: program_study (+ department/faculty jika ada)
+	if m.HasColumn(&models.Student{}, "program_study") {
+		program := normalizedSQL("program_study")
+		dept, faculty := legacyCol(&models.Student{}, "department"), legacyCol(&models.Student{}, "faculty")
+		var rows []struct{ Program, Department, Faculty string }
+		err := DB.Raw(`SELECT DISTINCT ` + program + ` AS program, ` + dept + ` AS department, ` + faculty + ` AS faculty
+			FROM students WHERE study_program_id IS NULL AND ` + program + ` <> ''`).Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			facultyID, err := ensureFaculty(row.Faculty)
+			if err != nil {
+				return err
+			}
+			deptID, err := ensureDepartment(row.Department, facultyID)
+			if err != nil {
+				return err
+			}
+			programID, err := ensureStudyProgram(row.Program, deptID)
+			if err != nil {
+				return err
+			}
+			// Nama program sama bisa ada di jurusan berbeda: cocokkan juga jurusan & fakultas lamanya
+			if err := DB.Exec(`UPDATE students SET study_program_id = ?
+				WHERE study_program_id IS NULL AND lower(`+program+`) = lower(?)
+				AND lower(`+dept+`) = lower(?) AND lower(`+faculty+`) = lower(?)`,
+				programID, row.Program, row.Department, row.Faculty).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	// 2. Dosen: department (+ faculty jika ada)
+	if m.HasColumn(&models.Lecturer{}, "department") {
+		dept, faculty := normalizedSQL("department"), legacyCol(&models.Lecturer{}, "faculty")
+		var rows []struct{ Department, Faculty string }
+		err := DB.Raw(`SELECT DISTINCT ` + dept + ` AS department, ` + faculty + ` AS faculty
+			FROM lecturers WHERE department_id IS NULL AND ` + dept + ` <> ''`).Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			facultyID, err := ensureFaculty(row.Faculty)
+			if err != nil {
+				return err
+			}
+			deptID, err := ensureDepartment(row.Department, facultyID)
+			if err != nil {
+				return err
+			}
+			if err := DB.Exec(`UPDATE lecturers SET department_id = ?
+				WHERE department_id IS NULL AND lower(`+dept+`) = lower(?) AND lower(`+faculty+`) = lower(?)`,
+				deptID, row.Department, row.Faculty).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	// 3. User scope lama menyimpan nama unit (kolom value) -> unit_id
+	if m.HasColumn(&models.UserScope{}, "value") {
+		tables := map[string]string{
+			models.UnitFaculty:      "faculties",
+			models.UnitDepartment:   "departments",
+			models.UnitProgramStudy: "study_programs",
+		}
+		var legacy []struct {
+			ID     uuid.UUID
+			UserID uuid.UUID
+			Unit   string
+			Value  string
+		}
+		if err := DB.Raw(`SELECT id, user_id, unit, value FROM user_scopes WHERE unit_id IS NULL`).Scan(&legacy).Error; err != nil {
+			return err
+		}
+		seen := map[string]bool{}
+		for _, sc := range legacy {
+			// Nama yang tidak ditemukan tetap membatasi akses (uuid nol tidak cocok dengan unit mana pun)
+			target := uuid.Nil
+			if table, ok := tables[sc.Unit]; ok {
+				id, err := findUnitID(table, strings.Join(strings.Fields(sc.Value), " "), "", nil)
+				if err != nil {
+					return err
+				}
+				if id != nil {
+					target = *id
+				}
+			}
+			key := sc.UserID.String() + "|" + sc.Unit + "|" + target.String()
+			if seen[key] {
+				// Variasi penulisan yang sama -> satu scope saja
+				if err := DB.Exec(`DELETE FROM user_scopes WHERE id = ?`, sc.ID).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			seen[key] = true
+			if err := DB.Exec(`UPDATE user_scopes SET unit_id = ? WHERE id = ?`, target, sc.ID).Error; err != nil {
+				return err
+			}
+		}
+		if err := m.DropColumn(&models.UserScope{}, "value"); err != nil {
+			return err
+		}
+	}
+
+	if err := reportSimilarUnitNames(); err != nil {
+		return err
+	}
+
+	// Nama unit unik tanpa membedakan huruf besar/kecil di dalam parent yang sama: jurusan
+	// "Informatika" boleh ada di dua fakultas. Parent NULL (data migrasi) dihitung satu grup.
+	if err := DB.Exec(`DROP INDEX IF EXISTS idx_departments_name_lower, idx_study_programs_name_lower`).Error; err != nil {
+		return err
+	}
+	for _, idx := range []struct{ table, parent string }{
+		{"faculties", ""},
+		{"departments", "faculty_id"},
+		{"study_programs", "department_id"},
+	} {
+		name, cols := "idx_"+idx.table+"_name_lower", "lower(name)"
+		if idx.parent != "" {
+			name = "idx_" + idx.table + "_" + idx.parent + "_name_lower"
+			cols = "coalesce(" + idx.parent + ", '00000000-0000-0000-0000-000000000000'::uuid), " + cols
+		}
+		if err := DB.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS ` + name + ` ON ` + idx.table + ` (` + cols + `)`).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reportSimilarUnitNames mencatat nama unit yang kemungkinan salah ketik (mis. "Informatka").
+// Tidak digabung otomatis karena nama mirip bisa saja unit berbeda (D3 vs D4); admin mereview
+// lewat GET /api/v1/organization/duplicates lalu POST /api/v1/organization/merge.
+func reportSimilarUnitNames() error {
+	for _, table := range []string{"faculties", "departments", "study_programs"} {
+		var names []string
+		if err := DB.Raw(`SELECT name FROM ` + table + ` ORDER BY name`).Scan(&names).Error; err != nil {
+			return err
+		}
+		for _, pair := range helper.SimilarNames(names) {
+			log.Printf("[MIGRATE] possible duplicate in %s: %q ~ %q (review via /api/v1/organization/duplicates)", table, names[pair[0]], names[pair[1]])
+		}
+	}
+	return nil
+}
+
+// findUnitID mencari unit berdasarkan nama (case-insensitive).
+// parentCol kosong = cari di seluruh tabel; selain itu hanya di bawah parentID (NULL ikut dicocokkan)
+func findUnitID(table, name, parentCol string, parentID *uuid.UUID) (*uuid.UUID, error) {
+	q := DB.Table(table).Select("id").Where("lower(name) = lower(?)", name)
+	if parentCol != "" {
+		q = q.Where(parentCol+" IS NOT DISTINCT FROM ?", parentID)
+	}
+	var ids []uuid.UUID
+	if err := q.Limit(1).Scan(&ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return &ids[0], nil
+}
+
+func ensureFaculty(name string) (*uuid.UUID, error) {
+	if name == "" {
+		return nil, nil
+	}
+	if id, err := findUnitID("faculties", name, "", nil); err != nil || id != nil {
+		return id, err
+	}
+	faculty := models.Faculty{Name: name}
+	err := DB.Create(&faculty).Error
+	return &faculty.ID, err
+}
+
+func ensureDepartment(name string, facultyID *uuid.UUID) (*uuid.UUID, error) {
+	if name == "" {
+		return nil, nil
+	}
+	if id, err := findUnitID("departments", name, "faculty_id", facultyID); err != nil || id != nil {
+		return id, err
+	}
+	dept := models.Department{Name: name, FacultyID: facultyID}
+	err := DB.Create(&dept).Error
+	return &dept.ID, err
+}
+
+func ensureStudyProgram(name string, departmentID *uuid.UUID) (*uuid.UUID, error) {
+	if id, err := findUnitID("study_programs", name, "department_id", departmentID); err != nil || id != nil {
+		return id, err
+	}
+	program := models.StudyProgram{Name: name, DepartmentID: departmentID}
+	err := DB.Create(&program).Error
+	return &program.ID, err
+}
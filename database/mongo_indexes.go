@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// EnsureMongoIndexes membuat index yang dibutuhkan aplikasi (idempotent)
+func EnsureMongoIndexes() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	achievements := MongoDB.Collection("achievements")
+
+	// Text index untuk pencarian prestasi
+	textIndex := mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "title", Value: "text"},
+			{Key: "description", Value: "text"},
+			{Key: "tags", Value: "text"},
+			{Key: "details.competitionName", Value: "text"},
+			{Key: "details.organizer", Value: "text"},
+		},
+		Options: options.Index().
+			SetName("achievement_text").
+			SetDefaultLanguage("none").
+			SetWeights(bson.D{
+				{Key: "title", Value: 10},
+				{Key: "tags", Value: 5},
+				{Key: "details.competitionName", Value: 3},
+				{Key: "description", Value: 1},
+				{Key: "details.organizer", Value: 1},
+			}),
+	}
+
+	studentIndex := mongo.IndexModel{
+		Keys:    bson.D{{Key: "studentId", Value: 1}},
+		Options: options.Index().SetName("achievement_student"),
+	}
+
+	if _, err := achievements.Indexes().CreateMany(ctx, []mongo.IndexModel{textIndex, studentIndex}); err != nil {
+		log.Fatal("Failed to create MongoDB indexes: ", err)
+	}
+	log.Println("MongoDB indexes ensured")
+}
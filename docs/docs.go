@@ -14,9 +14,18 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "tags": ["5.1 Authentication"],
+                "summary": "JSON Web Key Set",
+                "description": "Public key (RS256/EdDSA) untuk memvalidasi token gouas; header kid pada token menunjuk key di sini. Selama rotasi, key lama tetap dipublikasikan. Kosong jika server memakai HS256 (development).",
+                "produces": ["application/json"],
+                "responses": { "200": { "description": "OK" } }
+            }
+        },
         "/api/v1/auth/login": {
             "post": {
-                "description": "Login user",
+                "description": "Login user. Setiap login membuat session baru; session di perangkat lain tetap aktif. Jika mustChangePassword=true, endpoint lain ditolak (403) sampai password diganti lewat /auth/change-password.",
                 "consumes": ["application/json"],
                 "produces": ["application/json"],
                 "tags": ["5.1 Authentication"],
@@ -31,12 +40,18 @@ const docTemplate = `{
                             "type": "object",
                             "properties": {
                                 "username": { "type": "string", "example": "admin" },
-                                "password": { "type": "string", "example": "admin123" }
+                                "password": { "type": "string", "example": "admin123" },
+                                "device": { "type": "string", "example": "Laptop Kantor" }
                             }
                         }
                     }
                 ],
-                "responses": { "200": { "description": "OK" } }
+                "responses": {
+                    "200": { "description": "OK" },
+                    "401": { "description": "Invalid credentials (juga untuk user tidak ada / nonaktif)" },
+                    "423": { "description": "Username dikunci sementara setelah LOGIN_LOCK_THRESHOLD kali gagal (selama LOGIN_LOCK_DURATION); lihat header Retry-After" },
+                    "429": { "description": "Backoff per username / IP; lihat header Retry-After" }
+                }
             }
         },
         "/api/v1/auth/refresh": {
@@ -44,23 +59,136 @@ const docTemplate = `{
                 "security": [{"BearerAuth": []}],
                 "tags": ["5.1 Authentication"],
                 "summary": "Refresh Token",
+                "description": "Kirim refresh token di header Authorization. Setiap refresh mengembalikan access token DAN refresh token baru; refresh token lama tidak berlaku lagi. Memakai ulang refresh token yang sudah dirotasi mencabut seluruh session.",
+                "responses": { "200": { "description": "OK" }, "401": { "description": "Invalid, revoked or reused refresh token" } }
+            }
+        },
+        "/api/v1/auth/change-password": {
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.1 Authentication"],
+                "summary": "Change Password",
+                "description": "Password baru harus memenuhi policy (panjang minimal, tidak mengandung username, tidak ada di daftar password bocor). Session lain dicabut. Cek currentPassword memakai counter gagal yang sama dengan login.",
+                "parameters": [
+                    {
+                        "name": "body", "in": "body", "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "currentPassword": { "type": "string" },
+                                "newPassword": { "type": "string" }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": { "description": "OK" },
+                    "400": { "description": "Wrong current password / policy violation" },
+                    "423": { "description": "Username dikunci sementara; lihat header Retry-After" },
+                    "429": { "description": "Terlalu banyak password salah; lihat header Retry-After" }
+                }
+            }
+        },
+        "/api/v1/auth/forgot-password": {
+            "post": {
+                "tags": ["5.1 Authentication"],
+                "summary": "Forgot Password",
+                "description": "Mengirim link reset (token sekali pakai, berlaku PASSWORD_RESET_TTL) ke email. Respons selalu sama walau email tidak terdaftar.",
+                "parameters": [
+                    {
+                        "name": "body", "in": "body", "required": true,
+                        "schema": { "type": "object", "properties": { "email": { "type": "string" } } }
+                    }
+                ],
                 "responses": { "200": { "description": "OK" } }
             }
         },
+        "/api/v1/auth/reset-password": {
+            "post": {
+                "tags": ["5.1 Authentication"],
+                "summary": "Reset Password",
+                "description": "Memakai token dari email. Semua session user dicabut.",
+                "parameters": [
+                    {
+                        "name": "body", "in": "body", "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "token": { "type": "string" },
+                                "newPassword": { "type": "string" }
+                            }
+                        }
+                    }
+                ],
+                "responses": { "200": { "description": "OK" }, "400": { "description": "Invalid/expired token or policy violation" } }
+            }
+        },
         "/api/v1/auth/logout": {
             "post": {
                 "security": [{"BearerAuth": []}],
                 "tags": ["5.1 Authentication"],
-                "summary": "Logout",
+                "summary": "Logout (current session)",
+                "responses": { "200": { "description": "OK" } }
+            }
+        },
+        "/api/v1/auth/logout-all": {
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.1 Authentication"],
+                "summary": "Logout from All Devices",
                 "responses": { "200": { "description": "OK" } }
             }
         },
+        "/api/v1/auth/sessions": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.1 Authentication"],
+                "summary": "List Active Sessions",
+                "description": "Perangkat, IP, user agent, waktu login & terakhir aktif. current=true untuk session yang sedang dipakai.",
+                "responses": { "200": { "description": "OK" } }
+            }
+        },
+        "/api/v1/auth/sessions/{id}": {
+            "delete": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.1 Authentication"],
+                "summary": "Revoke Session",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" }, "404": { "description": "Session not found" } }
+            }
+        },
         "/api/v1/auth/profile": {
             "get": {
                 "security": [{"BearerAuth": []}],
                 "tags": ["5.1 Authentication"],
                 "summary": "Get Current Profile",
                 "responses": { "200": { "description": "OK" } }
+            },
+            "put": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.1 Authentication"],
+                "summary": "Update Own Contact (email, phone)",
+                "description": "Field yang tidak dikirim tidak diubah; phone dinormalisasi ke +628xx, \"\" menghapus nomor HP. Mengganti email wajib menyertakan currentPassword (dibatasi counter gagal yang sama dengan login).",
+                "parameters": [
+                    {
+                        "name": "body", "in": "body", "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "email": { "type": "string" },
+                                "phone": { "type": "string", "example": "081234567890" },
+                                "currentPassword": { "type": "string", "description": "Wajib jika email diganti" }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": { "description": "OK" },
+                    "400": { "description": "Validation Failed / wrong current password" },
+                    "409": { "description": "Email already used" },
+                    "423": { "description": "Username dikunci sementara; lihat header Retry-After" },
+                    "429": { "description": "Terlalu banyak password salah; lihat header Retry-After" }
+                }
             }
         },
         "/api/v1/users": {
@@ -84,9 +212,14 @@ const docTemplate = `{
                             "properties": {
                                 "username": { "type": "string" },
                                 "email": { "type": "string" },
-                                "password": { "type": "string" },
+                                "password": { "type": "string", "description": "Harus memenuhi password policy; user wajib ganti password saat login pertama" },
                                 "fullName": { "type": "string" },
-                                "roleName": { "type": "string", "enum": ["Mahasiswa", "Dosen Wali", "Admin"] }
+                                "roleName": { "type": "string", "example": "Mahasiswa" },
+                                "nim": { "type": "string", "description": "Mahasiswa only (optional, placeholder if empty)" },
+                                "nip": { "type": "string", "description": "Dosen Wali only (optional, placeholder if empty)" },
+                                "studyProgramId": { "type": "string", "description": "Mahasiswa only (optional)" },
+                                "academicYear": { "type": "string", "example": "2025", "description": "Mahasiswa only, defaults to current year" },
+                                "departmentId": { "type": "string", "description": "Dosen Wali only (optional)" }
                             }
                         }
                     }
@@ -94,6 +227,29 @@ const docTemplate = `{
                 "responses": { "201": { "description": "Created" } }
             }
         },
+        "/api/v1/users/import": {
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.2 Users (Admin)"],
+                "summary": "Bulk Import Users (CSV/XLSX)",
+                "description": "Kolom: username, email, full_name, role (wajib); password, nim, program, academic_year, advisor_nip (Mahasiswa); nip, department (Dosen Wali). Program/department dicocokkan dengan kode atau nama. Password kosong diganti password acak. dryRun=true hanya memvalidasi; tanpa dryRun seluruh baris harus valid lalu diproses di background (lihat GET /users/import/{id}). Kolom NIP di XLSX sebaiknya berformat teks.",
+                "consumes": ["multipart/form-data"],
+                "parameters": [
+                    { "name": "file", "in": "formData", "required": true, "type": "file" },
+                    { "name": "dryRun", "in": "query", "type": "boolean" }
+                ],
+                "responses": { "200": { "description": "Dry run report" }, "202": { "description": "Import job started" }, "400": { "description": "Validation Failed (per-row errors)" } }
+            }
+        },
+        "/api/v1/users/import/{id}": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.2 Users (Admin)"],
+                "summary": "Get Import Job Progress",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" }, "404": { "description": "Not Found" } }
+            }
+        },
         "/api/v1/users/{id}": {
             "get": {
                 "security": [{"BearerAuth": []}],
@@ -126,11 +282,199 @@ const docTemplate = `{
                 "responses": { "200": { "description": "OK" } }
             }
         },
+        "/api/v1/users/{id}/scopes": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.2 Users (Admin)"],
+                "summary": "Get User Organizational Scopes",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" } }
+            },
+            "put": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.2 Users (Admin)"],
+                "summary": "Replace User Organizational Scopes (empty list = global access)",
+                "parameters": [
+                    { "name": "id", "in": "path", "required": true, "type": "string" },
+                    {
+                        "name": "body", "in": "body", "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "scopes": {
+                                    "type": "array",
+                                    "items": {
+                                        "type": "object",
+                                        "properties": {
+                                            "unit": { "type": "string", "enum": ["faculty", "department", "program_study"] },
+                                            "unitId": { "type": "string", "description": "Faculty / department / study program ID" }
+                                        }
+                                    }
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": { "200": { "description": "OK" } }
+            }
+        },
+        "/api/v1/users/{id}/unlock": {
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.2 Users (Admin)"],
+                "summary": "Unlock User Login",
+                "description": "Me-reset counter login gagal username sehingga lockout & backoff langsung berakhir. Dicatat di audit sebagai admin_unlock.",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" }, "404": { "description": "User not found" } }
+            }
+        },
+        "/api/v1/users/{id}/login-attempts": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.2 Users (Admin)"],
+                "summary": "List Recent Login Attempts",
+                "description": "100 percobaan login terbaru untuk username user (result: success, invalid_credentials, inactive, throttled, locked, admin_unlock).",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" } }
+            }
+        },
+        "/api/v1/roles": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Roles & Permissions (Admin)"],
+                "summary": "List Roles (with permissions)",
+                "responses": { "200": { "description": "OK" } }
+            },
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Roles & Permissions (Admin)"],
+                "summary": "Create Role",
+                "parameters": [
+                    {
+                        "name": "body", "in": "body", "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "name": { "type": "string", "example": "Kaprodi" },
+                                "description": { "type": "string" },
+                                "permissionIds": { "type": "array", "items": { "type": "string" } }
+                            }
+                        }
+                    }
+                ],
+                "responses": { "201": { "description": "Created" } }
+            }
+        },
+        "/api/v1/roles/{id}": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Roles & Permissions (Admin)"],
+                "summary": "Get Role Detail",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" } }
+            },
+            "put": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Roles & Permissions (Admin)"],
+                "summary": "Update Role (system roles cannot be renamed)",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" }, "400": { "description": "System role" } }
+            },
+            "delete": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Roles & Permissions (Admin)"],
+                "summary": "Delete Role",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" }, "400": { "description": "System role" }, "409": { "description": "Role still assigned to users" } }
+            }
+        },
+        "/api/v1/roles/{id}/permissions": {
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Roles & Permissions (Admin)"],
+                "summary": "Attach Permission to Role",
+                "parameters": [
+                    { "name": "id", "in": "path", "required": true, "type": "string" },
+                    {
+                        "name": "body", "in": "body", "required": true,
+                        "schema": { "type": "object", "properties": { "permissionId": { "type": "string" } } }
+                    }
+                ],
+                "responses": { "200": { "description": "OK" } }
+            }
+        },
+        "/api/v1/roles/{id}/permissions/{permissionId}": {
+            "delete": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Roles & Permissions (Admin)"],
+                "summary": "Detach Permission from Role",
+                "parameters": [
+                    { "name": "id", "in": "path", "required": true, "type": "string" },
+                    { "name": "permissionId", "in": "path", "required": true, "type": "string" }
+                ],
+                "responses": { "200": { "description": "OK" }, "409": { "description": "Last active holder of user:manage / role:manage" } }
+            }
+        },
+        "/api/v1/permissions": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Roles & Permissions (Admin)"],
+                "summary": "List Permissions",
+                "responses": { "200": { "description": "OK" } }
+            },
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Roles & Permissions (Admin)"],
+                "summary": "Create Permission",
+                "parameters": [
+                    {
+                        "name": "body", "in": "body", "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "name": { "type": "string", "example": "report:export" },
+                                "description": { "type": "string" }
+                            }
+                        }
+                    }
+                ],
+                "responses": { "201": { "description": "Created" } }
+            }
+        },
+        "/api/v1/permissions/{id}": {
+            "put": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Roles & Permissions (Admin)"],
+                "summary": "Update Permission Description",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" } }
+            },
+            "delete": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Roles & Permissions (Admin)"],
+                "summary": "Delete Permission (custom only)",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" }, "400": { "description": "Built-in permission" } }
+            }
+        },
         "/api/v1/achievements": {
             "get": {
                 "security": [{"BearerAuth": []}],
                 "tags": ["5.4 Achievements"],
                 "summary": "List Achievements (Filtered)",
+                "parameters": [
+                    { "name": "status", "in": "query", "type": "string", "description": "Comma separated, e.g. submitted,verified" },
+                    { "name": "type", "in": "query", "type": "string" },
+                    { "name": "from", "in": "query", "type": "string", "description": "YYYY-MM-DD or RFC3339 (created_at)" },
+                    { "name": "to", "in": "query", "type": "string", "description": "YYYY-MM-DD or RFC3339 (created_at)" },
+                    { "name": "studentId", "in": "query", "type": "string" },
+                    { "name": "studyProgramId", "in": "query", "type": "string" },
+                    { "name": "academicYear", "in": "query", "type": "string" },
+                    { "name": "advisorId", "in": "query", "type": "string" },
+                    { "name": "sort", "in": "query", "type": "string", "description": "created_at, submitted_at, verified_at, status, type, points_awarded; prefix '-' for descending" },
+                    { "name": "page", "in": "query", "type": "integer", "default": 1 },
+                    { "name": "limit", "in": "query", "type": "integer", "default": 20 }
+                ],
                 "responses": { "200": { "description": "OK" } }
             },
             "post": {
@@ -155,6 +499,18 @@ const docTemplate = `{
                 "responses": { "201": { "description": "Created" } }
             }
         },
+        "/api/v1/achievements/search": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.4 Achievements"],
+                "summary": "Full-text Search Achievements",
+                "parameters": [
+                    { "name": "q", "in": "query", "required": true, "type": "string" },
+                    { "name": "limit", "in": "query", "type": "integer", "default": 20 }
+                ],
+                "responses": { "200": { "description": "OK" } }
+            }
+        },
         "/api/v1/achievements/{id}": {
             "get": {
                 "security": [{"BearerAuth": []}],
@@ -174,6 +530,7 @@ const docTemplate = `{
                 "security": [{"BearerAuth": []}],
                 "tags": ["5.4 Achievements"],
                 "summary": "Delete Achievement",
+                "description": "Mahasiswa hanya boleh menghapus draft miliknya. Admin (achievement:manage) boleh menghapus status apa pun; poin prestasi verified ditarik seperti revoke.",
                 "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
                 "responses": { "200": { "description": "OK" } }
             }
@@ -218,6 +575,28 @@ const docTemplate = `{
                 "responses": { "200": { "description": "OK" } }
             }
         },
+        "/api/v1/achievements/{id}/revoke": {
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.4 Achievements"],
+                "summary": "Revoke Verified Achievement (Dosen Wali / Admin)",
+                "parameters": [
+                    { "name": "id", "in": "path", "required": true, "type": "string" },
+                    {
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "reason": { "type": "string" }
+                            }
+                        }
+                    }
+                ],
+                "responses": { "200": { "description": "OK" } }
+            }
+        },
         "/api/v1/achievements/{id}/history": {
             "get": {
                 "security": [{"BearerAuth": []}],
@@ -236,22 +615,138 @@ const docTemplate = `{
                 "responses": { "200": { "description": "OK" } }
             }
         },
-        "/api/v1/students": {
+        "/api/v1/achievement-types": {
             "get": {
                 "security": [{"BearerAuth": []}],
-                "tags": ["5.5 Students & Lecturers"],
-                "summary": "List Students",
+                "tags": ["5.4 Achievements"],
+                "summary": "List Achievement Types (built-in + custom)",
+                "parameters": [{ "name": "includeInactive", "in": "query", "required": false, "type": "boolean" }],
                 "responses": { "200": { "description": "OK" } }
+            },
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.4 Achievements"],
+                "summary": "Register Custom Achievement Type (Admin)",
+                "parameters": [
+                    {
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "name": { "type": "string", "example": "hackathon" },
+                                "label": { "type": "string", "example": "Hackathon" },
+                                "description": { "type": "string" },
+                                "schema": {
+                                    "type": "object",
+                                    "example": {
+                                        "type": "object",
+                                        "required": ["teamName", "durationHours"],
+                                        "properties": {
+                                            "teamName": { "type": "string", "minLength": 1 },
+                                            "durationHours": { "type": "integer", "minimum": 1 }
+                                        }
+                                    }
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": { "201": { "description": "Created" } }
             }
         },
-        "/api/v1/students/{id}": {
+        "/api/v1/achievement-types/{name}": {
             "get": {
                 "security": [{"BearerAuth": []}],
-                "tags": ["5.5 Students & Lecturers"],
-                "summary": "Get Student Detail",
-                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "tags": ["5.4 Achievements"],
+                "summary": "Get Achievement Type",
+                "parameters": [{ "name": "name", "in": "path", "required": true, "type": "string" }],
                 "responses": { "200": { "description": "OK" } }
-            }
+            },
+            "put": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.4 Achievements"],
+                "summary": "Update Custom Achievement Type (Admin)",
+                "parameters": [
+                    { "name": "name", "in": "path", "required": true, "type": "string" },
+                    { "name": "body", "in": "body", "required": true, "schema": { "type": "object" } }
+                ],
+                "responses": { "200": { "description": "OK" } }
+            },
+            "delete": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.4 Achievements"],
+                "summary": "Deactivate Custom Achievement Type (Admin)",
+                "parameters": [{ "name": "name", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" } }
+            }
+        },
+        "/api/v1/students": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.5 Students & Lecturers"],
+                "summary": "List Students",
+                "responses": { "200": { "description": "OK" } }
+            },
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.5 Students & Lecturers"],
+                "summary": "Create Student Profile (Admin)",
+                "description": "User harus ber-role Mahasiswa & belum punya profile. NIM 8-15 digit angka, unik.",
+                "parameters": [
+                    {
+                        "name": "body", "in": "body", "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "userId": { "type": "string" },
+                                "nim": { "type": "string", "example": "2141720001" },
+                                "studyProgramId": { "type": "string" },
+                                "academicYear": { "type": "string", "example": "2024" }
+                            }
+                        }
+                    }
+                ],
+                "responses": { "201": { "description": "Created" }, "400": { "description": "Validation Failed" }, "409": { "description": "NIM already used / profile exists" } }
+            }
+        },
+        "/api/v1/students/{id}": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.5 Students & Lecturers"],
+                "summary": "Get Student Detail",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" } }
+            },
+            "put": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.5 Students & Lecturers"],
+                "summary": "Update Student Profile (Admin)",
+                "description": "Field kosong tidak diubah.",
+                "parameters": [
+                    { "name": "id", "in": "path", "required": true, "type": "string" },
+                    {
+                        "name": "body", "in": "body", "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "nim": { "type": "string" },
+                                "studyProgramId": { "type": "string" },
+                                "academicYear": { "type": "string" }
+                            }
+                        }
+                    }
+                ],
+                "responses": { "200": { "description": "OK" }, "400": { "description": "Validation Failed" }, "409": { "description": "NIM already used" } }
+            },
+            "delete": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.5 Students & Lecturers"],
+                "summary": "Delete Student Profile (Admin)",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" }, "409": { "description": "Student has achievement/point records" } }
+            }
         },
         "/api/v1/students/{id}/achievements": {
             "get": {
@@ -266,7 +761,47 @@ const docTemplate = `{
             "put": {
                 "security": [{"BearerAuth": []}],
                 "tags": ["5.5 Students & Lecturers"],
-                "summary": "Assign Advisor",
+                "summary": "Assign Advisor (Admin)",
+                "description": "Dosen harus ada & aktif. Penugasan lama ditutup di riwayat; prestasi submitted ikut pindah ke dosen baru dan dosen baru mendapat notifikasi.",
+                "parameters": [
+                    { "name": "id", "in": "path", "required": true, "type": "string" },
+                    {
+                        "name": "body", "in": "body", "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "advisorId": { "type": "string" },
+                                "note": { "type": "string" }
+                            }
+                        }
+                    }
+                ],
+                "responses": { "200": { "description": "OK" }, "400": { "description": "Invalid / inactive advisor" }, "404": { "description": "Student not found" }, "409": { "description": "Advisor was changed by another request" } }
+            }
+        },
+        "/api/v1/students/{id}/advisor-history": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.5 Students & Lecturers"],
+                "summary": "Get Advisor History",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" }, "403": { "description": "Forbidden" } }
+            }
+        },
+        "/api/v1/students/{id}/points": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.5 Students & Lecturers"],
+                "summary": "Get Student Point Ledger",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" } }
+            }
+        },
+        "/api/v1/students/{id}/points/reconcile": {
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.5 Students & Lecturers"],
+                "summary": "Reconcile Total Points from Ledger (Admin)",
                 "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
                 "responses": { "200": { "description": "OK" } }
             }
@@ -277,6 +812,55 @@ const docTemplate = `{
                 "tags": ["5.5 Students & Lecturers"],
                 "summary": "List Lecturers",
                 "responses": { "200": { "description": "OK" } }
+            },
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.5 Students & Lecturers"],
+                "summary": "Create Lecturer Profile (Admin)",
+                "description": "User harus ber-role Dosen Wali & belum punya profile. NIP 18 digit: tgl lahir (YYYYMMDD), TMT (YYYYMM), jenis kelamin (1/2), nomor urut.",
+                "parameters": [
+                    {
+                        "name": "body", "in": "body", "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "userId": { "type": "string" },
+                                "nip": { "type": "string", "example": "198503152010121001" },
+                                "departmentId": { "type": "string" }
+                            }
+                        }
+                    }
+                ],
+                "responses": { "201": { "description": "Created" }, "400": { "description": "Validation Failed" }, "409": { "description": "NIP already used / profile exists" } }
+            }
+        },
+        "/api/v1/lecturers/{id}": {
+            "put": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.5 Students & Lecturers"],
+                "summary": "Update Lecturer Profile (Admin)",
+                "description": "Field kosong tidak diubah.",
+                "parameters": [
+                    { "name": "id", "in": "path", "required": true, "type": "string" },
+                    {
+                        "name": "body", "in": "body", "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "nip": { "type": "string" },
+                                "departmentId": { "type": "string" }
+                            }
+                        }
+                    }
+                ],
+                "responses": { "200": { "description": "OK" }, "400": { "description": "Validation Failed" }, "409": { "description": "NIP already used" } }
+            },
+            "delete": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.5 Students & Lecturers"],
+                "summary": "Delete Lecturer Profile (Admin)",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" }, "409": { "description": "Lecturer still has advisees" } }
             }
         },
         "/api/v1/lecturers/{id}/advisees": {
@@ -288,6 +872,210 @@ const docTemplate = `{
                 "responses": { "200": { "description": "OK" } }
             }
         },
+        "/api/v1/lecturers/{id}/reassign-advisees": {
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.5 Students & Lecturers"],
+                "summary": "Reassign All Advisees (Admin)",
+                "description": "Untuk dosen yang berhenti/mutasi. Tanpa targetLecturerId, bimbingan dibagi ke dosen aktif lain di jurusan yang sama berdasarkan beban bimbingan. Mahasiswa yang dosen walinya diubah request lain selama proses dilewati (disebut di message).",
+                "parameters": [
+                    { "name": "id", "in": "path", "required": true, "type": "string" },
+                    {
+                        "name": "body", "in": "body", "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "targetLecturerId": { "type": "string" },
+                                "note": { "type": "string" }
+                            }
+                        }
+                    }
+                ],
+                "responses": { "200": { "description": "OK" }, "400": { "description": "No active target lecturer" }, "404": { "description": "Lecturer not found" } }
+            }
+        },
+        "/api/v1/advisors/balance": {
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["5.5 Students & Lecturers"],
+                "summary": "Auto-balance Unassigned Students (Admin)",
+                "description": "Mahasiswa tanpa dosen wali di program studi jurusan dibagi ke dosen aktif jurusan tersebut, mulai dari beban bimbingan paling sedikit. dryRun=true hanya menampilkan rencana. Mahasiswa yang sudah dibagi request lain selama proses dilewati (disebut di message).",
+                "parameters": [
+                    { "name": "dryRun", "in": "query", "type": "boolean" },
+                    {
+                        "name": "body", "in": "body", "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": { "departmentId": { "type": "string" } }
+                        }
+                    }
+                ],
+                "responses": { "200": { "description": "OK" }, "400": { "description": "No active lecturer in department" } }
+            }
+        },
+        "/api/v1/faculties": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Organization"],
+                "summary": "List Faculties",
+                "responses": { "200": { "description": "OK" } }
+            },
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Organization"],
+                "summary": "Create Faculty",
+                "parameters": [
+                    {
+                        "name": "body", "in": "body", "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": { "type": "string" },
+                                "name": { "type": "string" }
+                            }
+                        }
+                    }
+                ],
+                "responses": { "201": { "description": "Created" }, "409": { "description": "Name already exists" } }
+            }
+        },
+        "/api/v1/faculties/{id}": {
+            "put": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Organization"],
+                "summary": "Update Faculty",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" } }
+            },
+            "delete": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Organization"],
+                "summary": "Delete Faculty",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" }, "409": { "description": "Still referenced" } }
+            }
+        },
+        "/api/v1/departments": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Organization"],
+                "summary": "List Departments",
+                "parameters": [{ "name": "facultyId", "in": "query", "type": "string" }],
+                "responses": { "200": { "description": "OK" } }
+            },
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Organization"],
+                "summary": "Create Department",
+                "parameters": [
+                    {
+                        "name": "body", "in": "body", "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": { "type": "string" },
+                                "name": { "type": "string" },
+                                "facultyId": { "type": "string" }
+                            }
+                        }
+                    }
+                ],
+                "responses": { "201": { "description": "Created" }, "409": { "description": "Name already exists" } }
+            }
+        },
+        "/api/v1/departments/{id}": {
+            "put": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Organization"],
+                "summary": "Update Department",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" } }
+            },
+            "delete": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Organization"],
+                "summary": "Delete Department",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" }, "409": { "description": "Still referenced" } }
+            }
+        },
+        "/api/v1/study-programs": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Organization"],
+                "summary": "List Study Programs",
+                "parameters": [{ "name": "departmentId", "in": "query", "type": "string" }],
+                "responses": { "200": { "description": "OK" } }
+            },
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Organization"],
+                "summary": "Create Study Program",
+                "parameters": [
+                    {
+                        "name": "body", "in": "body", "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "code": { "type": "string" },
+                                "name": { "type": "string" },
+                                "degree": { "type": "string", "example": "S1" },
+                                "departmentId": { "type": "string" }
+                            }
+                        }
+                    }
+                ],
+                "responses": { "201": { "description": "Created" }, "409": { "description": "Name already exists" } }
+            }
+        },
+        "/api/v1/study-programs/{id}": {
+            "put": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Organization"],
+                "summary": "Update Study Program",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" } }
+            },
+            "delete": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Organization"],
+                "summary": "Delete Study Program",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" }, "409": { "description": "Still referenced" } }
+            }
+        },
+        "/api/v1/organization/duplicates": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Organization"],
+                "summary": "List Possible Duplicate Units",
+                "description": "Pasangan fakultas/jurusan/program studi dengan nama mirip (salah ketik, mis. Informatka vs Informatika) beserta jumlah rujukannya. Source (rujukan lebih sedikit) disarankan di-merge ke target.",
+                "parameters": [{ "name": "unit", "in": "query", "type": "string", "enum": ["faculty", "department", "program_study"] }],
+                "responses": { "200": { "description": "OK" } }
+            }
+        },
+        "/api/v1/organization/merge": {
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Organization"],
+                "summary": "Merge Units",
+                "description": "Memindahkan semua rujukan (sub-unit, mahasiswa/dosen & scope user) dari source ke target lalu menghapus source, dalam satu transaksi.",
+                "parameters": [
+                    {
+                        "name": "body", "in": "body", "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "unit": { "type": "string", "enum": ["faculty", "department", "program_study"] },
+                                "sourceId": { "type": "string" },
+                                "targetId": { "type": "string" }
+                            }
+                        }
+                    }
+                ],
+                "responses": { "200": { "description": "OK" }, "400": { "description": "Invalid unit / same source and target" }, "404": { "description": "Unit not found" } }
+            }
+        },
         "/api/v1/reports/statistics": {
             "get": {
                 "security": [{"BearerAuth": []}],
@@ -304,6 +1092,99 @@ const docTemplate = `{
                 "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
                 "responses": { "200": { "description": "OK" } }
             }
+        },
+        "/api/v1/admin/point-rules": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Admin: Point Rules"],
+                "summary": "List Point Rules",
+                "responses": { "200": { "description": "OK" } }
+            },
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Admin: Point Rules"],
+                "summary": "Create Point Rule",
+                "parameters": [
+                    {
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "name": { "type": "string", "example": "Juara 1 Nasional" },
+                                "achievementType": { "type": "string", "example": "competition" },
+                                "competitionLevel": { "type": "string", "example": "National" },
+                                "rank": { "type": "integer", "example": 1 },
+                                "medalType": { "type": "string" },
+                                "publicationType": { "type": "string" },
+                                "position": { "type": "string" },
+                                "points": { "type": "integer", "example": 40 },
+                                "priority": { "type": "integer", "example": 0, "description": "Rule cocok dengan priority tertinggi dipakai; jika sama, yang kriterianya paling spesifik" },
+                                "isActive": { "type": "boolean", "example": true },
+                                "validFrom": { "type": "string", "format": "date-time" },
+                                "validUntil": { "type": "string", "format": "date-time" }
+                            }
+                        }
+                    }
+                ],
+                "responses": { "201": { "description": "Created" } }
+            }
+        },
+        "/api/v1/admin/point-rules/{id}": {
+            "put": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Admin: Point Rules"],
+                "summary": "Update Point Rule",
+                "parameters": [
+                    { "name": "id", "in": "path", "required": true, "type": "string" },
+                    { "name": "body", "in": "body", "required": true, "schema": { "type": "object" } }
+                ],
+                "responses": { "200": { "description": "OK" } }
+            }
+        },
+        "/api/v1/admin/point-rules/dry-run": {
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Admin: Point Rules"],
+                "summary": "Preview Points for an Achievement",
+                "parameters": [
+                    {
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "achievementId": { "type": "string" },
+                                "achievementType": { "type": "string" },
+                                "details": { "type": "object" },
+                                "points": { "type": "integer" }
+                            }
+                        }
+                    }
+                ],
+                "responses": { "200": { "description": "OK" } }
+            }
+        },
+        "/api/v1/outbox/failed": {
+            "get": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Admin: Outbox"],
+                "summary": "List Failed Outbox Events",
+                "description": "100 event terakhir yang berhenti dicoba setelah batas retry (notifikasi, sinkronisasi Mongo, award lama).",
+                "responses": { "200": { "description": "OK" } }
+            }
+        },
+        "/api/v1/outbox/{id}/retry": {
+            "post": {
+                "security": [{"BearerAuth": []}],
+                "tags": ["Admin: Outbox"],
+                "summary": "Requeue Failed Outbox Event",
+                "description": "Mengembalikan event failed ke antrean dengan counter percobaan baru. Handler idempotent sehingga aman dijalankan ulang.",
+                "parameters": [{ "name": "id", "in": "path", "required": true, "type": "string" }],
+                "responses": { "200": { "description": "OK" }, "404": { "description": "Failed event not found" } }
+            }
         }
     },
     "securityDefinitions": {
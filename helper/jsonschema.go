@@ -0,0 +1,209 @@
+package helper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// SchemaError adalah satu pelanggaran JSON Schema pada path tertentu
+type SchemaError struct {
+	Path    string
+	Message string
+}
+
+// ParseJSONSchema mem-parse schema dan memastikan keyword yang dipakai dikenali.
+// Yang didukung adalah subset draft-07: type, properties, required, additionalProperties,
+// enum, minimum, maximum, minLength, maxLength, pattern, format, items, minItems, maxItems.
+func ParseJSONSchema(raw []byte) (map[string]interface{}, error) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, errors.New("schema must be a JSON object")
+	}
+	if t, _ := schema["type"].(string); t != "object" {
+		return nil, errors.New(`root schema must have "type": "object"`)
+	}
+	if err := checkSchema(schema, "#"); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func checkSchema(schema map[string]interface{}, path string) error {
+	if p, ok := schema["pattern"].(string); ok {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("%s: invalid pattern: %v", path, err)
+		}
+	}
+	if props, ok := schema["properties"]; ok {
+		m, ok := props.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: properties must be an object", path)
+		}
+		for name, sub := range m {
+			subSchema, ok := sub.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s/properties/%s: must be an object", path, name)
+			}
+			if err := checkSchema(subSchema, path+"/properties/"+name); err != nil {
+				return err
+			}
+		}
+	}
+	if items, ok := schema["items"]; ok {
+		subSchema, ok := items.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s/items: must be an object", path)
+		}
+		return checkSchema(subSchema, path+"/items")
+	}
+	return nil
+}
+
+// ValidateJSONSchema memvalidasi data (hasil json.Unmarshal) terhadap schema
+func ValidateJSONSchema(schema map[string]interface{}, data interface{}, path string) []SchemaError {
+	var errs []SchemaError
+	fail := func(msg string) {
+		errs = append(errs, SchemaError{Path: path, Message: msg})
+	}
+
+	if t, ok := schema["type"].(string); ok && !matchesType(t, data) {
+		fail("must be of type " + t)
+		return errs
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, e := range enum {
+			if fmt.Sprint(e) == fmt.Sprint(data) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail(fmt.Sprintf("must be one of %v", enum))
+		}
+	}
+
+	switch v := data.(type) {
+	case string:
+		if n, ok := number(schema["minLength"]); ok && float64(len([]rune(v))) < n {
+			fail(fmt.Sprintf("must be at least %v characters", n))
+		}
+		if n, ok := number(schema["maxLength"]); ok && float64(len([]rune(v))) > n {
+			fail(fmt.Sprintf("must be at most %v characters", n))
+		}
+		if p, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(p); err == nil && !re.MatchString(v) {
+				fail("must match pattern " + p)
+			}
+		}
+		if f, ok := schema["format"].(string); ok && !matchesFormat(f, v) {
+			fail("must be a valid " + f)
+		}
+	case float64:
+		if n, ok := number(schema["minimum"]); ok && v < n {
+			fail(fmt.Sprintf("must be >= %v", n))
+		}
+		if n, ok := number(schema["maximum"]); ok && v > n {
+			fail(fmt.Sprintf("must be <= %v", n))
+		}
+	case []interface{}:
+		if n, ok := number(schema["minItems"]); ok && float64(len(v)) < n {
+			fail(fmt.Sprintf("must have at least %v items", n))
+		}
+		if n, ok := number(schema["maxItems"]); ok && float64(len(v)) > n {
+			fail(fmt.Sprintf("must have at most %v items", n))
+		}
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				errs = append(errs, ValidateJSONSchema(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case map[string]interface{}:
+		props, _ := schema["properties"].(map[string]interface{})
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, r := range required {
+				name, _ := r.(string)
+				if _, exists := v[name]; !exists {
+					errs = append(errs, SchemaError{Path: joinPath(path, name), Message: "is required"})
+				}
+			}
+		}
+
+		// Urutkan key agar urutan error stabil
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if sub, ok := props[k].(map[string]interface{}); ok {
+				errs = append(errs, ValidateJSONSchema(sub, v[k], joinPath(path, k))...)
+			} else if allowed, ok := schema["additionalProperties"].(bool); ok && !allowed {
+				errs = append(errs, SchemaError{Path: joinPath(path, k), Message: "is not allowed"})
+			}
+		}
+	}
+
+	return errs
+}
+
+func matchesType(t string, data interface{}) bool {
+	switch t {
+	case "object":
+		_, ok := data.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := data.([]interface{})
+		return ok
+	case "string":
+		_, ok := data.(string)
+		return ok
+	case "number":
+		_, ok := data.(float64)
+		return ok
+	case "integer":
+		f, ok := data.(float64)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := data.(bool)
+		return ok
+	case "null":
+		return data == nil
+	}
+	return true
+}
+
+func matchesFormat(format, value string) bool {
+	switch format {
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "email":
+		_, err := mail.ParseAddress(value)
+		return err == nil
+	}
+	return true
+}
+
+func number(v interface{}) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok
+}
+
+func joinPath(base, key string) string {
+	if base == "" {
+		return key
+	}
+	return base + "." + key
+}
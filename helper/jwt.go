@@ -1,66 +1,88 @@
 package helper
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
-	"gouas/config"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
+// Jenis token; refresh token tidak boleh dipakai sebagai access token dan sebaliknya
+const (
+	TokenAccess  = "access"
+	TokenRefresh = "refresh"
+)
+
 type JWTClaims struct {
 	UserID      uuid.UUID `json:"user_id"`
 	Role        string    `json:"role"`
 	Permissions []string  `json:"permissions"`
 	TokenID     uuid.UUID `json:"jti"` // [BARU] ID Unik Token
+	SessionID   uuid.UUID `json:"sid"` // session (perangkat) pemilik token
+	Type        string    `json:"typ"`
 	jwt.RegisteredClaims
 }
 
+// RefreshTokenTTL juga dipakai sebagai masa berlaku session
+const RefreshTokenTTL = 24 * time.Hour
+
 // GenerateAccessToken membuat token pendek (15 menit)
-func GenerateAccessToken(userID uuid.UUID, roleName string, permissions []string, tokenID uuid.UUID) (string, error) {
+func GenerateAccessToken(userID uuid.UUID, roleName string, permissions []string, tokenID uuid.UUID, sessionID uuid.UUID) (string, error) {
 	claims := JWTClaims{
 		UserID:      userID,
 		Role:        roleName,
 		Permissions: permissions,
 		TokenID:     tokenID, // Simpan ID
+		SessionID:   sessionID,
+		Type:        TokenAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)), // 15 Menit
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "gouas-backend",
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(config.GetEnv("JWT_SECRET", "secret")))
+	return signJWT(claims)
 }
 
 // GenerateRefreshToken membuat token panjang (24 jam)
-func GenerateRefreshToken(userID uuid.UUID, tokenID uuid.UUID) (string, error) {
+func GenerateRefreshToken(userID uuid.UUID, tokenID uuid.UUID, sessionID uuid.UUID) (string, error) {
 	claims := JWTClaims{
-		UserID:  userID,
-		TokenID: tokenID, // Simpan ID
+		UserID:    userID,
+		TokenID:   tokenID, // Simpan ID
+		SessionID: sessionID,
+		Type:      TokenRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // 24 Jam
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(RefreshTokenTTL)), // 24 Jam
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "gouas-backend",
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(config.GetEnv("JWT_SECRET", "secret")))
+	return signJWT(claims)
 }
 
-func ValidateJWT(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(config.GetEnv("JWT_SECRET", "secret")), nil
-	})
+// ValidateJWT memvalidasi signature, masa berlaku & jenis token
+func ValidateJWT(tokenString string, tokenType string) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, jwtKeyFunc)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*JWTClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
 	}
+	if claims.Type != tokenType {
+		return nil, errors.New("invalid token type")
+	}
+	return claims, nil
+}
 
-	return nil, errors.New("invalid token")
-}
\ No newline at end of file
+// HashToken menghasilkan sha256 hex; refresh token tidak disimpan mentah di DB
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,240 @@
+package helper
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gouas/config"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTKeyConfig menentukan cara token ditandatangani.
+// PrivateKeyFile (PEM RSA / Ed25519) -> RS256 / EdDSA dengan header kid.
+// Tanpa PrivateKeyFile dipakai HS256 dengan Secret (hanya untuk development).
+type JWTKeyConfig struct {
+	Env            string
+	Secret         string
+	PrivateKeyFile string
+	// PublicKeyFiles berisi public key lama yang masih diterima selama rotasi kunci
+	PublicKeyFiles []string
+}
+
+const defaultJWTSecret = "secret"
+
+type verifyKey struct {
+	method jwt.SigningMethod
+	key    crypto.PublicKey
+}
+
+type jwtKeySet struct {
+	method  jwt.SigningMethod
+	kid     string
+	signKey interface{}
+	secret  []byte               // hanya mode HS256
+	verify  map[string]verifyKey // kid -> public key
+	kids    []string             // urutan kunci di JWKS (kunci aktif dulu)
+}
+
+var (
+	jwtKeysMu sync.RWMutex
+	jwtKeys   *jwtKeySet
+)
+
+// LoadJWTKeysFromEnv membaca JWT_PRIVATE_KEY_FILE, JWT_PUBLIC_KEY_FILES (dipisah koma),
+// JWT_SECRET & APP_ENV
+func LoadJWTKeysFromEnv() error {
+	var publicFiles []string
+	for _, f := range strings.Split(config.GetEnv("JWT_PUBLIC_KEY_FILES", ""), ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			publicFiles = append(publicFiles, f)
+		}
+	}
+	return LoadJWTKeys(JWTKeyConfig{
+		Env:            config.GetEnv("APP_ENV", "development"),
+		Secret:         config.GetEnv("JWT_SECRET", ""),
+		PrivateKeyFile: config.GetEnv("JWT_PRIVATE_KEY_FILE", ""),
+		PublicKeyFiles: publicFiles,
+	})
+}
+
+// LoadJWTKeys memuat kunci penandatangan & verifikasi. Di production, mode HS256 dengan
+// secret kosong / default ditolak.
+func LoadJWTKeys(cfg JWTKeyConfig) error {
+	set := &jwtKeySet{verify: map[string]verifyKey{}}
+
+	if cfg.PrivateKeyFile == "" {
+		if cfg.Secret == "" || cfg.Secret == defaultJWTSecret {
+			if cfg.Env == "production" {
+				return errors.New("JWT_SECRET is empty or default; set JWT_PRIVATE_KEY_FILE (recommended) or a strong JWT_SECRET")
+			}
+			cfg.Secret = defaultJWTSecret
+		}
+		set.method = jwt.SigningMethodHS256
+		set.secret = []byte(cfg.Secret)
+	} else {
+		data, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return fmt.Errorf("read JWT private key: %w", err)
+		}
+		if key, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+			set.method, set.signKey = jwt.SigningMethodRS256, key
+			set.kid, err = set.addVerifyKey(&key.PublicKey)
+			if err != nil {
+				return err
+			}
+		} else if key, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+			set.method, set.signKey = jwt.SigningMethodEdDSA, key
+			set.kid, err = set.addVerifyKey(key.(ed25519.PrivateKey).Public())
+			if err != nil {
+				return err
+			}
+		} else {
+			return fmt.Errorf("%s: unsupported private key (expected RSA or Ed25519 PEM)", cfg.PrivateKeyFile)
+		}
+	}
+
+	for _, file := range cfg.PublicKeyFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("read JWT public key: %w", err)
+		}
+		var pub crypto.PublicKey
+		if key, err := jwt.ParseRSAPublicKeyFromPEM(data); err == nil {
+			pub = key
+		} else if key, err := jwt.ParseEdPublicKeyFromPEM(data); err == nil {
+			pub = key
+		} else {
+			return fmt.Errorf("%s: unsupported public key (expected RSA or Ed25519 PEM)", file)
+		}
+		if _, err := set.addVerifyKey(pub); err != nil {
+			return err
+		}
+	}
+
+	jwtKeysMu.Lock()
+	jwtKeys = set
+	jwtKeysMu.Unlock()
+	return nil
+}
+
+// addVerifyKey mendaftarkan public key dengan kid = JWK thumbprint (RFC 7638),
+// sehingga kid stabil tanpa perlu dikonfigurasi
+func (s *jwtKeySet) addVerifyKey(pub crypto.PublicKey) (string, error) {
+	jwk, err := publicJWK(pub)
+	if err != nil {
+		return "", err
+	}
+	kid := jwk["kid"]
+	if _, exists := s.verify[kid]; !exists {
+		s.kids = append(s.kids, kid)
+	}
+	method := jwt.SigningMethod(jwt.SigningMethodEdDSA)
+	if _, ok := pub.(*rsa.PublicKey); ok {
+		method = jwt.SigningMethodRS256
+	}
+	s.verify[kid] = verifyKey{method: method, key: pub}
+	return kid, nil
+}
+
+// currentJWTKeys memakai kunci yang sudah dimuat; jika belum (mis. di test) fallback ke
+// HS256 dari JWT_SECRET
+func currentJWTKeys() *jwtKeySet {
+	jwtKeysMu.RLock()
+	set := jwtKeys
+	jwtKeysMu.RUnlock()
+	if set != nil {
+		return set
+	}
+	secret := config.GetEnv("JWT_SECRET", defaultJWTSecret)
+	if secret == "" {
+		secret = defaultJWTSecret
+	}
+	return &jwtKeySet{method: jwt.SigningMethodHS256, secret: []byte(secret)}
+}
+
+func signJWT(claims jwt.Claims) (string, error) {
+	set := currentJWTKeys()
+	token := jwt.NewWithClaims(set.method, claims)
+	if set.secret != nil {
+		return token.SignedString(set.secret)
+	}
+	token.Header["kid"] = set.kid
+	return token.SignedString(set.signKey)
+}
+
+// jwtKeyFunc memilih kunci verifikasi berdasarkan kid; algoritma token harus sama dengan
+// jenis kuncinya (mencegah alg confusion)
+func jwtKeyFunc(token *jwt.Token) (interface{}, error) {
+	set := currentJWTKeys()
+	if set.secret != nil {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, errors.New("unexpected signing method")
+		}
+		return set.secret, nil
+	}
+	kid, _ := token.Header["kid"].(string)
+	key, ok := set.verify[kid]
+	if !ok {
+		return nil, errors.New("unknown key id")
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key.key, nil
+}
+
+// JWKS mengembalikan public key yang berlaku (format JSON Web Key Set) agar service lain
+// bisa memvalidasi token tanpa berbagi secret. Kosong pada mode HS256.
+func JWKS() map[string]interface{} {
+	set := currentJWTKeys()
+	keys := make([]map[string]string, 0, len(set.kids))
+	for _, kid := range set.kids {
+		jwk, _ := publicJWK(set.verify[kid].key)
+		keys = append(keys, jwk)
+	}
+	return map[string]interface{}{"keys": keys}
+}
+
+func publicJWK(pub crypto.PublicKey) (map[string]string, error) {
+	b64 := base64.RawURLEncoding.EncodeToString
+	var jwk map[string]string
+	var thumbprint []byte
+	var err error
+	// Thumbprint memakai member wajib dengan urutan leksikografis (RFC 7638)
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		n, e := b64(key.N.Bytes()), b64(big.NewInt(int64(key.E)).Bytes())
+		jwk = map[string]string{"kty": "RSA", "alg": "RS256", "use": "sig", "n": n, "e": e}
+		thumbprint, err = json.Marshal(struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{e, "RSA", n})
+	case ed25519.PublicKey:
+		x := b64(key)
+		jwk = map[string]string{"kty": "OKP", "crv": "Ed25519", "alg": "EdDSA", "use": "sig", "x": x}
+		thumbprint, err = json.Marshal(struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{"Ed25519", "OKP", x})
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(thumbprint)
+	jwk["kid"] = b64(sum[:])
+	return jwk, nil
+}
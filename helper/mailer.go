@@ -0,0 +1,51 @@
+package helper
+
+import (
+	"fmt"
+	"gouas/config"
+	"log"
+	"net/smtp"
+	"strings"
+)
+
+// Mailer mengirim email ke alamat tertentu (mis. link reset password)
+type Mailer interface {
+	Send(to string, subject string, body string) error
+}
+
+// NewMailerFromEnv memakai SMTP jika SMTP_HOST diisi, selain itu hanya mencatat ke log
+func NewMailerFromEnv() Mailer {
+	host := config.GetEnv("SMTP_HOST", "")
+	if host == "" {
+		return &logMailer{}
+	}
+	return &smtpMailer{
+		addr:     host + ":" + config.GetEnv("SMTP_PORT", "587"),
+		host:     host,
+		username: config.GetEnv("SMTP_USERNAME", ""),
+		password: config.GetEnv("SMTP_PASSWORD", ""),
+		from:     config.GetEnv("SMTP_FROM", "no-reply@gouas.local"),
+	}
+}
+
+type smtpMailer struct {
+	addr, host, username, password, from string
+}
+
+func (m *smtpMailer) Send(to string, subject string, body string) error {
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
+		m.from, to, subject, strings.ReplaceAll(body, "\n", "\r\n"))
+	return smtp.SendMail(m.addr, auth, m.from, []string{to}, []byte(msg))
+}
+
+type logMailer struct{}
+
+// logMailer dipakai di development; isi email (termasuk token) ikut tercatat di log
+func (m *logMailer) Send(to string, subject string, body string) error {
+	log.Printf("[MAIL] to=%s subject=%q body=%q", to, subject, body)
+	return nil
+}
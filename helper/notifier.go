@@ -0,0 +1,24 @@
+package helper
+
+import (
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// Notifier mengirim notifikasi ke user (in-app, email, dll)
+type Notifier interface {
+	Notify(userID uuid.UUID, subject string, message string) error
+}
+
+type logNotifier struct{}
+
+// NewLogNotifier hanya mencatat notifikasi ke log, dipakai sampai ada channel pengiriman
+func NewLogNotifier() Notifier {
+	return &logNotifier{}
+}
+
+func (n *logNotifier) Notify(userID uuid.UUID, subject string, message string) error {
+	log.Printf("[NOTIFY] user=%s subject=%q message=%q", userID, subject, message)
+	return nil
+}
@@ -0,0 +1,115 @@
+package helper
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gouas/config"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// PasswordPolicyConfig diatur lewat PASSWORD_MIN_LENGTH & PASSWORD_BREACHED_LIST.
+// File breached list berisi satu password per baris, atau SHA-1 hex (format HIBP "HASH:count").
+type PasswordPolicyConfig struct {
+	MinLength        int
+	BreachedListFile string
+}
+
+type passwordPolicy struct {
+	minLength int
+	breached  map[string]struct{} // SHA-1 hex uppercase
+}
+
+// bcrypt hanya memakai 72 byte pertama, password lebih panjang ditolak
+const maxPasswordBytes = 72
+
+var (
+	passwordPolicyMu      sync.RWMutex
+	currentPasswordPolicy = &passwordPolicy{minLength: 8}
+)
+
+func LoadPasswordPolicyFromEnv() error {
+	minLength, err := strconv.Atoi(config.GetEnv("PASSWORD_MIN_LENGTH", "8"))
+	if err != nil {
+		return fmt.Errorf("invalid PASSWORD_MIN_LENGTH: %w", err)
+	}
+	return LoadPasswordPolicy(PasswordPolicyConfig{
+		MinLength:        minLength,
+		BreachedListFile: config.GetEnv("PASSWORD_BREACHED_LIST", ""),
+	})
+}
+
+func LoadPasswordPolicy(cfg PasswordPolicyConfig) error {
+	if cfg.MinLength < 8 || cfg.MinLength > maxPasswordBytes {
+		return fmt.Errorf("password min length must be between 8 and %d", maxPasswordBytes)
+	}
+	policy := &passwordPolicy{minLength: cfg.MinLength, breached: map[string]struct{}{}}
+
+	if cfg.BreachedListFile != "" {
+		f, err := os.Open(cfg.BreachedListFile)
+		if err != nil {
+			return fmt.Errorf("open breached password list: %w", err)
+		}
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			if hash, _, _ := strings.Cut(line, ":"); isSHA1Hex(hash) {
+				policy.breached[strings.ToUpper(hash)] = struct{}{}
+			} else {
+				policy.breached[sha1Hex(line)] = struct{}{}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("read breached password list: %w", err)
+		}
+	}
+
+	passwordPolicyMu.Lock()
+	currentPasswordPolicy = policy
+	passwordPolicyMu.Unlock()
+	return nil
+}
+
+// ValidatePassword mengecek password baru terhadap policy yang berlaku
+func ValidatePassword(password, username string) error {
+	passwordPolicyMu.RLock()
+	policy := currentPasswordPolicy
+	passwordPolicyMu.RUnlock()
+
+	if utf8.RuneCountInString(password) < policy.minLength {
+		return fmt.Errorf("password must be at least %d characters", policy.minLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("password must be at most %d bytes", maxPasswordBytes)
+	}
+	if username != "" && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		return errors.New("password must not contain the username")
+	}
+	if _, found := policy.breached[sha1Hex(password)]; found {
+		return errors.New("password appears in a list of breached passwords")
+	}
+	return nil
+}
+
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func isSHA1Hex(s string) bool {
+	if len(s) != 40 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -4,6 +4,15 @@ type Response struct {
 	Status  string      `json:"status"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
+	Meta    interface{} `json:"meta,omitempty"`
+}
+
+// PaginationMeta dikirim bersama list yang dipaginasi
+type PaginationMeta struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"totalPages"`
 }
 
 // APIResponse sekarang mengembalikan struct, bukan []byte
@@ -13,4 +22,23 @@ func APIResponse(status string, message string, data interface{}) Response {
 		Message: message,
 		Data:    data,
 	}
-}
\ No newline at end of file
+}
+
+// APIResponseWithMeta sama seperti APIResponse dengan tambahan meta (mis. pagination)
+func APIResponseWithMeta(status string, message string, data interface{}, meta interface{}) Response {
+	return Response{
+		Status:  status,
+		Message: message,
+		Data:    data,
+		Meta:    meta,
+	}
+}
+
+// NewPaginationMeta menghitung jumlah halaman dari total data
+func NewPaginationMeta(page int, limit int, total int64) PaginationMeta {
+	totalPages := 0
+	if limit > 0 {
+		totalPages = int((total + int64(limit) - 1) / int64(limit))
+	}
+	return PaginationMeta{Page: page, Limit: limit, Total: total, TotalPages: totalPages}
+}
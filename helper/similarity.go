@@ -0,0 +1,63 @@
+package helper
+
+import (
+	"strings"
+	"unicode"
+)
+
+// EditDistance menghitung jarak Levenshtein (per rune) antara dua string
+func EditDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// NameKey menyederhanakan nama untuk perbandingan: huruf kecil, hanya huruf & angka
+func NameKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// maxNameDistance: batas salah ketik, mis. "Informatka" vs "Informatika" (1)
+const maxNameDistance = 2
+
+// SimilarNames mengembalikan pasangan indeks nama yang kemungkinan salah ketik satu sama lain.
+// Nama pendek (< 6 huruf, mis. singkatan) hanya dianggap sama jika identik setelah disederhanakan.
+func SimilarNames(names []string) [][2]int {
+	keys := make([]string, len(names))
+	for i, n := range names {
+		keys[i] = NameKey(n)
+	}
+	var pairs [][2]int
+	for i := range names {
+		for j := i + 1; j < len(names); j++ {
+			limit := maxNameDistance
+			if len([]rune(keys[i])) < 6 || len([]rune(keys[j])) < 6 {
+				limit = 0
+			}
+			if EditDistance(keys[i], keys[j]) <= limit {
+				pairs = append(pairs, [2]int{i, j})
+			}
+		}
+	}
+	return pairs
+}
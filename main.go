@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"gouas/app/models"
 	"gouas/app/repository"
@@ -9,9 +10,13 @@ import (
 	"gouas/database"
 	_ "gouas/docs"
 	"gouas/helper"
+	"gouas/middleware"
 	"gouas/route"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -21,36 +26,47 @@ import (
 )
 
 func seedDatabase(db *gorm.DB) {
-	rolePermissions := map[string][]string{
-		"Admin":      {"user:manage"},
-		"Mahasiswa":  {"achievement:create", "achievement:read", "achievement:update", "achievement:delete"},
-		"Dosen Wali": {"achievement:read", "achievement:verify"},
-	}
-
-	for roleName, permNames := range rolePermissions {
-		var permissions []models.Permission
+	// Permission yang belum ada dibuat lalu ditempelkan ke role default-nya. Relasi role yang
+	// sudah ada tidak diubah, agar konfigurasi role lewat API (/roles) tidak tertimpa saat restart.
+	createdPerms := map[string]bool{}
+	for _, permNames := range models.DefaultRolePermissions {
 		for _, permName := range permNames {
 			var perm models.Permission
 			if err := db.Where("name = ?", permName).First(&perm).Error; err != nil {
-				perm = models.Permission{
+				resource, action, _ := strings.Cut(permName, ":")
+				db.Create(&models.Permission{
 					Name:        permName,
-					Resource:    permName,
-					Action:      "access",
+					Resource:    resource,
+					Action:      action,
 					Description: "Auto generated",
-				}
-				db.Create(&perm)
+				})
+				createdPerms[permName] = true
 			}
-			permissions = append(permissions, perm)
 		}
+	}
 
+	for roleName, permNames := range models.DefaultRolePermissions {
 		var role models.Role
-		if err := db.Where("name = ?", roleName).Preload("Permissions").First(&role).Error; err != nil {
+		roleExists := db.Where("name = ?", roleName).First(&role).Error == nil
+
+		var permissions []models.Permission
+		for _, permName := range permNames {
+			if roleExists && !createdPerms[permName] {
+				continue
+			}
+			var perm models.Permission
+			if err := db.Where("name = ?", permName).First(&perm).Error; err == nil {
+				permissions = append(permissions, perm)
+			}
+		}
+
+		if !roleExists {
 			newRole := models.Role{Name: roleName, Permissions: permissions}
 			db.Create(&newRole)
 			fmt.Printf("[SEED] Role created: %s\n", roleName)
-		} else {
-			db.Model(&role).Association("Permissions").Replace(permissions)
-			fmt.Printf("[SEED] Role updated: %s\n", roleName)
+		} else if len(permissions) > 0 {
+			db.Model(&role).Association("Permissions").Append(permissions)
+			fmt.Printf("[SEED] Role updated: %s (+%d permissions)\n", roleName, len(permissions))
 		}
 	}
 
@@ -66,17 +82,46 @@ func seedDatabase(db *gorm.DB) {
 			FullName:     "Super Admin",
 			RoleID:       adminRole.ID,
 			IsActive:     true,
+
+			MustChangePassword: true,
 		}
 		db.Create(&admin)
 		fmt.Println("[SEED] User 'admin' created")
+	} else if userExist.PasswordChangedAt == nil && !userExist.MustChangePassword && helper.CheckPasswordHash("admin123", userExist.PasswordHash) {
+		// Admin lama yang masih memakai password default wajib menggantinya
+		db.Model(&userExist).Update("must_change_password", true)
+		fmt.Println("[SEED] User 'admin' still uses the default password; password change required")
+	}
+
+	// Point rules default (setara nilai lama per CompetitionLevel), hanya jika belum ada rule
+	var ruleCount int64
+	db.Model(&models.PointRule{}).Count(&ruleCount)
+	if ruleCount == 0 {
+		defaultRules := []models.PointRule{
+			{Name: "Kompetisi Internasional", CompetitionLevel: "International", Points: 50, IsActive: true},
+			{Name: "Kompetisi Nasional", CompetitionLevel: "National", Points: 30, IsActive: true},
+			{Name: "Kompetisi Provinsi", CompetitionLevel: "Provincial", Points: 20, IsActive: true},
+			{Name: "Kompetisi Kampus", CompetitionLevel: "Campus", Points: 10, IsActive: true},
+			{Name: "Poin Dasar", Points: 5, IsActive: true},
+		}
+		db.Create(&defaultRules)
+		fmt.Println("[SEED] Default point rules created")
 	}
 }
 
 func main() {
 	config.LoadEnv()
 	config.InitLogger()
+	// Kunci JWT dimuat sebelum koneksi DB agar konfigurasi salah langsung gagal saat boot
+	if err := helper.LoadJWTKeysFromEnv(); err != nil {
+		log.Fatal("Invalid JWT key configuration: ", err)
+	}
+	if err := helper.LoadPasswordPolicyFromEnv(); err != nil {
+		log.Fatal("Invalid password policy: ", err)
+	}
 	database.ConnectPostgres()
 	database.ConnectMongo()
+	database.EnsureMongoIndexes()
 	database.Migrate()
 
 	db := database.DB
@@ -86,23 +131,82 @@ func main() {
 	// 1. Repositories
 	authRepo := repository.NewAuthRepository(db)
 	adminRepo := repository.NewAdminRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	orgRepo := repository.NewOrganizationRepository(db)
+	profileRepo := repository.NewProfileRepository(db)
+	importJobRepo := repository.NewImportJobRepository(db)
+	advisorRepo := repository.NewAdvisorRepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
 	achievementRepo := repository.NewAchievementRepository(db, mongoDB)
 	studentRepo := repository.NewStudentRepository(db)
 	lecturerRepo := repository.NewLecturerRepository(db)
 	reportRepo := repository.NewReportRepository(db, mongoDB)
+	pointRepo := repository.NewPointRepository(db)
+	pointRuleRepo := repository.NewPointRuleRepository(db)
+	achievementTypeRepo := repository.NewAchievementTypeRepository(db)
+	reconcileRepo := repository.NewReconcileRepository(db, mongoDB)
+	outboxRepo := repository.NewOutboxRepository(db)
+	uow := repository.NewUnitOfWork(db, mongoDB)
 
 	// 2. Services
-	authSvc := service.NewAuthService(authRepo)
-	adminSvc := service.NewAdminService(adminRepo)
+	loginPolicy := service.DefaultLoginPolicy()
+	lockThreshold, err := strconv.Atoi(config.GetEnv("LOGIN_LOCK_THRESHOLD", strconv.Itoa(loginPolicy.LockThreshold)))
+	if err != nil || lockThreshold <= 0 {
+		log.Fatal("Invalid LOGIN_LOCK_THRESHOLD")
+	}
+	lockDuration, err := time.ParseDuration(config.GetEnv("LOGIN_LOCK_DURATION", loginPolicy.LockDuration.String()))
+	if err != nil || lockDuration <= 0 {
+		log.Fatal("Invalid LOGIN_LOCK_DURATION")
+	}
+	loginPolicy.LockThreshold, loginPolicy.LockDuration = lockThreshold, lockDuration
+	authSvc := service.NewAuthService(authRepo, loginAttemptRepo, loginPolicy)
+	resetTTL, err := time.ParseDuration(config.GetEnv("PASSWORD_RESET_TTL", "30m"))
+	if err != nil || resetTTL <= 0 {
+		log.Fatal("Invalid PASSWORD_RESET_TTL")
+	}
+	passwordSvc := service.NewPasswordService(authRepo, loginAttemptRepo, loginPolicy, helper.NewMailerFromEnv(), resetTTL, config.GetEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password?token="))
+	adminSvc := service.NewAdminService(adminRepo, orgRepo, uow)
+	roleSvc := service.NewRoleService(roleRepo, uow)
+	orgSvc := service.NewOrganizationService(orgRepo)
+	profileSvc := service.NewProfileService(profileRepo, studentRepo, lecturerRepo, adminRepo, orgRepo, loginAttemptRepo, loginPolicy)
+	importSvc := service.NewUserImportService(adminRepo, profileRepo, lecturerRepo, orgRepo, importJobRepo, uow)
+	advisorSvc := service.NewAdvisorService(advisorRepo, studentRepo, lecturerRepo)
 
 	// [FIXED] Menambahkan lecturerRepo sebagai argumen ke-3
-	achievementSvc := service.NewAchievementService(achievementRepo, studentRepo, lecturerRepo)
+	achievementSvc := service.NewAchievementService(achievementRepo, studentRepo, lecturerRepo, pointRuleRepo, achievementTypeRepo)
 
 	// [FIXED] Menambahkan lecturerRepo sebagai argumen ke-3
-	studentSvc := service.NewStudentService(studentRepo, achievementRepo, lecturerRepo)
+	studentSvc := service.NewStudentService(studentRepo, achievementRepo, lecturerRepo, pointRepo)
 
 	lecturerSvc := service.NewLecturerService(lecturerRepo)
-	reportSvc := service.NewReportService(reportRepo, achievementRepo)
+	reportSvc := service.NewReportService(reportRepo, achievementRepo, studentRepo, lecturerRepo)
+	pointRuleSvc := service.NewPointRuleService(pointRuleRepo, achievementRepo)
+	achievementTypeSvc := service.NewAchievementTypeService(achievementTypeRepo)
+	gracePeriod, _ := time.ParseDuration(config.GetEnv("RECONCILE_GRACE_PERIOD", "15m"))
+	reconcileSvc := service.NewReconcileService(reconcileRepo, gracePeriod)
+
+	// Subcommand CLI: `go run . reconcile [--repair]`
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		runReconcileCommand(reconcileSvc, os.Args[2:])
+		return
+	}
+
+	// Mode server: job import yang terputus karena restart tidak dilanjutkan, tandai failed.
+	// Tidak dijalankan untuk subcommand CLI agar tidak menggagalkan job milik server yang sedang jalan.
+	if n, err := importJobRepo.FailInterrupted(context.Background()); err != nil {
+		log.Println("[IMPORT] failed to mark interrupted jobs:", err)
+	} else if n > 0 {
+		log.Printf("[IMPORT] %d interrupted import jobs marked as failed", n)
+	}
+
+	startReconcileScheduler(reconcileSvc)
+
+	// Dispatcher outbox: poin, notifikasi & sinkronisasi Mongo setelah perubahan status
+	outboxInterval, err := time.ParseDuration(config.GetEnv("OUTBOX_INTERVAL", "5s"))
+	if err != nil || outboxInterval <= 0 {
+		log.Fatal("Invalid OUTBOX_INTERVAL")
+	}
+	service.NewOutboxDispatcher(outboxRepo, pointRepo, achievementRepo, helper.NewLogNotifier()).Start(outboxInterval)
 
 	// 3. Fiber App
 	app := fiber.New(fiber.Config{
@@ -111,13 +215,20 @@ func main() {
 	app.Use(logger.New())
 	app.Use(cors.New())
 
+	// Deadline per request, diteruskan ke repository lewat context
+	requestTimeout, err := time.ParseDuration(config.GetEnv("REQUEST_TIMEOUT", "30s"))
+	if err != nil || requestTimeout <= 0 {
+		log.Fatal("Invalid REQUEST_TIMEOUT")
+	}
+	app.Use(middleware.RequestTimeout(requestTimeout))
+
 	if _, err := os.Stat("./uploads"); os.IsNotExist(err) {
 		os.Mkdir("./uploads", 0755)
 	}
 	app.Static("/uploads", "./uploads")
 	app.Get("/swagger/*", swagger.HandlerDefault)
 
-	route.InitRoutes(app, authSvc, adminSvc, achievementSvc, studentSvc, lecturerSvc, reportSvc)
+	route.InitRoutes(app, authSvc, adminSvc, roleSvc, achievementSvc, studentSvc, lecturerSvc, reportSvc, pointRuleSvc, achievementTypeSvc, orgSvc, profileSvc, importSvc, advisorSvc, passwordSvc, service.NewOutboxService(outboxRepo))
 
 	port := config.GetEnv("APP_PORT", "3000")
 	log.Fatal(app.Listen(":" + port))
@@ -6,12 +6,21 @@ import (
 	"gouas/app/models"
 	"gouas/helper"
 	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
 )
 
+const authLocalsKey = "auth"
+
 type AuthResult struct {
 	UserID      string
+	SessionID   string
 	Role        string
 	Permissions []string
+	Scopes      []models.UserScope // unit organisasi yang boleh diakses; kosong = global
+
+	MustChangePassword bool
 }
 
 // CheckAuth memvalidasi token dan mengecek status Whitelist di DB
@@ -26,32 +35,121 @@ func CheckAuth(authHeader string) (*AuthResult, error) {
 	}
 
 	// 1. Validasi Signature (Stateless)
-	claims, err := helper.ValidateJWT(parts[1])
+	claims, err := helper.ValidateJWT(parts[1], helper.TokenAccess)
 	if err != nil {
 		return nil, err
 	}
 
 	// 2. Validasi ke Database (Stateful)
-	// Cek apakah TokenID di JWT == CurrentAccessTokenID di DB
+	// Token hanya valid jika session-nya aktif dan jti == access token terakhir session tsb
+	var session models.UserSession
+	result := database.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?",
+		claims.SessionID, claims.UserID, time.Now()).First(&session)
+	if result.Error != nil || session.AccessTokenID != claims.TokenID {
+		return nil, errors.New("token has been revoked (logged out or refreshed)")
+	}
+	// last_seen_at cukup diperbarui per menit, tidak setiap request
+	if time.Since(session.LastSeenAt) > time.Minute {
+		database.DB.Model(&session).UpdateColumn("last_seen_at", time.Now())
+	}
+
 	var user models.User
-	result := database.DB.Select("current_access_token_id").First(&user, "id = ?", claims.UserID)
-	
+	result = database.DB.Select("id", "role_id", "must_change_password").
+		Preload("Role.Permissions").Preload("Scopes").First(&user, "id = ?", claims.UserID)
 	if result.Error != nil {
 		return nil, errors.New("user not found")
 	}
 
-	// Jika ID di DB null atau beda dengan ID di token -> TOLAK
-	if user.CurrentAccessTokenID == nil || *user.CurrentAccessTokenID != claims.TokenID {
-		return nil, errors.New("token has been revoked (logged out or refreshed)")
+	// Role & permission dibaca dari DB agar perubahan role langsung berlaku
+	permissions := make([]string, 0, len(user.Role.Permissions))
+	for _, p := range user.Role.Permissions {
+		permissions = append(permissions, p.Name)
 	}
 
 	return &AuthResult{
 		UserID:      claims.UserID.String(),
-		Role:        claims.Role,
-		Permissions: claims.Permissions,
+		SessionID:   session.ID.String(),
+		Role:        user.Role.Name,
+		Permissions: permissions,
+		Scopes:      user.Scopes,
+
+		MustChangePassword: user.MustChangePassword,
 	}, nil
 }
 
+// Can mengecek apakah user yang login punya permission tertentu
+func (a *AuthResult) Can(permission string) bool {
+	return HasPermission(a.Permissions, permission)
+}
+
+// Authenticate memvalidasi token sekali per request dan menyimpan AuthResult di c.Locals.
+// User yang wajib ganti password ditolak sampai password diganti.
+func Authenticate() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		authData := resolveAuth(c)
+		if authData == nil {
+			return c.Status(401).JSON(helper.APIResponse("error", "Unauthorized", nil))
+		}
+		if authData.MustChangePassword {
+			return passwordChangeRequired(c)
+		}
+		return c.Next()
+	}
+}
+
+// AuthenticateAllowingPasswordChange sama dengan Authenticate tapi tetap mengizinkan user yang
+// wajib ganti password; hanya untuk route ganti password, profile & logout
+func AuthenticateAllowingPasswordChange() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if resolveAuth(c) == nil {
+			return c.Status(401).JSON(helper.APIResponse("error", "Unauthorized", nil))
+		}
+		return c.Next()
+	}
+}
+
+// RequirePermission mengizinkan request jika user punya salah satu permission yang diminta
+func RequirePermission(permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		authData := resolveAuth(c)
+		if authData == nil {
+			return c.Status(401).JSON(helper.APIResponse("error", "Unauthorized", nil))
+		}
+		if authData.MustChangePassword {
+			return passwordChangeRequired(c)
+		}
+		for _, p := range permissions {
+			if authData.Can(p) {
+				return c.Next()
+			}
+		}
+		return c.Status(403).JSON(helper.APIResponse("error", "Forbidden", nil))
+	}
+}
+
+func passwordChangeRequired(c *fiber.Ctx) error {
+	return c.Status(403).JSON(helper.APIResponse("error", "Password change required; use POST /api/v1/auth/change-password", nil))
+}
+
+// resolveAuth memakai hasil yang sudah ada di c.Locals, atau memvalidasi token jika belum
+func resolveAuth(c *fiber.Ctx) *AuthResult {
+	if authData := CurrentAuth(c); authData != nil {
+		return authData
+	}
+	authData, err := CheckAuth(c.Get("Authorization"))
+	if err != nil {
+		return nil
+	}
+	c.Locals(authLocalsKey, authData)
+	return authData
+}
+
+// CurrentAuth mengambil AuthResult yang disimpan Authenticate; nil jika belum login
+func CurrentAuth(c *fiber.Ctx) *AuthResult {
+	authData, _ := c.Locals(authLocalsKey).(*AuthResult)
+	return authData
+}
+
 func HasPermission(userPerms []string, requiredPerm string) bool {
 	for _, p := range userPerms {
 		if p == requiredPerm {
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"gouas/helper"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestTimeout memberi deadline pada context request. Handler meneruskan
+// c.UserContext() ke repository sehingga query Postgres/Mongo ikut dibatalkan.
+//
+// Context juga dibatalkan saat server shutdown (Done() milik fasthttp.RequestCtx).
+// Client yang memutus koneksi TIDAK membatalkan context: fasthttp baru tahu koneksi
+// putus ketika menulis respons, jadi query tetap jalan sampai selesai atau deadline.
+func RequestTimeout(timeout time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+		defer cancel()
+		stop := context.AfterFunc(c.Context(), cancel)
+		defer stop()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return c.Status(fiber.StatusGatewayTimeout).JSON(helper.APIResponse("error", "Request timed out", nil))
+		}
+		return err
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"gouas/app/service"
+	"gouas/config"
+	"log"
+	"strconv"
+	"time"
+)
+
+const reconcileTimeout = 10 * time.Minute
+
+// runReconcileCommand menjalankan `reconcile [--repair]` sekali lalu keluar
+func runReconcileCommand(svc service.ReconcileService, args []string) {
+	fs := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	repair := fs.Bool("repair", false, "perbaiki inkonsistensi yang ditemukan")
+	fs.Parse(args)
+
+	if err := reconcileOnce(svc, *repair); err != nil {
+		log.Fatal("Reconciliation failed: ", err)
+	}
+}
+
+// startReconcileScheduler menjalankan rekonsiliasi berkala jika RECONCILE_INTERVAL diisi (mis. "1h")
+func startReconcileScheduler(svc service.ReconcileService) {
+	raw := config.GetEnv("RECONCILE_INTERVAL", "")
+	if raw == "" {
+		return
+	}
+	interval, err := time.ParseDuration(raw)
+	if err != nil || interval <= 0 {
+		log.Fatal("Invalid RECONCILE_INTERVAL: ", raw)
+	}
+	repair, _ := strconv.ParseBool(config.GetEnv("RECONCILE_REPAIR", "false"))
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := reconcileOnce(svc, repair); err != nil {
+				log.Println("[RECONCILE] failed:", err)
+			}
+		}
+	}()
+	log.Printf("[RECONCILE] scheduled every %s (repair=%t)", interval, repair)
+}
+
+func reconcileOnce(svc service.ReconcileService, repair bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileTimeout)
+	defer cancel()
+
+	report, err := svc.Run(ctx, repair)
+	if err != nil {
+		return err
+	}
+	log.Printf("[RECONCILE] checked %d references and %d mongo documents in %s, issues: %v",
+		report.References, report.MongoDocuments, report.FinishedAt.Sub(report.StartedAt), report.Summary())
+	for _, issue := range report.Issues {
+		status := "reported"
+		if issue.Repaired {
+			status = "repaired"
+		} else if issue.RepairError != "" {
+			status = "repair failed: " + issue.RepairError
+		}
+		log.Printf("[RECONCILE] %s mongo=%s: %s (%s)", issue.Kind, issue.MongoID, issue.Detail, status)
+	}
+	return nil
+}
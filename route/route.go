@@ -1,6 +1,7 @@
 package route
 
 import (
+	"gouas/app/models"
 	"gouas/app/service"
 	"gouas/middleware"
 
@@ -11,11 +12,23 @@ func InitRoutes(
 	app *fiber.App,
 	authSvc service.AuthService,
 	adminSvc service.AdminService,
+	roleSvc service.RoleService,
 	achSvc service.AchievementService,
 	studentSvc service.StudentService,
 	lecturerSvc service.LecturerService,
 	reportSvc service.ReportService,
+	pointRuleSvc service.PointRuleService,
+	achTypeSvc service.AchievementTypeService,
+	orgSvc service.OrganizationService,
+	profileSvc service.ProfileService,
+	importSvc service.UserImportService,
+	advisorSvc service.AdvisorService,
+	passwordSvc service.PasswordService,
+	outboxSvc service.OutboxService,
 ) {
+	// Public key untuk validasi token oleh service kampus lain
+	app.Get("/.well-known/jwks.json", authSvc.JWKS)
+
 	api := app.Group("/api/v1")
 
 	// =========================================================================
@@ -24,68 +37,166 @@ func InitRoutes(
 	auth := api.Group("/auth")
 	auth.Post("/login", authSvc.Login)
 	auth.Post("/refresh", authSvc.Refresh)
-	auth.Post("/logout", authSvc.Logout)
-	auth.Get("/profile", authSvc.GetProfile)
+	auth.Post("/forgot-password", passwordSvc.ForgotPassword)
+	auth.Post("/reset-password", passwordSvc.ResetPassword)
+
+	// Route berikut tetap bisa dipakai user yang wajib ganti password
+	pending := middleware.AuthenticateAllowingPasswordChange()
+	auth.Post("/change-password", pending, passwordSvc.ChangePassword)
+	auth.Post("/logout", pending, authSvc.Logout)
+	auth.Post("/logout-all", pending, authSvc.LogoutAll)
+	auth.Get("/sessions", pending, authSvc.ListSessions)
+	auth.Delete("/sessions/:id", pending, authSvc.RevokeSession)
+	auth.Get("/profile", pending, authSvc.GetProfile)
+	auth.Put("/profile", middleware.Authenticate(), profileSvc.UpdateContact)
+
+	// Setiap route di bawah ini dicek berdasarkan permission (bukan nama role),
+	// sehingga hak akses role bisa diatur ulang lewat data role_permissions.
+	can := middleware.RequirePermission
 
 	// =========================================================================
 	// 5.2 USERS (ADMIN)
 	// =========================================================================
-	users := api.Group("/users")
-	// Middleware Check: Hanya Admin
-	users.Use(func(c *fiber.Ctx) error {
-		authData, err := middleware.CheckAuth(c.Get("Authorization"))
-		if err != nil || authData.Role != "Admin" {
-			return c.Status(403).JSON(fiber.Map{"status": "error", "message": "Forbidden"})
-		}
-		return c.Next()
-	})
+	users := api.Group("/users", can(models.PermUserManage))
 
 	users.Get("/", adminSvc.GetAllUsers)
+	// Import massal (CSV/XLSX) didaftarkan sebelum /:id
+	users.Post("/import", importSvc.Import)
+	users.Get("/import/:id", importSvc.GetJob)
 	users.Get("/:id", adminSvc.GetUserDetail)
 	users.Post("/", adminSvc.CreateUser)
 	users.Put("/:id", adminSvc.UpdateUser)
 	users.Delete("/:id", adminSvc.DeleteUser)
 	users.Put("/:id/role", adminSvc.AssignRole)
+	users.Get("/:id/scopes", adminSvc.GetUserScopes)
+	users.Put("/:id/scopes", adminSvc.SetUserScopes)
+	users.Post("/:id/unlock", authSvc.UnlockUser)
+	users.Get("/:id/login-attempts", authSvc.GetLoginAttempts)
+
+	// =========================================================================
+	// ROLES & PERMISSIONS (ADMIN)
+	// =========================================================================
+	roles := api.Group("/roles", can(models.PermRoleManage))
+
+	roles.Get("/", roleSvc.GetAllRoles)
+	roles.Get("/:id", roleSvc.GetRole)
+	roles.Post("/", roleSvc.CreateRole)
+	roles.Put("/:id", roleSvc.UpdateRole)
+	roles.Delete("/:id", roleSvc.DeleteRole)
+	roles.Post("/:id/permissions", roleSvc.AttachPermission)
+	roles.Delete("/:id/permissions/:permissionId", roleSvc.DetachPermission)
+
+	permissions := api.Group("/permissions", can(models.PermRoleManage))
+
+	permissions.Get("/", roleSvc.GetAllPermissions)
+	permissions.Post("/", roleSvc.CreatePermission)
+	permissions.Put("/:id", roleSvc.UpdatePermission)
+	permissions.Delete("/:id", roleSvc.DeletePermission)
 
 	// =========================================================================
 	// 5.4 ACHIEVEMENTS
 	// =========================================================================
-	ach := api.Group("/achievements")
-	
-	// Middleware Auth (Basic Check)
-	ach.Use(func(c *fiber.Ctx) error {
-		_, err := middleware.CheckAuth(c.Get("Authorization"))
-		if err != nil {
-			return c.Status(401).JSON(fiber.Map{"status": "error", "message": "Unauthorized"})
-		}
-		return c.Next()
-	})
-
-	ach.Get("/", achSvc.GetAll)
-	ach.Get("/:id", achSvc.GetDetail)
-	ach.Post("/", achSvc.Create)
-	ach.Put("/:id", achSvc.Update)
-	ach.Delete("/:id", achSvc.Delete)
-	ach.Post("/:id/submit", achSvc.Submit)
-	ach.Post("/:id/verify", achSvc.Verify)
-	ach.Post("/:id/reject", achSvc.Reject)
-	ach.Get("/:id/history", achSvc.GetHistory)
-	ach.Post("/:id/attachments", achSvc.AddAttachment)
+	ach := api.Group("/achievements", middleware.Authenticate())
+
+	ach.Get("/", can(models.PermAchievementRead), achSvc.GetAll)
+	ach.Get("/search", can(models.PermAchievementRead), achSvc.Search)
+	ach.Get("/:id", can(models.PermAchievementRead), achSvc.GetDetail)
+	ach.Post("/", can(models.PermAchievementCreate), achSvc.Create)
+	ach.Put("/:id", can(models.PermAchievementUpdate), achSvc.Update)
+	ach.Delete("/:id", can(models.PermAchievementDelete, models.PermAchievementManage), achSvc.Delete)
+	ach.Post("/:id/submit", can(models.PermAchievementSubmit), achSvc.Submit)
+	ach.Post("/:id/verify", can(models.PermAchievementVerify), achSvc.Verify)
+	ach.Post("/:id/reject", can(models.PermAchievementVerify), achSvc.Reject)
+	ach.Post("/:id/revoke", can(models.PermAchievementRevoke), achSvc.Revoke)
+	ach.Get("/:id/history", can(models.PermAchievementRead), achSvc.GetHistory)
+	ach.Post("/:id/attachments", can(models.PermAchievementUpdate), achSvc.AddAttachment)
+
+	// =========================================================================
+	// ACHIEVEMENT TYPES (Custom type + JSON Schema)
+	// =========================================================================
+	achTypes := api.Group("/achievement-types", middleware.Authenticate())
+
+	achTypes.Get("/", achTypeSvc.GetAll)
+	achTypes.Get("/:name", achTypeSvc.GetDetail)
+	achTypes.Post("/", can(models.PermAchievementTypeManage), achTypeSvc.Create)
+	achTypes.Put("/:name", can(models.PermAchievementTypeManage), achTypeSvc.Update)
+	achTypes.Delete("/:name", can(models.PermAchievementTypeManage), achTypeSvc.Delete)
 
 	// =========================================================================
 	// 5.5 STUDENTS & LECTURERS
 	// =========================================================================
-	api.Get("/students", studentSvc.GetAll)
-	api.Get("/students/:id", studentSvc.GetDetail)
-	api.Get("/students/:id/achievements", studentSvc.GetStudentAchievements)
-	api.Put("/students/:id/advisor", studentSvc.AssignAdvisor)
+	// Scope data (sendiri / bimbingan / semua) dicek di service
+	students := api.Group("/students", middleware.Authenticate())
+	students.Get("/", studentSvc.GetAll)
+	students.Get("/:id", studentSvc.GetDetail)
+	students.Post("/", can(models.PermProfileManage), profileSvc.CreateStudent)
+	students.Put("/:id", can(models.PermProfileManage), profileSvc.UpdateStudent)
+	students.Delete("/:id", can(models.PermProfileManage), profileSvc.DeleteStudent)
+	students.Get("/:id/achievements", studentSvc.GetStudentAchievements)
+	students.Put("/:id/advisor", can(models.PermAdvisorAssign), advisorSvc.Assign)
+	students.Get("/:id/advisor-history", advisorSvc.GetHistory)
+	students.Get("/:id/points", studentSvc.GetPointLedger)
+	students.Post("/:id/points/reconcile", can(models.PermPointReconcile), studentSvc.ReconcilePoints)
+
+	lecturers := api.Group("/lecturers", middleware.Authenticate())
+	lecturers.Get("/", lecturerSvc.GetAll)
+	lecturers.Post("/", can(models.PermProfileManage), profileSvc.CreateLecturer)
+	lecturers.Put("/:id", can(models.PermProfileManage), profileSvc.UpdateLecturer)
+	lecturers.Delete("/:id", can(models.PermProfileManage), profileSvc.DeleteLecturer)
+	lecturers.Get("/:id/advisees", lecturerSvc.GetAdvisees)
+	lecturers.Post("/:id/reassign-advisees", can(models.PermAdvisorAssign), advisorSvc.ReassignAdvisees)
 
-	api.Get("/lecturers", lecturerSvc.GetAll)
-	api.Get("/lecturers/:id/advisees", lecturerSvc.GetAdvisees)
+	advisors := api.Group("/advisors", middleware.Authenticate())
+	advisors.Post("/balance", can(models.PermAdvisorAssign), advisorSvc.Balance)
+
+	// =========================================================================
+	// ORGANIZATION MASTER DATA (Fakultas, Jurusan, Program Studi)
+	// =========================================================================
+	faculties := api.Group("/faculties", middleware.Authenticate())
+	faculties.Get("/", orgSvc.GetAllFaculties)
+	faculties.Post("/", can(models.PermOrganizationManage), orgSvc.CreateFaculty)
+	faculties.Put("/:id", can(models.PermOrganizationManage), orgSvc.UpdateFaculty)
+	faculties.Delete("/:id", can(models.PermOrganizationManage), orgSvc.DeleteFaculty)
+
+	departments := api.Group("/departments", middleware.Authenticate())
+	departments.Get("/", orgSvc.GetAllDepartments)
+	departments.Post("/", can(models.PermOrganizationManage), orgSvc.CreateDepartment)
+	departments.Put("/:id", can(models.PermOrganizationManage), orgSvc.UpdateDepartment)
+	departments.Delete("/:id", can(models.PermOrganizationManage), orgSvc.DeleteDepartment)
+
+	programs := api.Group("/study-programs", middleware.Authenticate())
+	programs.Get("/", orgSvc.GetAllStudyPrograms)
+	programs.Post("/", can(models.PermOrganizationManage), orgSvc.CreateStudyProgram)
+	programs.Put("/:id", can(models.PermOrganizationManage), orgSvc.UpdateStudyProgram)
+	programs.Delete("/:id", can(models.PermOrganizationManage), orgSvc.DeleteStudyProgram)
+
+	// Laporan nama mirip (salah ketik data lama) lalu merge ke unit yang benar
+	organization := api.Group("/organization", can(models.PermOrganizationManage))
+	organization.Get("/duplicates", orgSvc.GetDuplicates)
+	organization.Post("/merge", orgSvc.MergeUnits)
 
 	// =========================================================================
 	// 5.8 REPORTS
 	// =========================================================================
-	api.Get("/reports/statistics", reportSvc.GetStatistics)
-	api.Get("/reports/student/:id", reportSvc.GetStudentReport)
-}
\ No newline at end of file
+	reports := api.Group("/reports", middleware.Authenticate())
+	reports.Get("/statistics", can(models.PermReportRead), reportSvc.GetStatistics)
+	reports.Get("/student/:id", reportSvc.GetStudentReport)
+
+	// =========================================================================
+	// ADMIN: POINT RULES
+	// =========================================================================
+	admin := api.Group("/admin", can(models.PermPointRuleManage))
+
+	admin.Get("/point-rules", pointRuleSvc.GetAll)
+	admin.Post("/point-rules", pointRuleSvc.Create)
+	admin.Post("/point-rules/dry-run", pointRuleSvc.DryRun)
+	admin.Put("/point-rules/:id", pointRuleSvc.Update)
+
+	// =========================================================================
+	// ADMIN: OUTBOX (event yang gagal setelah batas retry)
+	// =========================================================================
+	outbox := api.Group("/outbox", can(models.PermOutboxManage))
+
+	outbox.Get("/failed", outboxSvc.GetFailed)
+	outbox.Post("/:id/retry", outboxSvc.Retry)
+}